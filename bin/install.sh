@@ -85,14 +85,11 @@ detect_platform() {
     # Construct binary names
     if [ "$PLATFORM" = "windows" ]; then
         BINARY_NAME="claude-notifications-${PLATFORM}-${ARCH}.exe"
-        LIST_DEVICES_NAME="list-devices-${PLATFORM}-${ARCH}.exe"
     else
         BINARY_NAME="claude-notifications-${PLATFORM}-${ARCH}"
-        LIST_DEVICES_NAME="list-devices-${PLATFORM}-${ARCH}"
     fi
 
     BINARY_PATH="${SCRIPT_DIR}/${BINARY_NAME}"
-    LIST_DEVICES_PATH="${SCRIPT_DIR}/${LIST_DEVICES_NAME}"
     CHECKSUMS_PATH="${SCRIPT_DIR}/.checksums.txt"
 }
 
@@ -272,10 +269,10 @@ check_github_availability() {
 check_existing() {
     if [ "$FORCE_UPDATE" = true ]; then
         echo -e "${BLUE}🔄 Force update requested, removing old files...${NC}"
-        rm -f "$BINARY_PATH" "$LIST_DEVICES_PATH" 2>/dev/null
+        rm -f "$BINARY_PATH" 2>/dev/null
         # Remove symlinks (Unix) and .bat wrappers (Windows)
-        rm -f "${SCRIPT_DIR}/claude-notifications" "${SCRIPT_DIR}/list-devices" 2>/dev/null
-        rm -f "${SCRIPT_DIR}/claude-notifications.bat" "${SCRIPT_DIR}/list-devices.bat" 2>/dev/null
+        rm -f "${SCRIPT_DIR}/claude-notifications" "${SCRIPT_DIR}/sound-preview" "${SCRIPT_DIR}/list-devices" 2>/dev/null
+        rm -f "${SCRIPT_DIR}/claude-notifications.bat" "${SCRIPT_DIR}/sound-preview.bat" "${SCRIPT_DIR}/list-devices.bat" 2>/dev/null
         # Remove macOS apps for clean reinstall
         rm -rf "${SCRIPT_DIR}/terminal-notifier.app" "${SCRIPT_DIR}/ClaudeNotifications.app" 2>/dev/null
         rm -f "${SCRIPT_DIR}/README.markdown" 2>/dev/null
@@ -289,62 +286,18 @@ check_existing() {
     return 1
 }
 
-# Download a utility binary (sound-preview, list-devices)
-download_utility() {
-    local util_name="$1"
-    local util_path="$2"
-    local url="${RELEASE_URL}/${util_name}"
-
-    # Skip if already exists
-    if [ -f "$util_path" ]; then
-        echo -e "${GREEN}✓${NC} ${util_name} already installed"
-        return 0
-    fi
-
-    echo -e "${BLUE}📦 Downloading ${util_name}...${NC}"
-
-    if command -v curl &> /dev/null; then
-        if curl -fsSL "$url" -o "$util_path" 2>/dev/null; then
-            if [ -f "$util_path" ] && [ "$(get_file_size "$util_path")" -gt 100000 ]; then
-                chmod +x "$util_path" 2>/dev/null || true
-                echo -e "${GREEN}✓${NC} ${util_name} downloaded"
-                return 0
-            fi
-        fi
-    elif command -v wget &> /dev/null; then
-        if wget -q "$url" -O "$util_path" 2>/dev/null; then
-            if [ -f "$util_path" ] && [ "$(get_file_size "$util_path")" -gt 100000 ]; then
-                chmod +x "$util_path" 2>/dev/null || true
-                echo -e "${GREEN}✓${NC} ${util_name} downloaded"
-                return 0
-            fi
-        fi
-    fi
-
-    # Not critical - just warn
-    rm -f "$util_path" 2>/dev/null
-    echo -e "${YELLOW}⚠${NC} Could not download ${util_name} (optional utility)"
-    return 1
+# Create utility wrappers (sound-preview, list-devices)
+# Utilities are subcommands of the main binary, which dispatches on the name it is invoked as
+create_utilities() {
+    create_utility_symlink "sound-preview" || true
+    create_utility_symlink "list-devices" || true
 }
 
-# Download utility binaries (list-devices)
-download_utilities() {
-    echo ""
-    echo -e "${BLUE}📦 Downloading utility binaries...${NC}"
-
-    download_utility "$LIST_DEVICES_NAME" "$LIST_DEVICES_PATH" || true
-
-    # Create symlinks for utilities (may fail if downloads failed - that's OK)
-    create_utility_symlink "list-devices" "$LIST_DEVICES_NAME" "$LIST_DEVICES_PATH" || true
-}
-
-# Create symlink for a utility binary
+# Create symlink for a utility subcommand
 create_utility_symlink() {
     local util_base="$1"
-    local util_name="$2"
-    local util_path="$3"
 
-    if [ ! -f "$util_path" ]; then
+    if [ ! -f "$BINARY_PATH" ]; then
         return 1
     fi
 
@@ -360,18 +313,18 @@ create_utility_symlink() {
 @echo off
 setlocal
 set SCRIPT_DIR=%~dp0
-"%SCRIPT_DIR%${util_name}" %*
+"%SCRIPT_DIR%${BINARY_NAME}" ${util_base} %*
 EOF
         return 0
     fi
 
     # Unix: create symlink
-    if ln -s "$util_name" "$symlink_path" 2>/dev/null; then
+    if ln -s "$BINARY_NAME" "$symlink_path" 2>/dev/null; then
         return 0
     fi
 
-    # Fallback: copy
-    cp "$util_path" "$symlink_path" 2>/dev/null || true
+    # Fallback: copy (the binary still dispatches on its file name)
+    cp "$BINARY_PATH" "$symlink_path" 2>/dev/null || true
     chmod +x "$symlink_path" 2>/dev/null || true
 }
 
@@ -837,8 +790,8 @@ main() {
         # Even if binary exists, ensure symlink is created
         create_symlink
 
-        # Download utility binaries (sound-preview, list-devices)
-        download_utilities
+        # Create utility wrappers (sound-preview, list-devices)
+        create_utilities
 
         # On macOS, also check terminal-notifier and create notification app
         if [ "$PLATFORM" = "darwin" ]; then
@@ -936,8 +889,8 @@ main() {
     # Create symlink for hooks to use
     create_symlink
 
-    # Download utility binaries (sound-preview, list-devices)
-    download_utilities
+    # Create utility wrappers (sound-preview, list-devices)
+    create_utilities
 
     # On macOS, download terminal-notifier and create notification app
     if [ "$PLATFORM" = "darwin" ]; then
@@ -956,7 +909,7 @@ main() {
     echo -e "${GREEN}========================================${NC}"
     echo ""
     echo -e "${GREEN}✓${NC} Binary downloaded: ${BOLD}${BINARY_NAME}${NC}"
-    echo -e "${GREEN}✓${NC} Utilities: list-devices"
+    echo -e "${GREEN}✓${NC} Utilities: sound-preview, list-devices"
     echo -e "${GREEN}✓${NC} Location: ${SCRIPT_DIR}/"
     echo -e "${GREEN}✓${NC} Checksum verified"
     echo -e "${GREEN}✓${NC} Symlinks created"
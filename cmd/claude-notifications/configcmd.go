@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+// runConfig handles the config command and returns the process exit code
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "show" {
+		fmt.Fprintln(os.Stderr, "Usage: claude-notifications config show [--origin] [--cwd dir]")
+		return 1
+	}
+
+	fs := flag.NewFlagSet("config show", flag.ExitOnError)
+	origin := fs.Bool("origin", false, "Show which layer supplied each value")
+	cwd := fs.String("cwd", "", "Project directory used to find .claude/notifications.json (default: current directory)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: claude-notifications config show [--origin] [--cwd dir]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Prints the effective configuration after merging all layers:")
+		fmt.Fprintln(os.Stderr, "defaults, global (plugin), user, project and environment.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args[1:]); err != nil || fs.NArg() > 0 {
+		fs.Usage()
+		return 1
+	}
+
+	dir := *cwd
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+
+	cfg, err := config.LoadLayered(config.LoadOptions{PluginRoot: getPluginRoot(), CWD: dir})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// The output ends up in bug reports; never print expanded credentials
+	cfg = cfg.Redacted()
+
+	if !*origin {
+		data, err := json.MarshalIndent(cfg, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(data))
+		return 0
+	}
+
+	printConfigOrigins(cfg)
+	return 0
+}
+
+// printConfigOrigins prints the layers and every value with the layer that supplied it
+func printConfigOrigins(cfg *config.Config) {
+	fmt.Println("Layers (lowest to highest precedence):")
+	for _, layer := range cfg.Layers() {
+		switch {
+		case layer.Name == config.LayerDefault:
+			fmt.Printf("  %-8s built-in\n", layer.Name)
+		case layer.Loaded:
+			fmt.Printf("  %-8s %s\n", layer.Name, layer.Source)
+		case layer.Source == "":
+			fmt.Printf("  %-8s (not found)\n", layer.Name)
+		default:
+			fmt.Printf("  %-8s %s (not found)\n", layer.Name, layer.Source)
+		}
+		if len(layer.Ignored) > 0 {
+			fmt.Printf("  %-8s ignored (global or user config only): %s\n", "", strings.Join(layer.Ignored, ", "))
+		}
+	}
+	fmt.Println()
+
+	settings := cfg.Settings()
+
+	width := 0
+	for _, s := range settings {
+		if len(s.Path) > width {
+			width = len(s.Path)
+		}
+	}
+
+	for _, s := range settings {
+		value, _ := json.Marshal(s.Value)
+		origin := s.Origin.Layer
+		if s.Origin.Source != "" {
+			origin += ": " + s.Origin.Source
+		}
+		fmt.Printf("%-*s = %s  [%s]\n", width, s.Path, value, origin)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureStdout returns what fn prints to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- string(out)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestConfigShowRedactsSecrets(t *testing.T) {
+	root := t.TempDir()
+	xdg := filepath.Join(root, "xdg")
+	t.Setenv("HOME", filepath.Join(root, "home"))
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	t.Setenv("CLAUDE_PLUGIN_ROOT", filepath.Join(root, "plugin"))
+	t.Setenv("NTFY_TOKEN", "tk_supersecret")
+	t.Setenv("MQTT_PASSWORD", "mqtt-supersecret")
+
+	userConfig := `{"notifications": {
+		"webhook": {"enabled": true, "preset": "ntfy", "url": "https://ntfy.sh/alerts",
+			"ntfy": {"token": "${NTFY_TOKEN}"}, "headers": {"Authorization": "Bearer hdr-supersecret"}},
+		"webhooks": [{"name": "dingtalk", "preset": "dingtalk", "url": "https://oapi.dingtalk.com/robot/send",
+			"dingtalk": {"secret": "SECding-supersecret"}}],
+		"mqtt": {"broker": "tcp://localhost:1883", "username": "claude", "password": "${MQTT_PASSWORD}"}
+	}}`
+	path := filepath.Join(xdg, "claude-notifications", "config.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(userConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{{"show"}, {"show", "--origin"}} {
+		var code int
+		out := captureStdout(t, func() {
+			code = runConfig(append(args, "--cwd", root))
+		})
+		if code != 0 {
+			t.Fatalf("config %v exited with %d", args, code)
+		}
+		if !strings.Contains(out, "***") {
+			t.Errorf("config %v: expected redacted values, got:\n%s", args, out)
+		}
+		// Every secret above contains "supersecret"
+		if strings.Contains(out, "supersecret") {
+			t.Errorf("config %v printed a secret:\n%s", args, out)
+		}
+	}
+}
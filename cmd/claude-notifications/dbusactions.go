@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/777genius/claude-notifications/internal/notifier"
+)
+
+// runDBusActions handles the actions of a D-Bus notification until the user acts on
+// it, it is closed or notifier.ActionListenTimeout passes. The D-Bus notifier starts it
+// in the background, so it is not listed in the usage.
+func runDBusActions(args []string) int {
+	fs := flag.NewFlagSet(notifier.ActionListenerCommand, flag.ContinueOnError)
+	id := fs.Uint("id", 0, "Notification ID")
+	window := fs.String("window", "", "X11 window ID of the terminal")
+	if err := fs.Parse(args); err != nil || *id == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: claude-notifications %s --id <notification-id> [--window <window-id>]\n", notifier.ActionListenerCommand)
+		return 1
+	}
+
+	if _, err := logging.InitLogger(getPluginRoot()); err == nil {
+		defer logging.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifier.ActionListenTimeout)
+	defer cancel()
+
+	err := notifier.ListenForActions(ctx, uint32(*id), func() error {
+		return notifier.FocusTerminalWindow(*window)
+	})
+	if err != nil {
+		logging.Error("D-Bus action listener failed: %v", err)
+		return 1
+	}
+	return 0
+}
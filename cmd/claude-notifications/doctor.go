@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/777genius/claude-notifications/internal/doctor"
+)
+
+// runDoctor checks the plugin setup and returns the process exit code (non-zero if any check failed)
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Print the report as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: claude-notifications doctor [--json]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Checks the plugin setup and prints a pass/warn/fail report.")
+		fmt.Fprintln(os.Stderr, "Exits non-zero if any check fails.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		fs.Usage()
+		return 1
+	}
+
+	report := doctor.New(getPluginRoot()).Run()
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else {
+		printDoctorReport(report)
+	}
+
+	if report.HasFailures() {
+		return 1
+	}
+	return 0
+}
+
+// printDoctorReport prints the report in human-readable form
+func printDoctorReport(report *doctor.Report) {
+	fmt.Printf("claude-notifications v%s doctor\n", version)
+	fmt.Printf("Plugin root: %s\n\n", report.PluginRoot)
+
+	width := 0
+	for _, check := range report.Checks {
+		if len(check.Name) > width {
+			width = len(check.Name)
+		}
+	}
+
+	for _, check := range report.Checks {
+		var marker string
+		switch check.Level {
+		case doctor.LevelPass:
+			marker = "✓"
+		case doctor.LevelWarn:
+			marker = "⚠"
+		default:
+			marker = "✗"
+		}
+		fmt.Printf("  %s %-*s  %s\n", marker, width, check.Name, check.Message)
+	}
+
+	fmt.Printf("\n%d passed, %d warnings, %d failed\n",
+		report.Count(doctor.LevelPass), report.Count(doctor.LevelWarn), report.Count(doctor.LevelFail))
+}
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/777genius/claude-notifications/internal/errorhandler"
 	"github.com/777genius/claude-notifications/internal/hooks"
 	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/777genius/claude-notifications/internal/notifier"
 )
 
 const version = "1.13.0"
@@ -22,6 +24,17 @@ func main() {
 	// Add global panic recovery
 	defer errorhandler.HandlePanic()
 
+	// Installed wrappers (bin/sound-preview, bin/list-devices) invoke this
+	// binary under their own name
+	switch invokedAs() {
+	case "sound-preview":
+		soundPreview(os.Args[1:])
+		return
+	case "list-devices":
+		listDevices()
+		return
+	}
+
 	if len(os.Args) < 2 {
 		printUsage()
 		os.Exit(1)
@@ -37,6 +50,26 @@ func main() {
 			os.Exit(1)
 		}
 		handleHook(os.Args[2])
+	case "test":
+		os.Exit(testNotification(os.Args[2:]))
+	case "doctor":
+		os.Exit(runDoctor(os.Args[2:]))
+	case "config":
+		os.Exit(runConfig(os.Args[2:]))
+	case "outbox":
+		os.Exit(runOutbox(os.Args[2:]))
+	case "mute":
+		os.Exit(runMute(os.Args[2:]))
+	case "unmute":
+		os.Exit(runUnmute(os.Args[2:]))
+	case "status":
+		os.Exit(runStatus(os.Args[2:]))
+	case notifier.ActionListenerCommand:
+		os.Exit(runDBusActions(os.Args[2:]))
+	case "sound-preview":
+		soundPreview(os.Args[2:])
+	case "list-devices":
+		listDevices()
 	case "version", "--version", "-v":
 		fmt.Printf("claude-notifications v%s\n", version)
 	case "help", "--help", "-h":
@@ -76,6 +109,11 @@ func handleHook(hookEvent string) {
 	}
 }
 
+// invokedAs returns the executable name without directory or .exe suffix
+func invokedAs() string {
+	return strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+}
+
 func getPluginRoot() string {
 	// Try CLAUDE_PLUGIN_ROOT environment variable first
 	if root := os.Getenv("CLAUDE_PLUGIN_ROOT"); root != "" {
@@ -109,12 +147,31 @@ func printUsage() {
 	fmt.Println()
 	fmt.Println("Usage:")
 	fmt.Println("  claude-notifications handle-hook <HookName>")
+	fmt.Println("  claude-notifications test [--status question] [--channel desktop|webhook|email|mqtt|exec|all]")
+	fmt.Println("  claude-notifications doctor [--json]")
+	fmt.Println("  claude-notifications config show [--origin] [--cwd dir]")
+	fmt.Println("  claude-notifications outbox list|flush|purge")
+	fmt.Println("  claude-notifications mute [--for 30m] [--project dir] [--session id] [--status name]")
+	fmt.Println("  claude-notifications unmute [--id id] [--project dir] [--session id] [--status name]")
+	fmt.Println("  claude-notifications status [--cwd dir]")
+	fmt.Println("  claude-notifications sound-preview <file|status> [--volume 0.0-1.0] [--device name]")
+	fmt.Println("  claude-notifications list-devices")
 	fmt.Println("  claude-notifications version")
 	fmt.Println("  claude-notifications help")
 	fmt.Println()
 	fmt.Println("Commands:")
 	fmt.Println("  handle-hook <HookName>  Handle a Claude Code hook event")
-	fmt.Println("                          HookName: PreToolUse, Stop, SubagentStop, Notification")
+	fmt.Println("                          HookName: PreToolUse, Stop, SubagentStop, Notification,")
+	fmt.Println("                          UserPromptSubmit, SessionEnd")
+	fmt.Println("  test                    Send a test notification through every enabled channel")
+	fmt.Println("  doctor                  Check the plugin setup (config, sounds, hooks, permissions)")
+	fmt.Println("  config show             Show the effective config (--origin: which layer set each value)")
+	fmt.Println("  outbox list|flush|purge Show, redeliver or delete undelivered webhook notifications")
+	fmt.Println("  mute                    Silence notifications, optionally for a time, project, session or status")
+	fmt.Println("  unmute                  Remove mutes (all, or those matching the flags)")
+	fmt.Println("  status                  Show active mutes and quiet hours")
+	fmt.Println("  sound-preview <target>  Play a sound file or the sound configured for a status")
+	fmt.Println("  list-devices            List available audio output devices")
 	fmt.Println("  version                 Show version information")
 	fmt.Println("  help                    Show this help message")
 	fmt.Println()
@@ -125,7 +182,17 @@ func printUsage() {
 	fmt.Println("  # Handle Stop hook")
 	fmt.Println("  echo '{\"session_id\":\"test\",\"transcript_path\":\"/path/to/transcript.jsonl\"}' | claude-notifications handle-hook Stop")
 	fmt.Println()
+	fmt.Println("  # Check that webhooks are configured correctly")
+	fmt.Println("  claude-notifications test --channel webhook")
+	fmt.Println()
+	fmt.Println("  # Silence this project during a meeting")
+	fmt.Println("  claude-notifications mute --for 1h --project .")
+	fmt.Println()
+	fmt.Println("  # Preview the question sound at 30% volume")
+	fmt.Println("  claude-notifications sound-preview question --volume 0.3")
+	fmt.Println()
 	fmt.Println("Environment Variables:")
-	fmt.Println("  CLAUDE_PLUGIN_ROOT  Plugin root directory (auto-detected if not set)")
+	fmt.Println("  CLAUDE_PLUGIN_ROOT      Plugin root directory (auto-detected if not set)")
+	fmt.Println("  CLAUDE_NOTIFICATIONS_*  Config overrides, e.g. CLAUDE_NOTIFICATIONS_DESKTOP_VOLUME=0.5")
 	fmt.Println()
 }
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/schedule"
+	"github.com/777genius/claude-notifications/internal/state"
+)
+
+const (
+	muteUsage   = "Usage: claude-notifications mute [--for 30m] [--project dir] [--session id] [--status name]"
+	unmuteUsage = "Usage: claude-notifications unmute [--id id] [--project dir] [--session id] [--status name]"
+)
+
+// muteFilters are the flags shared by mute and unmute
+type muteFilters struct {
+	project, session, status string
+}
+
+// register adds the filter flags to fs
+func (f *muteFilters) register(fs *flag.FlagSet, verb string) {
+	fs.StringVar(&f.project, "project", "", verb+" sessions in this project directory (\".\" = current directory)")
+	fs.StringVar(&f.session, "session", "", verb+" this session ID")
+	fs.StringVar(&f.status, "status", "", verb+" this status only, e.g. question")
+}
+
+// resolve makes the project path absolute and checks the status name against the
+// config used in the project (or the current directory), including custom statuses
+func (f *muteFilters) resolve() error {
+	if f.project != "" {
+		abs, err := filepath.Abs(f.project)
+		if err != nil {
+			return fmt.Errorf("invalid project directory: %w", err)
+		}
+		f.project = abs
+	}
+	if f.status != "" {
+		dir := f.project
+		if dir == "" {
+			dir, _ = os.Getwd()
+		}
+		cfg, err := config.LoadLayered(config.LoadOptions{PluginRoot: getPluginRoot(), CWD: dir})
+		if err != nil {
+			return err
+		}
+		if _, ok := cfg.Statuses[f.status]; !ok {
+			return fmt.Errorf("unknown status: %s", f.status)
+		}
+	}
+	return nil
+}
+
+// runMute handles the mute command and returns the process exit code
+func runMute(args []string) int {
+	fs := flag.NewFlagSet("mute", flag.ExitOnError)
+	duration := fs.Duration("for", 0, "Mute for this long, e.g. 30m or 2h (default: until unmute)")
+	var filters muteFilters
+	filters.register(fs, "Only mute")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, muteUsage)
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Silences notifications on every channel. Without filters, all notifications")
+		fmt.Fprintln(os.Stderr, "are muted. `claude-notifications status` lists active mutes.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		fs.Usage()
+		return 1
+	}
+	if *duration < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --for must be positive\n")
+		return 1
+	}
+	if err := filters.resolve(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	now := time.Now()
+	mute := state.Mute{Project: filters.project, SessionID: filters.session, Status: filters.status}
+	if *duration > 0 {
+		mute.ExpiresAt = now.Add(*duration)
+	}
+
+	mute, err := state.NewManager().AddMute(mute, now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if mute.ExpiresAt.IsZero() {
+		fmt.Printf("Muted %s until unmuted (id %s)\n", mute.Describe(), mute.ID)
+	} else {
+		fmt.Printf("Muted %s for %s, until %s (id %s)\n",
+			mute.Describe(), *duration, mute.ExpiresAt.Local().Format(time.DateTime), mute.ID)
+	}
+	fmt.Println("Run `claude-notifications unmute` to undo.")
+	return 0
+}
+
+// runUnmute handles the unmute command and returns the process exit code
+func runUnmute(args []string) int {
+	fs := flag.NewFlagSet("unmute", flag.ExitOnError)
+	id := fs.String("id", "", "Remove the mute with this ID (see `claude-notifications status`)")
+	var filters muteFilters
+	filters.register(fs, "Remove mutes of")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, unmuteUsage)
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Removes the mutes that match every given flag. Without flags, all mutes")
+		fmt.Fprintln(os.Stderr, "are removed.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		fs.Usage()
+		return 1
+	}
+	if err := filters.resolve(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	removed, err := state.NewManager().RemoveMutes(time.Now(), func(m state.Mute) bool {
+		return (*id == "" || m.ID == *id) &&
+			(filters.project == "" || m.Project == filters.project) &&
+			(filters.session == "" || m.SessionID == filters.session) &&
+			(filters.status == "" || m.Status == filters.status)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if len(removed) == 0 {
+		fmt.Println("No matching mutes")
+		return 0
+	}
+	for _, m := range removed {
+		fmt.Printf("Unmuted %s (id %s)\n", m.Describe(), m.ID)
+	}
+	return 0
+}
+
+// runStatus handles the status command: active mutes and quiet hours
+func runStatus(args []string) int {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	cwd := fs.String("cwd", "", "Project directory used to find .claude/notifications.json (default: current directory)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: claude-notifications status [--cwd dir]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Shows active mutes and whether quiet hours are in effect.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		fs.Usage()
+		return 1
+	}
+
+	now := time.Now()
+	mutes, err := state.NewManager().ActiveMutes(now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if len(mutes) == 0 {
+		fmt.Println("Mutes: none")
+	} else {
+		fmt.Printf("Mutes: %d active\n", len(mutes))
+		for _, m := range mutes {
+			until := "until unmuted"
+			if !m.ExpiresAt.IsZero() {
+				until = fmt.Sprintf("until %s (%s left)",
+					m.ExpiresAt.Local().Format(time.DateTime), m.ExpiresAt.Sub(now).Round(time.Second))
+			}
+			fmt.Printf("  %s  %s, %s\n", m.ID, m.Describe(), until)
+		}
+	}
+
+	dir := *cwd
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	cfg, err := config.LoadLayered(config.LoadOptions{PluginRoot: getPluginRoot(), CWD: dir})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Println(quietHoursStatus(cfg.Notifications.Schedule, now))
+	return 0
+}
+
+// quietHoursStatus describes whether quiet hours are in effect at now
+func quietHoursStatus(cfg config.ScheduleConfig, now time.Time) string {
+	if !cfg.Enabled {
+		return "Quiet hours: off"
+	}
+	sched, err := schedule.New(cfg)
+	if err != nil {
+		return fmt.Sprintf("Quiet hours: invalid schedule: %v", err)
+	}
+	if sched.Active(now) {
+		return "Quiet hours: not in effect"
+	}
+	next := sched.NextActive(now)
+	if next.IsZero() {
+		return "Quiet hours: in effect"
+	}
+	return fmt.Sprintf("Quiet hours: in effect until %s", next.Local().Format("Mon "+time.DateTime))
+}
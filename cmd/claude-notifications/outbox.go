@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/777genius/claude-notifications/internal/webhook"
+)
+
+const outboxUsage = "Usage: claude-notifications outbox list|flush|purge"
+
+// runOutbox handles the outbox command and returns the process exit code
+func runOutbox(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, outboxUsage)
+		return 1
+	}
+
+	subcommand := args[0]
+	fs := flag.NewFlagSet("outbox "+subcommand, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, outboxUsage)
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Webhook notifications that could not be delivered are kept in the outbox")
+		fmt.Fprintln(os.Stderr, "and redelivered on the next hook with their original X-Request-ID.")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "  list   Show queued notifications")
+		fmt.Fprintln(os.Stderr, "  flush  Redeliver queued notifications now")
+		fmt.Fprintln(os.Stderr, "  purge  Delete all queued notifications")
+	}
+	if err := fs.Parse(args[1:]); err != nil || fs.NArg() > 0 {
+		fs.Usage()
+		return 1
+	}
+
+	switch subcommand {
+	case "list":
+		return outboxList(webhook.NewOutbox(webhook.DefaultOutboxDir()))
+	case "flush":
+		return outboxFlush()
+	case "purge":
+		return outboxPurge(webhook.NewOutbox(webhook.DefaultOutboxDir()))
+	default:
+		fs.Usage()
+		return 1
+	}
+}
+
+// outboxList prints the queued notifications, oldest first
+func outboxList(outbox *webhook.Outbox) int {
+	entries, err := outbox.List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("Outbox is empty (%s)\n", outbox.Dir())
+		return 0
+	}
+
+	fmt.Printf("%d queued notification(s) in %s\n\n", len(entries), outbox.Dir())
+	for _, e := range entries {
+		fmt.Printf("  %s  %-12s %-16s attempts: %d  request ID: %s\n",
+			e.CreatedAt.Local().Format(time.DateTime), e.Target, e.Status, e.Attempts, e.RequestID)
+		switch {
+		case e.Deferred && !e.DeferredUntil.IsZero():
+			fmt.Printf("      deferred during quiet hours, held until %s\n", e.DeferredUntil.Local().Format(time.DateTime))
+		case e.Deferred:
+			fmt.Printf("      deferred during quiet hours\n")
+		}
+		if e.LastError != "" {
+			fmt.Printf("      last error: %s\n", e.LastError)
+		}
+	}
+	return 0
+}
+
+// outboxFlush redelivers the queued notifications using the current webhook config
+func outboxFlush() int {
+	pluginRoot := getPluginRoot()
+
+	if _, err := logging.InitLogger(pluginRoot); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to initialize logger: %v\n", err)
+		return 1
+	}
+	defer logging.Close()
+
+	cwd, _ := os.Getwd()
+	cfg, err := config.LoadLayered(config.LoadOptions{PluginRoot: pluginRoot, CWD: cwd})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	sender := webhook.New(cfg)
+	defer sender.Shutdown(5 * time.Second)
+
+	res, err := sender.Flush()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Delivered: %d, failed: %d, dropped: %d, expired: %d, remaining: %d\n",
+		res.Delivered, res.Failed, res.Dropped, res.Expired, res.Remaining)
+	if res.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// outboxPurge deletes every queued notification
+func outboxPurge(outbox *webhook.Outbox) int {
+	removed, err := outbox.Purge()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Removed %d queued notification(s)\n", removed)
+	return 0
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/777genius/claude-notifications/internal/audio"
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/notifier"
+	"github.com/777genius/claude-notifications/internal/platform"
+)
+
+// soundPreview plays a sound file (or the sound configured for a status)
+// through the same player used for hook notifications
+func soundPreview(args []string) {
+	fs := flag.NewFlagSet("sound-preview", flag.ExitOnError)
+	volume := fs.Float64("volume", -1, "Volume level 0.0-1.0 (default: from config)")
+	device := fs.String("device", "", "Audio output device name (default: from config)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: claude-notifications sound-preview <file|status> [--volume 0.0-1.0] [--device name]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Plays a sound file, or the sound configured for a status")
+		fmt.Fprintln(os.Stderr, "(task_complete, review_complete, question, plan_ready, ...).")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	cwd, _ := os.Getwd()
+	cfg, err := config.LoadLayered(config.LoadOptions{PluginRoot: getPluginRoot(), CWD: cwd})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *volume >= 0 {
+		if *volume > 1.0 {
+			fmt.Fprintf(os.Stderr, "Error: volume must be between 0.0 and 1.0 (got %.2f)\n", *volume)
+			os.Exit(1)
+		}
+		cfg.Notifications.Desktop.Volume = *volume
+	}
+	if *device != "" {
+		cfg.Notifications.Desktop.AudioDevice = *device
+	}
+
+	path, err := resolveSoundTarget(cfg, positional[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	n := notifier.New(cfg)
+	defer n.Close()
+
+	if err := n.PlaySound(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// resolveSoundTarget maps a status name to its configured sound, or returns target as a file path
+func resolveSoundTarget(cfg *config.Config, target string) (string, error) {
+	if info, ok := cfg.GetStatusInfo(target); ok {
+		if info.Sound == "" {
+			return "", fmt.Errorf("no sound configured for status: %s", target)
+		}
+		target = info.Sound
+	}
+
+	if !platform.FileExists(target) {
+		statuses := make([]string, 0, len(cfg.Statuses))
+		for status := range cfg.Statuses {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		return "", fmt.Errorf("sound file not found: %s (statuses: %s)", target, strings.Join(statuses, ", "))
+	}
+
+	return target, nil
+}
+
+// listDevices prints the available audio output devices
+func listDevices() {
+	devices, err := audio.ListDevices()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(devices) == 0 {
+		fmt.Println("No audio output devices found")
+		return
+	}
+
+	fmt.Println("Available audio output devices:")
+	for _, device := range devices {
+		if device.IsDefault {
+			fmt.Printf("  %s (default)\n", device.Name)
+		} else {
+			fmt.Printf("  %s\n", device.Name)
+		}
+	}
+	fmt.Println()
+	fmt.Println("Set \"audioDevice\" in config.json to one of these names (empty = system default)")
+}
+
+// parseInterspersed parses flags that may appear before or after positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/hooks"
+	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/777genius/claude-notifications/internal/webhook"
+)
+
+// testNotification sends a synthetic notification through the configured channels
+// and returns the process exit code (non-zero if any channel failed)
+func testNotification(args []string) int {
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	status := fs.String("status", string(analyzer.StatusTaskComplete), "Status to simulate (task_complete, review_complete, question, plan_ready, ...)")
+	channel := fs.String("channel", hooks.ChannelAll, "Channel to test: desktop, webhook, email, mqtt, exec or all")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: claude-notifications test [--status question] [--channel desktop|webhook|email|mqtt|exec|all]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Sends a test notification, bypassing duplicate detection and cooldowns.")
+		fmt.Fprintln(os.Stderr, "Exits non-zero if any channel fails.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		fs.Usage()
+		return 1
+	}
+
+	pluginRoot := getPluginRoot()
+
+	if _, err := logging.InitLogger(pluginRoot); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to initialize logger: %v\n", err)
+		return 1
+	}
+	defer logging.Close()
+
+	handler, err := hooks.NewHandler(pluginRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = pluginRoot
+	}
+	hookData := &hooks.HookData{
+		SessionID:     fmt.Sprintf("test-%d", time.Now().UnixNano()),
+		CWD:           cwd,
+		HookEventName: "Test",
+	}
+
+	fmt.Printf("Sending test notification (status: %s, channel: %s)\n\n", *status, *channel)
+
+	results, err := handler.SendTest(hookData, analyzer.Status(*status), *channel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	exitCode := 0
+	sent := 0
+	for _, r := range results {
+		switch {
+		case r.Channel == hooks.ChannelWebhook && len(r.Webhooks) > 0:
+			failed := 0
+			for _, w := range r.Webhooks {
+				if w.Err != nil {
+					failed++
+				}
+			}
+			if failed > 0 {
+				exitCode = 1
+				fmt.Printf("  ✗ %-8s %d of %d targets failed\n", r.Channel, failed, len(r.Webhooks))
+			} else {
+				sent++
+				fmt.Printf("  ✓ %-8s sent to %d target(s)\n", r.Channel, len(r.Webhooks))
+			}
+			printWebhookResults(r.Webhooks)
+		case r.Err != nil:
+			exitCode = 1
+			fmt.Printf("  ✗ %-8s %v\n", r.Channel, r.Err)
+		case r.Skipped:
+			fmt.Printf("  - %-8s disabled in config\n", r.Channel)
+		case r.Channel == hooks.ChannelWebhook:
+			fmt.Printf("  - %-8s no target accepts status %s\n", r.Channel, *status)
+		default:
+			sent++
+			fmt.Printf("  ✓ %-8s sent\n", r.Channel)
+		}
+	}
+
+	if exitCode == 0 && sent == 0 {
+		fmt.Println()
+		fmt.Println("No notification channels are enabled")
+		return 1
+	}
+
+	return exitCode
+}
+
+// printWebhookResults prints the delivery details of each webhook target
+func printWebhookResults(results []webhook.Result) {
+	width := 0
+	for _, r := range results {
+		if len(r.Target) > width {
+			width = len(r.Target)
+		}
+	}
+
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Printf("      ✗ %-*s  %v%s\n", width, r.Target, r.Err, formatWebhookResult(r))
+		} else {
+			fmt.Printf("      ✓ %-*s  sent%s\n", width, r.Target, formatWebhookResult(r))
+		}
+	}
+}
+
+// formatWebhookResult describes webhook delivery details, or returns "" if no request was made
+func formatWebhookResult(r webhook.Result) string {
+	if r.RequestID == "" {
+		return ""
+	}
+
+	attempts := "attempt"
+	if r.Attempts != 1 {
+		attempts = "attempts"
+	}
+
+	code := "no response"
+	if r.StatusCode != 0 {
+		code = fmt.Sprintf("HTTP %d", r.StatusCode)
+	}
+
+	return fmt.Sprintf(" (%s, %v, %d %s, request ID: %s)",
+		code, r.Latency.Round(time.Millisecond), r.Attempts, attempts, r.RequestID)
+}
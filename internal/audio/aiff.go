@@ -0,0 +1,208 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/gopxl/beep/v2"
+)
+
+// aiffStreamer streams PCM frames decoded from an AIFF file.
+// Notification sounds are short, so the sample data is held in memory.
+type aiffStreamer struct {
+	closer   io.Closer
+	data     []byte
+	channels int
+	width    int // bytes per sample
+	pos      int // position in frames
+	err      error
+}
+
+// decodeAIFF decodes an uncompressed AIFF (or AIFF-C "NONE"/"sowt") stream
+func decodeAIFF(rc io.ReadCloser) (beep.StreamSeekCloser, beep.Format, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(rc, header[:]); err != nil {
+		return nil, beep.Format{}, fmt.Errorf("failed to read AIFF header: %w", err)
+	}
+	if string(header[0:4]) != "FORM" {
+		return nil, beep.Format{}, errors.New("not an AIFF file: missing FORM chunk")
+	}
+	formType := string(header[8:12])
+	if formType != "AIFF" && formType != "AIFC" {
+		return nil, beep.Format{}, fmt.Errorf("not an AIFF file: unexpected form type %q", formType)
+	}
+
+	var (
+		channels     int
+		sampleBits   int
+		sampleRate   float64
+		littleEndian bool
+		data         []byte
+		haveComm     bool
+	)
+
+	for data == nil {
+		var chunkHeader [8]byte
+		if _, err := io.ReadFull(rc, chunkHeader[:]); err != nil {
+			return nil, beep.Format{}, fmt.Errorf("AIFF sound data not found: %w", err)
+		}
+		id := string(chunkHeader[0:4])
+		size := int64(binary.BigEndian.Uint32(chunkHeader[4:8]))
+		// Chunks are padded to an even number of bytes
+		padded := size + size%2
+
+		switch id {
+		case "COMM":
+			body := make([]byte, padded)
+			if _, err := io.ReadFull(rc, body); err != nil {
+				return nil, beep.Format{}, fmt.Errorf("failed to read COMM chunk: %w", err)
+			}
+			if len(body) < 18 {
+				return nil, beep.Format{}, errors.New("COMM chunk too short")
+			}
+			channels = int(binary.BigEndian.Uint16(body[0:2]))
+			sampleBits = int(binary.BigEndian.Uint16(body[6:8]))
+			sampleRate = parseExtended(body[8:18])
+			if formType == "AIFC" && len(body) >= 22 {
+				switch compression := string(body[18:22]); compression {
+				case "NONE", "twos":
+				case "sowt":
+					littleEndian = true
+				default:
+					return nil, beep.Format{}, fmt.Errorf("unsupported AIFF-C compression: %q", compression)
+				}
+			}
+			haveComm = true
+
+		case "SSND":
+			if !haveComm {
+				return nil, beep.Format{}, errors.New("SSND chunk appears before COMM chunk")
+			}
+			body := make([]byte, padded)
+			if _, err := io.ReadFull(rc, body); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil, beep.Format{}, fmt.Errorf("failed to read SSND chunk: %w", err)
+			}
+			if len(body) < 8 {
+				return nil, beep.Format{}, errors.New("SSND chunk too short")
+			}
+			offset := int(binary.BigEndian.Uint32(body[0:4]))
+			if 8+offset > int(size) {
+				return nil, beep.Format{}, errors.New("invalid SSND offset")
+			}
+			data = body[8+offset : size]
+
+		default:
+			if _, err := io.CopyN(io.Discard, rc, padded); err != nil {
+				return nil, beep.Format{}, fmt.Errorf("failed to skip %s chunk: %w", id, err)
+			}
+		}
+	}
+
+	if channels < 1 || channels > 2 {
+		return nil, beep.Format{}, fmt.Errorf("unsupported AIFF channel count: %d", channels)
+	}
+	if sampleBits < 1 || sampleBits > 32 {
+		return nil, beep.Format{}, fmt.Errorf("unsupported AIFF sample size: %d bits", sampleBits)
+	}
+	if sampleRate <= 0 {
+		return nil, beep.Format{}, fmt.Errorf("invalid AIFF sample rate: %v", sampleRate)
+	}
+
+	width := (sampleBits + 7) / 8
+
+	// Normalize to big-endian so streaming has a single code path
+	if littleEndian {
+		for i := 0; i+width <= len(data); i += width {
+			for l, r := i, i+width-1; l < r; l, r = l+1, r-1 {
+				data[l], data[r] = data[r], data[l]
+			}
+		}
+	}
+
+	format := beep.Format{
+		SampleRate:  beep.SampleRate(math.Round(sampleRate)),
+		NumChannels: channels,
+		Precision:   width,
+	}
+
+	return &aiffStreamer{
+		closer:   rc,
+		data:     data,
+		channels: channels,
+		width:    width,
+	}, format, nil
+}
+
+// parseExtended converts an 80-bit IEEE 754 extended precision float (big-endian)
+func parseExtended(b []byte) float64 {
+	exponent := int(binary.BigEndian.Uint16(b[0:2]))
+	mantissa := binary.BigEndian.Uint64(b[2:10])
+
+	sign := 1.0
+	if exponent&0x8000 != 0 {
+		sign = -1.0
+		exponent &= 0x7fff
+	}
+	if exponent == 0 && mantissa == 0 {
+		return 0
+	}
+
+	return sign * float64(mantissa) * math.Pow(2, float64(exponent-16383-63))
+}
+
+// sample decodes one signed big-endian sample into the -1.0..1.0 range
+func (s *aiffStreamer) sample(offset int) float64 {
+	var v int64
+	for i := 0; i < s.width; i++ {
+		v = v<<8 | int64(s.data[offset+i])
+	}
+	bits := uint(s.width * 8)
+	// Sign-extend
+	if v&(1<<(bits-1)) != 0 {
+		v -= 1 << bits
+	}
+	return float64(v) / float64(int64(1)<<(bits-1))
+}
+
+func (s *aiffStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	frameSize := s.width * s.channels
+	for n < len(samples) && s.pos < s.Len() {
+		offset := s.pos * frameSize
+		left := s.sample(offset)
+		right := left
+		if s.channels == 2 {
+			right = s.sample(offset + s.width)
+		}
+		samples[n] = [2]float64{left, right}
+		n++
+		s.pos++
+	}
+	return n, n > 0
+}
+
+func (s *aiffStreamer) Err() error {
+	return s.err
+}
+
+func (s *aiffStreamer) Len() int {
+	return len(s.data) / (s.width * s.channels)
+}
+
+func (s *aiffStreamer) Position() int {
+	return s.pos
+}
+
+func (s *aiffStreamer) Seek(p int) error {
+	if p < 0 || p > s.Len() {
+		return fmt.Errorf("aiff: seek position %d out of range [0, %d]", p, s.Len())
+	}
+	s.pos = p
+	return nil
+}
+
+func (s *aiffStreamer) Close() error {
+	return s.closer.Close()
+}
@@ -0,0 +1,179 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeExtended converts a positive integer-valued float to 80-bit extended precision
+func encodeExtended(v float64) []byte {
+	b := make([]byte, 10)
+	if v == 0 {
+		return b
+	}
+	exp := int(math.Floor(math.Log2(v)))
+	mantissa := uint64(v * math.Pow(2, float64(63-exp)))
+	binary.BigEndian.PutUint16(b[0:2], uint16(exp+16383))
+	binary.BigEndian.PutUint64(b[2:10], mantissa)
+	return b
+}
+
+// buildAIFF assembles an AIFF/AIFC file from raw sample data
+func buildAIFF(formType, compression string, channels, bits int, rate float64, samples []byte) []byte {
+	comm := new(bytes.Buffer)
+	_ = binary.Write(comm, binary.BigEndian, uint16(channels))
+	_ = binary.Write(comm, binary.BigEndian, uint32(len(samples)/(channels*((bits+7)/8))))
+	_ = binary.Write(comm, binary.BigEndian, uint16(bits))
+	comm.Write(encodeExtended(rate))
+	if formType == "AIFC" {
+		comm.WriteString(compression)
+		comm.Write([]byte{0, 0}) // empty pascal string, padded
+	}
+
+	body := new(bytes.Buffer)
+	body.WriteString(formType)
+	writeChunk := func(id string, data []byte) {
+		body.WriteString(id)
+		_ = binary.Write(body, binary.BigEndian, uint32(len(data)))
+		body.Write(data)
+		if len(data)%2 == 1 {
+			body.WriteByte(0)
+		}
+	}
+	writeChunk("COMM", comm.Bytes())
+	writeChunk("ANNO", []byte("test"))
+	writeChunk("SSND", append(make([]byte, 8), samples...))
+
+	out := new(bytes.Buffer)
+	out.WriteString("FORM")
+	_ = binary.Write(out, binary.BigEndian, uint32(body.Len()))
+	out.Write(body.Bytes())
+	return out.Bytes()
+}
+
+func writeAIFF(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.aiff")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func TestParseExtended(t *testing.T) {
+	for _, rate := range []float64{8000, 22050, 44100, 48000, 96000} {
+		assert.Equal(t, rate, parseExtended(encodeExtended(rate)))
+	}
+	assert.Equal(t, 0.0, parseExtended(make([]byte, 10)))
+}
+
+func TestDecodeAIFF_16BitStereo(t *testing.T) {
+	// Two frames: (0.5, -0.5), (-1.0, 0.25)
+	samples := []byte{
+		0x40, 0x00, 0xc0, 0x00,
+		0x80, 0x00, 0x20, 0x00,
+	}
+	path := writeAIFF(t, buildAIFF("AIFF", "", 2, 16, 44100, samples))
+
+	streamer, format, err := Decode(path)
+	require.NoError(t, err)
+	defer streamer.Close()
+
+	assert.Equal(t, beep.SampleRate(44100), format.SampleRate)
+	assert.Equal(t, 2, format.NumChannels)
+	assert.Equal(t, 2, streamer.Len())
+
+	buf := make([][2]float64, 4)
+	n, ok := streamer.Stream(buf)
+	require.True(t, ok)
+	require.Equal(t, 2, n)
+	assert.Equal(t, [2]float64{0.5, -0.5}, buf[0])
+	assert.Equal(t, [2]float64{-1.0, 0.25}, buf[1])
+
+	n, ok = streamer.Stream(buf)
+	assert.False(t, ok)
+	assert.Equal(t, 0, n)
+}
+
+func TestDecodeAIFF_8BitMono(t *testing.T) {
+	samples := []byte{0x40, 0xc0, 0x00} // odd length exercises chunk padding
+	path := writeAIFF(t, buildAIFF("AIFF", "", 1, 8, 22050, samples))
+
+	streamer, format, err := Decode(path)
+	require.NoError(t, err)
+	defer streamer.Close()
+
+	assert.Equal(t, 1, format.NumChannels)
+	assert.Equal(t, 3, streamer.Len())
+
+	buf := make([][2]float64, 3)
+	n, _ := streamer.Stream(buf)
+	require.Equal(t, 3, n)
+	// Mono is duplicated to both channels
+	assert.Equal(t, [2]float64{0.5, 0.5}, buf[0])
+	assert.Equal(t, [2]float64{-0.5, -0.5}, buf[1])
+	assert.Equal(t, [2]float64{0, 0}, buf[2])
+}
+
+func TestDecodeAIFF_SowtLittleEndian(t *testing.T) {
+	samples := []byte{0x00, 0x40} // 0x4000 little-endian = 0.5
+	path := writeAIFF(t, buildAIFF("AIFC", "sowt", 1, 16, 48000, samples))
+
+	streamer, _, err := Decode(path)
+	require.NoError(t, err)
+	defer streamer.Close()
+
+	buf := make([][2]float64, 1)
+	_, ok := streamer.Stream(buf)
+	require.True(t, ok)
+	assert.Equal(t, 0.5, buf[0][0])
+}
+
+func TestDecodeAIFF_Seek(t *testing.T) {
+	samples := []byte{0x10, 0x00, 0x20, 0x00, 0x40, 0x00}
+	path := writeAIFF(t, buildAIFF("AIFF", "", 1, 16, 8000, samples))
+
+	streamer, _, err := Decode(path)
+	require.NoError(t, err)
+	defer streamer.Close()
+
+	require.NoError(t, streamer.Seek(2))
+	assert.Equal(t, 2, streamer.Position())
+
+	buf := make([][2]float64, 1)
+	_, ok := streamer.Stream(buf)
+	require.True(t, ok)
+	assert.Equal(t, 0.5, buf[0][0])
+
+	assert.Error(t, streamer.Seek(4))
+	assert.Error(t, streamer.Seek(-1))
+}
+
+func TestDecodeAIFF_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"truncated header", []byte("FORM"), "failed to read AIFF header"},
+		{"not FORM", []byte("RIFF\x00\x00\x00\x04WAVE"), "missing FORM chunk"},
+		{"wrong form type", []byte("FORM\x00\x00\x00\x04WAVE"), "unexpected form type"},
+		{"no sound data", []byte("FORM\x00\x00\x00\x04AIFF"), "sound data not found"},
+		{"unsupported compression", buildAIFF("AIFC", "ulaw", 1, 16, 8000, []byte{0, 0}), "unsupported AIFF-C compression"},
+		{"too many channels", buildAIFF("AIFF", "", 6, 16, 8000, make([]byte, 12)), "unsupported AIFF channel count"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Decode(writeAIFF(t, tt.data))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
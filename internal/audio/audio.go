@@ -0,0 +1,176 @@
+// Package audio decodes notification sounds and plays them on an output device.
+//
+// Supported formats: MP3, WAV, OGG Vorbis and AIFF. Playback goes through a Sink,
+// which is the audio device by default (via miniaudio/malgo). NullSink and FileSink
+// allow the full decode/volume path to be exercised without audio hardware.
+package audio
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/effects"
+	"github.com/gopxl/beep/v2/mp3"
+	"github.com/gopxl/beep/v2/vorbis"
+	"github.com/gopxl/beep/v2/wav"
+)
+
+// SupportedExtensions lists file extensions that Decode understands
+var SupportedExtensions = []string{".mp3", ".wav", ".ogg", ".aiff", ".aif"}
+
+// Sink consumes a decoded audio stream (an output device, a file, or nothing)
+type Sink interface {
+	// Play streams s to the sink and blocks until the stream is drained
+	Play(s beep.Streamer, format beep.Format) error
+	// Close releases resources held by the sink
+	Close() error
+}
+
+// Player plays sound files through a sink with volume control
+type Player struct {
+	sink   Sink
+	volume float64
+}
+
+// NewPlayer creates a player for the named output device (empty = system default).
+// volume is a linear level in the 0.0-1.0 range.
+func NewPlayer(deviceName string, volume float64) (*Player, error) {
+	sink, err := NewDeviceSink(deviceName)
+	if err != nil {
+		return nil, err
+	}
+	return NewPlayerWithSink(sink, volume), nil
+}
+
+// NewPlayerWithSink creates a player that writes to a custom sink
+func NewPlayerWithSink(sink Sink, volume float64) *Player {
+	return &Player{
+		sink:   sink,
+		volume: volume,
+	}
+}
+
+// Play decodes the file at path and plays it, blocking until playback completes
+func (p *Player) Play(path string) error {
+	streamer, format, err := Decode(path)
+	if err != nil {
+		return err
+	}
+	defer streamer.Close()
+
+	if err := p.sink.Play(ApplyVolume(streamer, p.volume), format); err != nil {
+		return fmt.Errorf("failed to play %s: %w", filepath.Base(path), err)
+	}
+
+	return streamer.Err()
+}
+
+// Close releases the underlying sink
+func (p *Player) Close() error {
+	return p.sink.Close()
+}
+
+// Decode opens a sound file and returns a streamer for it based on its extension
+func Decode(path string) (beep.StreamSeekCloser, beep.Format, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, beep.Format{}, fmt.Errorf("failed to open sound file: %w", err)
+	}
+
+	var (
+		streamer beep.StreamSeekCloser
+		format   beep.Format
+	)
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".mp3":
+		streamer, format, err = mp3.Decode(f)
+	case ".wav":
+		streamer, format, err = wav.Decode(f)
+	case ".ogg":
+		streamer, format, err = vorbis.Decode(f)
+	case ".aiff", ".aif":
+		streamer, format, err = decodeAIFF(f)
+	default:
+		f.Close()
+		return nil, beep.Format{}, fmt.Errorf("unsupported sound format: %s (must be one of: %s)",
+			ext, strings.Join(SupportedExtensions, ", "))
+	}
+
+	if err != nil {
+		f.Close()
+		return nil, beep.Format{}, fmt.Errorf("failed to decode %s: %w", filepath.Base(path), err)
+	}
+
+	return streamer, format, nil
+}
+
+// ApplyVolume wraps a streamer with logarithmic volume scaling.
+// Linear volume is converted to log2 units so that 0.5 sounds "half as loud":
+// 1.0 → 0.0 (unchanged), 0.5 → -1.0, 0.1 → -3.3. Volume <= 0 mutes the stream.
+func ApplyVolume(s beep.Streamer, volume float64) beep.Streamer {
+	if volume >= 1.0 {
+		return s
+	}
+
+	return &effects.Volume{
+		Streamer: s,
+		Base:     2,
+		Volume:   math.Log2(math.Max(volume, 1e-6)),
+		Silent:   volume <= 0,
+	}
+}
+
+// NullSink drains streams without producing any output.
+// Useful for validating sound files and in tests.
+type NullSink struct {
+	// Samples is the total number of samples consumed across all Play calls
+	Samples int
+}
+
+// Play reads the stream until it is exhausted
+func (s *NullSink) Play(streamer beep.Streamer, format beep.Format) error {
+	buf := make([][2]float64, 512)
+	for {
+		n, ok := streamer.Stream(buf)
+		s.Samples += n
+		if !ok {
+			return streamer.Err()
+		}
+	}
+}
+
+// Close is a no-op
+func (s *NullSink) Close() error {
+	return nil
+}
+
+// FileSink writes each played stream to a WAV file
+type FileSink struct {
+	Path string
+}
+
+// Play encodes the stream as 16-bit WAV at the sink's path (overwriting it)
+func (s *FileSink) Play(streamer beep.Streamer, format beep.Format) error {
+	f, err := os.Create(s.Path)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer f.Close()
+
+	format.Precision = 2
+	if err := wav.Encode(f, streamer, format); err != nil {
+		return fmt.Errorf("failed to encode WAV: %w", err)
+	}
+
+	return nil
+}
+
+// Close is a no-op
+func (s *FileSink) Close() error {
+	return nil
+}
@@ -0,0 +1,158 @@
+package audio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/wav"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// constStreamer produces n frames of a constant amplitude
+func constStreamer(n int, amplitude float64) beep.Streamer {
+	remaining := n
+	return beep.StreamerFunc(func(samples [][2]float64) (int, bool) {
+		if remaining == 0 {
+			return 0, false
+		}
+		count := len(samples)
+		if count > remaining {
+			count = remaining
+		}
+		for i := 0; i < count; i++ {
+			samples[i] = [2]float64{amplitude, -amplitude}
+		}
+		remaining -= count
+		return count, true
+	})
+}
+
+// writeTestWAV writes a short 16-bit stereo WAV file and returns its path
+func writeTestWAV(t *testing.T, frames int, amplitude float64) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.wav")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	format := beep.Format{SampleRate: 8000, NumChannels: 2, Precision: 2}
+	require.NoError(t, wav.Encode(f, constStreamer(frames, amplitude), format))
+	return path
+}
+
+func TestDecode_WAV(t *testing.T) {
+	path := writeTestWAV(t, 800, 0.5)
+
+	streamer, format, err := Decode(path)
+	require.NoError(t, err)
+	defer streamer.Close()
+
+	assert.Equal(t, beep.SampleRate(8000), format.SampleRate)
+	assert.Equal(t, 2, format.NumChannels)
+	assert.Equal(t, 800, streamer.Len())
+}
+
+func TestDecode_BundledSounds(t *testing.T) {
+	sounds, err := filepath.Glob(filepath.Join("..", "..", "sounds", "*.mp3"))
+	require.NoError(t, err)
+	require.NotEmpty(t, sounds, "bundled sounds not found")
+
+	for _, path := range sounds {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			sink := &NullSink{}
+			player := NewPlayerWithSink(sink, 1.0)
+			require.NoError(t, player.Play(path))
+			assert.Greater(t, sink.Samples, 0)
+		})
+	}
+}
+
+func TestDecode_UnsupportedFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sound.flac")
+	require.NoError(t, os.WriteFile(path, []byte("fLaC"), 0644))
+
+	_, _, err := Decode(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported sound format")
+}
+
+func TestDecode_MissingFile(t *testing.T) {
+	_, _, err := Decode(filepath.Join(t.TempDir(), "missing.mp3"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to open sound file")
+}
+
+func TestDecode_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corrupt.wav")
+	require.NoError(t, os.WriteFile(path, []byte("not really a wav file"), 0644))
+
+	_, _, err := Decode(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode")
+}
+
+func TestApplyVolume(t *testing.T) {
+	tests := []struct {
+		name   string
+		volume float64
+		want   float64
+	}{
+		{"full volume", 1.0, 0.8},
+		{"half volume", 0.5, 0.4},
+		{"quarter volume", 0.25, 0.2},
+		{"muted", 0.0, 0.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := ApplyVolume(constStreamer(4, 0.8), tt.volume)
+			buf := make([][2]float64, 4)
+			n, ok := s.Stream(buf)
+			require.True(t, ok)
+			require.Equal(t, 4, n)
+			assert.InDelta(t, tt.want, buf[0][0], 1e-9)
+			assert.InDelta(t, -tt.want, buf[0][1], 1e-9)
+		})
+	}
+}
+
+func TestPlayer_NullSink(t *testing.T) {
+	path := writeTestWAV(t, 1000, 0.5)
+	sink := &NullSink{}
+	player := NewPlayerWithSink(sink, 0.7)
+	defer player.Close()
+
+	require.NoError(t, player.Play(path))
+	assert.Equal(t, 1000, sink.Samples)
+}
+
+func TestPlayer_FileSinkAppliesVolume(t *testing.T) {
+	path := writeTestWAV(t, 400, 0.8)
+	outPath := filepath.Join(t.TempDir(), "out.wav")
+
+	player := NewPlayerWithSink(&FileSink{Path: outPath}, 0.5)
+	require.NoError(t, player.Play(path))
+
+	out, format, err := Decode(outPath)
+	require.NoError(t, err)
+	defer out.Close()
+
+	assert.Equal(t, beep.SampleRate(8000), format.SampleRate)
+	assert.Equal(t, 400, out.Len())
+
+	buf := make([][2]float64, 1)
+	_, ok := out.Stream(buf)
+	require.True(t, ok)
+	// 16-bit quantization allows a small error
+	assert.InDelta(t, 0.4, buf[0][0], 1e-3)
+	assert.InDelta(t, -0.4, buf[0][1], 1e-3)
+}
+
+func TestPlayer_PlayMissingFile(t *testing.T) {
+	player := NewPlayerWithSink(&NullSink{}, 1.0)
+	err := player.Play(filepath.Join(t.TempDir(), "missing.wav"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,168 @@
+//go:build cgo
+
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gen2brain/malgo"
+	"github.com/gopxl/beep/v2"
+)
+
+// maxPlaybackDuration guards against a device that never finishes consuming frames
+const maxPlaybackDuration = 30 * time.Second
+
+// DeviceInfo describes an audio output device
+type DeviceInfo struct {
+	Name      string
+	IsDefault bool
+}
+
+// deviceSink plays audio through a miniaudio playback device
+type deviceSink struct {
+	deviceName string
+	backends   []malgo.Backend // nil = platform defaults
+}
+
+// NewDeviceSink creates a sink for the named output device (empty = system default).
+// The device is resolved on each Play, so a missing device is reported at playback time.
+func NewDeviceSink(deviceName string) (Sink, error) {
+	return &deviceSink{deviceName: deviceName}, nil
+}
+
+// ListDevices returns the available audio output devices
+func ListDevices() ([]DeviceInfo, error) {
+	return listDevices(nil)
+}
+
+func listDevices(backends []malgo.Backend) ([]DeviceInfo, error) {
+	ctx, err := malgo.InitContext(backends, malgo.ContextConfig{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize audio context: %w", err)
+	}
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	infos, err := ctx.Devices(malgo.Playback)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enumerate playback devices: %w", err)
+	}
+
+	devices := make([]DeviceInfo, 0, len(infos))
+	for _, info := range infos {
+		devices = append(devices, DeviceInfo{
+			Name:      info.Name(),
+			IsDefault: info.IsDefault != 0,
+		})
+	}
+	return devices, nil
+}
+
+// findDevice looks up a playback device by exact name, falling back to a
+// case-insensitive substring match
+func findDevice(ctx *malgo.AllocatedContext, name string) (*malgo.DeviceInfo, error) {
+	infos, err := ctx.Devices(malgo.Playback)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enumerate playback devices: %w", err)
+	}
+
+	for i := range infos {
+		if infos[i].Name() == name {
+			return &infos[i], nil
+		}
+	}
+	lower := strings.ToLower(name)
+	for i := range infos {
+		if strings.Contains(strings.ToLower(infos[i].Name()), lower) {
+			return &infos[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("audio device not found: %q (run 'claude-notifications list-devices' to see available devices)", name)
+}
+
+// Play opens the playback device at the stream's sample rate and blocks until the stream ends
+func (s *deviceSink) Play(streamer beep.Streamer, format beep.Format) error {
+	ctx, err := malgo.InitContext(s.backends, malgo.ContextConfig{}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to initialize audio context: %w", err)
+	}
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Playback)
+	deviceConfig.Playback.Format = malgo.FormatF32
+	deviceConfig.Playback.Channels = 2
+	deviceConfig.SampleRate = uint32(format.SampleRate)
+
+	if s.deviceName != "" {
+		info, err := findDevice(ctx, s.deviceName)
+		if err != nil {
+			return err
+		}
+		deviceConfig.Playback.DeviceID = info.ID.Pointer()
+	}
+
+	done := make(chan struct{})
+	var once sync.Once
+	finish := func() { once.Do(func() { close(done) }) }
+
+	buf := make([][2]float64, 0)
+	onSamples := func(output, _ []byte, frameCount uint32) {
+		if cap(buf) < int(frameCount) {
+			buf = make([][2]float64, frameCount)
+		}
+		buf = buf[:frameCount]
+
+		n, ok := streamer.Stream(buf)
+		for i := 0; i < n; i++ {
+			binary.LittleEndian.PutUint32(output[i*8:], math.Float32bits(float32(buf[i][0])))
+			binary.LittleEndian.PutUint32(output[i*8+4:], math.Float32bits(float32(buf[i][1])))
+		}
+		// Pad the rest of the period with silence
+		for i := n * 8; i < len(output); i++ {
+			output[i] = 0
+		}
+		if !ok || n < int(frameCount) {
+			finish()
+		}
+	}
+
+	device, err := malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{
+		Data: onSamples,
+		Stop: finish,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open audio device: %w", err)
+	}
+	defer device.Uninit()
+
+	if err := device.Start(); err != nil {
+		return fmt.Errorf("failed to start audio device: %w", err)
+	}
+
+	select {
+	case <-done:
+		// Let the final period reach the speakers before stopping
+		time.Sleep(100 * time.Millisecond)
+	case <-time.After(maxPlaybackDuration):
+		_ = device.Stop()
+		return fmt.Errorf("playback timed out after %v", maxPlaybackDuration)
+	}
+
+	return device.Stop()
+}
+
+// Close is a no-op; device resources are released after each Play
+func (s *deviceSink) Close() error {
+	return nil
+}
@@ -0,0 +1,63 @@
+//go:build linux && cgo
+
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gen2brain/malgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The null backend consumes frames in real time without audio hardware,
+// so these tests exercise the full device path in CI.
+//
+// malgo's Backend enumeration omits miniaudio's ma_backend_custom, which sits
+// between webaudio and null, so malgo.BackendNull actually selects "custom".
+const backendNull = malgo.Backend(malgo.BackendNull + 1)
+
+func TestDeviceSink_NullBackend(t *testing.T) {
+	path := writeTestWAV(t, 2000, 0.5) // 250ms at 8kHz
+
+	sink := &deviceSink{backends: []malgo.Backend{backendNull}}
+	player := NewPlayerWithSink(sink, 0.5)
+	defer player.Close()
+
+	start := time.Now()
+	require.NoError(t, player.Play(path))
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond, "playback should block until the stream is drained")
+}
+
+func TestDeviceSink_UnknownDevice(t *testing.T) {
+	path := writeTestWAV(t, 100, 0.5)
+
+	sink := &deviceSink{
+		deviceName: "definitely-not-a-real-device",
+		backends:   []malgo.Backend{backendNull},
+	}
+	err := NewPlayerWithSink(sink, 1.0).Play(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "audio device not found")
+}
+
+func TestListDevices_NullBackend(t *testing.T) {
+	devices, err := listDevices([]malgo.Backend{backendNull})
+	require.NoError(t, err)
+	require.NotEmpty(t, devices)
+	assert.NotEmpty(t, devices[0].Name)
+}
+
+func TestDeviceSink_NamedNullDevice(t *testing.T) {
+	devices, err := listDevices([]malgo.Backend{backendNull})
+	require.NoError(t, err)
+	require.NotEmpty(t, devices)
+
+	path := writeTestWAV(t, 400, 0.5)
+	sink := &deviceSink{
+		deviceName: devices[0].Name,
+		backends:   []malgo.Backend{backendNull},
+	}
+	assert.NoError(t, NewPlayerWithSink(sink, 1.0).Play(path))
+}
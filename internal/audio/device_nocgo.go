@@ -0,0 +1,24 @@
+//go:build !cgo
+
+package audio
+
+import "errors"
+
+// ErrNoAudioSupport is returned when the binary was built without cgo (miniaudio requires it)
+var ErrNoAudioSupport = errors.New("audio playback is not supported in this build (requires CGO_ENABLED=1)")
+
+// DeviceInfo describes an audio output device
+type DeviceInfo struct {
+	Name      string
+	IsDefault bool
+}
+
+// NewDeviceSink returns ErrNoAudioSupport in builds without cgo
+func NewDeviceSink(deviceName string) (Sink, error) {
+	return nil, ErrNoAudioSupport
+}
+
+// ListDevices returns ErrNoAudioSupport in builds without cgo
+func ListDevices() ([]DeviceInfo, error) {
+	return nil, ErrNoAudioSupport
+}
@@ -0,0 +1,217 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/errorhandler"
+	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/777genius/claude-notifications/internal/platform"
+	"github.com/777genius/claude-notifications/internal/sessionname"
+)
+
+const (
+	// defaultTimeout applies when the configured timeout can't be parsed
+	defaultTimeout = 10 * time.Second
+
+	// maxStderr is the number of stderr bytes kept for the log
+	maxStderr = 4096
+
+	// waitDelay bounds waiting for the command's output after it exits or is killed,
+	// e.g. when it started a background process that inherited stderr
+	waitDelay = time.Second
+)
+
+// Notification is the JSON written to the command's stdin
+type Notification struct {
+	Status      string `json:"status"`
+	Title       string `json:"title"`
+	Message     string `json:"message"`
+	SessionID   string `json:"sessionId"`
+	SessionName string `json:"sessionName"`
+	Branch      string `json:"branch"`
+	Project     string `json:"project"`
+	CWD         string `json:"cwd"`
+}
+
+// Runner runs the user-configured command of the exec channel for each notification
+type Runner struct {
+	cfg *config.Config
+	wg  sync.WaitGroup
+}
+
+// New creates a runner for the exec settings of cfg
+func New(cfg *config.Config) *Runner {
+	return &Runner{cfg: cfg}
+}
+
+// Run runs the command for a notification if the exec channel is enabled and the
+// status passes the statuses filter. It returns an error if the command fails,
+// times out or exits non-zero; stderr is written to the log either way.
+func (r *Runner) Run(status analyzer.Status, message, sessionID, cwd string) error {
+	e := r.cfg.Notifications.Exec
+	if !e.Enabled {
+		logging.Debug("Exec disabled, skipping")
+		return nil
+	}
+	if !matchesStatus(e.Statuses, status) {
+		logging.Debug("Exec: filtered out (status=%s)", status)
+		return nil
+	}
+
+	statusInfo, _ := r.cfg.GetStatusInfo(string(status))
+	n := Notification{
+		Status:      string(status),
+		Title:       statusInfo.Title,
+		Message:     message,
+		SessionID:   sessionID,
+		SessionName: sessionname.GenerateSessionName(sessionID),
+		Branch:      platform.GetGitBranch(cwd),
+		Project:     project(cwd),
+		CWD:         cwd,
+	}
+	stdin, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	timeout, err := time.ParseDuration(e.Timeout)
+	if err != nil || timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, e.Command[0], e.Command[1:]...)
+	cmd.Env = append(os.Environ(), environ(n)...)
+	cmd.Stdin = bytes.NewReader(append(stdin, '\n'))
+	cmd.WaitDelay = waitDelay
+	if info, err := os.Stat(cwd); cwd != "" && err == nil && info.IsDir() {
+		cmd.Dir = cwd
+	}
+	stderr := &limitedBuffer{limit: maxStderr}
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	err = cmd.Run()
+	output := strings.TrimSpace(stderr.String())
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		err = fmt.Errorf("%s timed out after %v", e.Command[0], timeout)
+	case err != nil:
+		err = fmt.Errorf("%s failed: %w", e.Command[0], err)
+	}
+
+	if err != nil {
+		if output != "" {
+			logging.Error("Exec: %v, stderr: %s", err, output)
+			return fmt.Errorf("%w: %s", err, output)
+		}
+		logging.Error("Exec: %v", err)
+		return err
+	}
+
+	if output != "" {
+		logging.Info("Exec: %s stderr: %s", e.Command[0], output)
+	}
+	logging.Info("Exec: %s completed (status=%s, duration: %v)", e.Command[0], status, time.Since(start))
+	return nil
+}
+
+// RunAsync runs the command in the background; Shutdown waits for it
+func (r *Runner) RunAsync(status analyzer.Status, message, sessionID, cwd string) {
+	r.wg.Add(1)
+	errorhandler.SafeGo(func() {
+		defer r.wg.Done()
+
+		if err := r.Run(status, message, sessionID, cwd); err != nil {
+			errorhandler.HandleError(err, "Async exec failed")
+		}
+	})
+}
+
+// Shutdown waits for running commands to complete. Each command is killed at its own
+// timeout, so this only gives up early if timeout is shorter.
+func (r *Runner) Shutdown(timeout time.Duration) error {
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		logging.Warn("Exec shutdown timeout, abandoned running commands")
+		return fmt.Errorf("shutdown timeout after %v", timeout)
+	}
+}
+
+// environ returns the CLAUDE_NOTIFY_* variables describing a notification
+func environ(n Notification) []string {
+	return []string{
+		"CLAUDE_NOTIFY_STATUS=" + n.Status,
+		"CLAUDE_NOTIFY_TITLE=" + n.Title,
+		"CLAUDE_NOTIFY_MESSAGE=" + n.Message,
+		"CLAUDE_NOTIFY_SESSION_ID=" + n.SessionID,
+		"CLAUDE_NOTIFY_SESSION_NAME=" + n.SessionName,
+		"CLAUDE_NOTIFY_BRANCH=" + n.Branch,
+		"CLAUDE_NOTIFY_CWD=" + n.CWD,
+		"CLAUDE_NOTIFY_PROJECT=" + n.Project,
+	}
+}
+
+// project returns the project name of a working directory
+func project(cwd string) string {
+	if cwd == "" {
+		return ""
+	}
+	return filepath.Base(cwd)
+}
+
+// matchesStatus reports whether status passes a statuses filter (empty = all)
+func matchesStatus(statuses []string, status analyzer.Status) bool {
+	if len(statuses) == 0 {
+		return true
+	}
+	for _, s := range statuses {
+		if s == string(status) {
+			return true
+		}
+	}
+	return false
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the rest
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buf.Len(); remaining > 0 {
+		if len(p) > remaining {
+			b.buf.Write(p[:remaining])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
@@ -0,0 +1,134 @@
+package command
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+func newTestRunner(t *testing.T, command []string, configure func(e *config.ExecConfig)) *Runner {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("exec tests use sh")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Notifications.Exec = config.ExecConfig{
+		Enabled: true,
+		Command: command,
+		Timeout: "5s",
+	}
+	if configure != nil {
+		configure(&cfg.Notifications.Exec)
+	}
+	return New(cfg)
+}
+
+func TestRunPassesEnvironmentAndStdin(t *testing.T) {
+	out := t.TempDir()
+	cwd := t.TempDir()
+	script := `printf '%s\n%s\n%s\n%s\n%s\n%s\n' "$CLAUDE_NOTIFY_STATUS" "$CLAUDE_NOTIFY_TITLE" "$CLAUDE_NOTIFY_MESSAGE" ` +
+		`"$CLAUDE_NOTIFY_SESSION_ID" "$CLAUDE_NOTIFY_PROJECT" "$PWD" > "$1/env"; cat > "$1/stdin"`
+	runner := newTestRunner(t, []string{"sh", "-c", script, "sh", out}, nil)
+
+	if err := runner.Run(analyzer.StatusQuestion, "Which database?", "session-123", cwd); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	env, err := os.ReadFile(filepath.Join(out, "env"))
+	if err != nil {
+		t.Fatalf("Command did not run: %v", err)
+	}
+	resolvedCwd, _ := filepath.EvalSymlinks(cwd)
+	want := strings.Join([]string{"question", "❓ Question", "Which database?", "session-123", filepath.Base(cwd), resolvedCwd}, "\n") + "\n"
+	if string(env) != want {
+		t.Errorf("Unexpected environment:\ngot:  %q\nwant: %q", env, want)
+	}
+
+	stdin, err := os.ReadFile(filepath.Join(out, "stdin"))
+	if err != nil {
+		t.Fatalf("Failed to read stdin copy: %v", err)
+	}
+	var n Notification
+	if err := json.Unmarshal(stdin, &n); err != nil {
+		t.Fatalf("Invalid JSON on stdin: %v", err)
+	}
+	if n.Status != "question" || n.Title != "❓ Question" || n.Message != "Which database?" || n.CWD != cwd {
+		t.Errorf("Unexpected notification: %+v", n)
+	}
+	if n.SessionID != "session-123" || n.SessionName == "" {
+		t.Errorf("Expected session ID and name, got %+v", n)
+	}
+}
+
+func TestRunReportsFailureWithStderr(t *testing.T) {
+	runner := newTestRunner(t, []string{"sh", "-c", "echo 'device not found' >&2; exit 3"}, nil)
+
+	err := runner.Run(analyzer.StatusTaskComplete, "Done", "session-123", "")
+	if err == nil {
+		t.Fatal("Expected error for non-zero exit")
+	}
+	if !strings.Contains(err.Error(), "exit status 3") || !strings.Contains(err.Error(), "device not found") {
+		t.Errorf("Expected exit status and stderr in error, got %v", err)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	runner := newTestRunner(t, []string{"sleep", "5"}, func(e *config.ExecConfig) { e.Timeout = "100ms" })
+
+	start := time.Now()
+	err := runner.Run(analyzer.StatusTaskComplete, "Done", "session-123", "")
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("Expected timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected the command to be killed at the timeout, took %v", elapsed)
+	}
+}
+
+func TestRunMissingCommand(t *testing.T) {
+	runner := newTestRunner(t, []string{"claude-notifications-no-such-command"}, nil)
+
+	if err := runner.Run(analyzer.StatusTaskComplete, "Done", "session-123", ""); err == nil {
+		t.Error("Expected error for a missing command")
+	}
+}
+
+func TestRunStatusFilter(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ran")
+	runner := newTestRunner(t, []string{"touch", marker}, func(e *config.ExecConfig) { e.Statuses = []string{"question"} })
+
+	if err := runner.Run(analyzer.StatusTaskComplete, "Done", "session-123", ""); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("Expected task_complete to be filtered out")
+	}
+
+	runner.RunAsync(analyzer.StatusQuestion, "Which database?", "session-123", "")
+	if err := runner.Shutdown(5 * time.Second); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Error("Expected question to run the command")
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	b := &limitedBuffer{limit: 5}
+	for _, s := range []string{"abc", "defgh", "ijk"} {
+		if n, err := b.Write([]byte(s)); n != len(s) || err != nil {
+			t.Errorf("Write(%q) = %d, %v; want %d, nil", s, n, err, len(s))
+		}
+	}
+	if b.String() != "abcde" {
+		t.Errorf("Expected first 5 bytes, got %q", b.String())
+	}
+}
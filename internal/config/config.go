@@ -3,51 +3,114 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/mail"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/777genius/claude-notifications/internal/platform"
 )
 
+// DefaultWebhookName is the target name of the main "webhook" setting
+const DefaultWebhookName = "default"
+
+// PushoverURL is the Pushover message API, used when a pushover target has no URL
+const PushoverURL = "https://api.pushover.net/1/messages.json"
+
+// webhookPresets lists the supported webhook presets
+var webhookPresets = []string{"slack", "discord", "telegram", "lark", "ntfy", "gotify", "pushover", "teams", "mattermost", "rocketchat", "googlechat", "matrix", "dingtalk", "wecom", "custom"}
+
+// isWebhookPreset reports whether preset is supported
+func isWebhookPreset(preset string) bool {
+	for _, p := range webhookPresets {
+		if p == preset {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultSignatureHeader is the header that carries the HMAC signature of signed webhooks
+const DefaultSignatureHeader = "X-Claude-Notifications-Signature"
+
 // Config represents the plugin configuration
 type Config struct {
 	Notifications NotificationsConfig   `json:"notifications"`
 	Statuses      map[string]StatusInfo `json:"statuses"`
+
+	layers  []Layer           // Sources merged by LoadLayered
+	origins map[string]Origin // Layer that supplied each value, keyed by JSON path
 }
 
 // NotificationsConfig represents notification settings
 type NotificationsConfig struct {
-	Desktop                                     DesktopConfig `json:"desktop"`
-	Webhook                                     WebhookConfig `json:"webhook"`
-	SuppressQuestionAfterTaskCompleteSeconds    int           `json:"suppressQuestionAfterTaskCompleteSeconds"`
-	SuppressQuestionAfterAnyNotificationSeconds int           `json:"suppressQuestionAfterAnyNotificationSeconds"`
-	NotifyOnSubagentStop                        bool          `json:"notifyOnSubagentStop"` // Send notifications when subagents (Task tool) complete, default: false
-	NotifyOnTextResponse                        *bool         `json:"notifyOnTextResponse"` // Send notifications for text-only responses (no tools), default: true
+	Desktop                                     DesktopConfig   `json:"desktop"`
+	Webhook                                     WebhookConfig   `json:"webhook"`
+	Webhooks                                    []WebhookConfig `json:"webhooks"` // Additional named webhook targets
+	Email                                       EmailConfig     `json:"email"`
+	MQTT                                        MQTTConfig      `json:"mqtt"`
+	Exec                                        ExecConfig      `json:"exec"`
+	Tmux                                        TmuxConfig      `json:"tmux"`
+	Schedule                                    ScheduleConfig  `json:"schedule"`
+	SuppressQuestionAfterTaskCompleteSeconds    int             `json:"suppressQuestionAfterTaskCompleteSeconds"`
+	SuppressQuestionAfterAnyNotificationSeconds int             `json:"suppressQuestionAfterAnyNotificationSeconds"`
+	NotifyOnSubagentStop                        bool            `json:"notifyOnSubagentStop"`        // Send notifications when subagents (Task tool) complete, default: false
+	NotifyOnTextResponse                        *bool           `json:"notifyOnTextResponse"`        // Send notifications for text-only responses (no tools), default: true
+	MatrixHomeservers                           []string        `json:"matrixHomeservers,omitempty"` // Hosts matrix targets may post to (empty = any)
 }
 
 // DesktopConfig represents desktop notification settings
 type DesktopConfig struct {
-	Enabled          bool    `json:"enabled"`
-	Method           string  `json:"method"`           // Notification method: "auto", "osc9", "terminal-notifier", "beeep" (default: "auto")
-	Sound            bool    `json:"sound"`
-	Volume           float64 `json:"volume"`           // Volume level 0.0-1.0, default 1.0 (full volume)
-	AudioDevice      string  `json:"audioDevice"`      // Audio output device name (empty = system default)
-	AppIcon          string  `json:"appIcon"`          // Path to app icon
-	ClickToFocus     bool    `json:"clickToFocus"`     // macOS: activate terminal on notification click (default: true)
-	TerminalBundleID string  `json:"terminalBundleId"` // macOS: override auto-detected terminal bundle ID (empty = auto)
+	Enabled             bool    `json:"enabled"`
+	Method              string  `json:"method"` // Notification method: "auto", "osc", "osc9", "osc99", "osc777", "terminal-notifier", "dbus", "beeep" (default: "auto")
+	Sound               bool    `json:"sound"`
+	Volume              float64 `json:"volume"`              // Volume level 0.0-1.0, default 1.0 (full volume)
+	AudioDevice         string  `json:"audioDevice"`         // Audio output device name (empty = system default)
+	AppIcon             string  `json:"appIcon"`             // Path to app icon
+	ClickToFocus        bool    `json:"clickToFocus"`        // macOS: activate terminal on notification click; Linux: "Focus terminal" action (default: true)
+	TerminalBundleID    string  `json:"terminalBundleId"`    // macOS: override auto-detected terminal bundle ID (empty = auto)
+	Expire              string  `json:"expire,omitempty"`    // Linux (dbus): how long notifications stay, e.g. "10s"; "0s" = until dismissed (empty = server default)
+	SuppressWhenFocused bool    `json:"suppressWhenFocused"` // Skip the notification when the session's terminal (and tmux pane) is focused, default: false
 }
 
-// WebhookConfig represents webhook settings
+// WebhookConfig represents webhook settings for a single target
 type WebhookConfig struct {
+	Name           string               `json:"name,omitempty"` // Target name for logs and metrics (default: "default" for the main webhook)
 	Enabled        bool                 `json:"enabled"`
 	Preset         string               `json:"preset"`
 	URL            string               `json:"url"`
 	ChatID         string               `json:"chat_id"`
 	Format         string               `json:"format"`
+	Template       string               `json:"template,omitempty"`     // custom preset: Go text/template for the request body
+	TemplateFile   string               `json:"templateFile,omitempty"` // custom preset: file containing the template (absolute or ~/ path)
 	Headers        map[string]string    `json:"headers"`
 	Retry          RetryConfig          `json:"retry"`
 	CircuitBreaker CircuitBreakerConfig `json:"circuitBreaker"`
 	RateLimit      RateLimitConfig      `json:"rateLimit"`
+	Signing        SigningConfig        `json:"signing"`
+	Ntfy           NtfyConfig           `json:"ntfy"`
+	Gotify         GotifyConfig         `json:"gotify"`
+	Pushover       PushoverConfig       `json:"pushover"`
+	Mattermost     MattermostConfig     `json:"mattermost"`
+	RocketChat     RocketChatConfig     `json:"rocketchat"`
+	Matrix         MatrixConfig         `json:"matrix"`
+	DingTalk       DingTalkConfig       `json:"dingtalk"`
+	Statuses       []string             `json:"statuses,omitempty"` // Only send these statuses (empty = all)
+	Projects       []string             `json:"projects,omitempty"` // Only send for these project directories or glob patterns (empty = all)
+}
+
+// UnmarshalJSON fills fields omitted from a new webhook target with the defaults,
+// so entries in the webhooks list only need the settings they change
+func (w *WebhookConfig) UnmarshalJSON(data []byte) error {
+	type plain WebhookConfig
+	if reflect.ValueOf(*w).IsZero() {
+		*w = DefaultWebhookConfig()
+		w.Enabled = true // Listing a target enables it unless it says otherwise
+	}
+	return json.Unmarshal(data, (*plain)(w))
 }
 
 // RetryConfig represents retry settings
@@ -72,6 +135,136 @@ type RateLimitConfig struct {
 	RequestsPerMinute int  `json:"requestsPerMinute"`
 }
 
+// SigningConfig represents HMAC request signing settings.
+// The secret is never stored in the config itself.
+type SigningConfig struct {
+	Enabled    bool   `json:"enabled"`
+	SecretEnv  string `json:"secretEnv,omitempty"`  // environment variable holding the secret
+	SecretFile string `json:"secretFile,omitempty"` // file holding the secret (absolute or ~/ path)
+	Algorithm  string `json:"algorithm"`            // "sha256" or "sha512"
+	Header     string `json:"header"`               // signature header name
+}
+
+// NtfyConfig represents settings of the ntfy preset.
+// Token and Password may reference environment variables, e.g. "${NTFY_TOKEN}".
+type NtfyConfig struct {
+	Click    string `json:"click,omitempty"`    // URL opened when the notification is tapped
+	Actions  string `json:"actions,omitempty"`  // Action buttons in ntfy's header format
+	Token    string `json:"token,omitempty"`    // Access token (Bearer auth)
+	Username string `json:"username,omitempty"` // Basic auth user
+	Password string `json:"password,omitempty"` // Basic auth password
+}
+
+// GotifyConfig represents settings of the gotify preset
+type GotifyConfig struct {
+	Token string `json:"token,omitempty"` // Application token, sent as X-Gotify-Key
+}
+
+// PushoverConfig represents settings of the pushover preset.
+// Token and User may reference environment variables, e.g. "${PUSHOVER_TOKEN}".
+type PushoverConfig struct {
+	Token  string `json:"token,omitempty"`  // Application API token
+	User   string `json:"user,omitempty"`   // User or group key
+	Device string `json:"device,omitempty"` // Only notify this device (empty = all devices)
+	Sound  string `json:"sound,omitempty"`  // Notification sound (empty = user default)
+	Retry  int    `json:"retry"`            // Emergency priority: seconds between repeats (min 30)
+	Expire int    `json:"expire"`           // Emergency priority: seconds until repeats stop (max 10800)
+}
+
+// MattermostConfig represents settings of the mattermost preset. Overrides only
+// take effect if the server allows integrations to override them.
+type MattermostConfig struct {
+	Channel  string `json:"channel,omitempty"`  // Channel name (e.g. "town-square") or "@username"
+	Username string `json:"username,omitempty"` // Display name of the post (default: "Claude Code")
+	IconURL  string `json:"iconUrl,omitempty"`  // Profile picture of the post
+}
+
+// RocketChatConfig represents settings of the rocketchat preset
+type RocketChatConfig struct {
+	Channel string `json:"channel,omitempty"` // "#channel" or "@username" (empty = integration default)
+	Alias   string `json:"alias,omitempty"`   // Display name of the message (default: "Claude Code")
+	Emoji   string `json:"emoji,omitempty"`   // Avatar emoji, e.g. ":robot:"
+}
+
+// MatrixConfig represents settings of the matrix preset; the target URL is the homeserver.
+// AccessToken may reference an environment variable, e.g. "${MATRIX_TOKEN}".
+type MatrixConfig struct {
+	RoomID      string `json:"roomId,omitempty"`      // Room ID, e.g. "!abc123:example.com" (not an alias)
+	AccessToken string `json:"accessToken,omitempty"` // Access token of the posting user, sent as a Bearer token
+}
+
+// DingTalkConfig represents settings of the dingtalk preset.
+// Secret may reference an environment variable, e.g. "${DINGTALK_SECRET}".
+type DingTalkConfig struct {
+	Secret  string `json:"secret,omitempty"`  // Signing secret ("SEC..."), when the robot uses signature security
+	Keyword string `json:"keyword,omitempty"` // Prefix added to every message, when the robot uses keyword security
+}
+
+// EmailConfig represents SMTP email notification settings
+type EmailConfig struct {
+	Enabled        bool                 `json:"enabled"`
+	Host           string               `json:"host"`
+	Port           int                  `json:"port"`                  // default: 587 for starttls, 465 for tls, 25 for none
+	TLS            string               `json:"tls"`                   // "starttls" (default), "tls" (implicit TLS) or "none"
+	Username       string               `json:"username,omitempty"`    // SMTP auth user (empty = no auth); may reference an environment variable
+	PasswordEnv    string               `json:"passwordEnv,omitempty"` // environment variable holding the SMTP password
+	From           string               `json:"from"`
+	To             []string             `json:"to"`
+	Statuses       []string             `json:"statuses,omitempty"` // Only send these statuses (empty = all)
+	Retry          RetryConfig          `json:"retry"`
+	CircuitBreaker CircuitBreakerConfig `json:"circuitBreaker"`
+}
+
+// MQTTConfig represents MQTT status event publishing settings.
+// Username and Password may reference environment variables, e.g. "${MQTT_PASSWORD}".
+type MQTTConfig struct {
+	Enabled     bool   `json:"enabled"`
+	Broker      string `json:"broker"`             // tcp://host:1883, or ssl://host:8883 for TLS
+	TopicPrefix string `json:"topicPrefix"`        // Topics are <topicPrefix>/<project>/<session>/status, default: "claude"
+	QoS         int    `json:"qos"`                // 0, 1 or 2, default: 1
+	Retain      bool   `json:"retain"`             // Keep the latest status on the broker until the session ends, default: true
+	Username    string `json:"username,omitempty"` // Broker user (empty = anonymous)
+	Password    string `json:"password,omitempty"`
+	CAFile      string `json:"caFile,omitempty"` // PEM CA certificates for a TLS broker (empty = system roots)
+}
+
+// ExecConfig represents the exec channel: a user command run for each notification.
+// The command gets the notification as CLAUDE_NOTIFY_* environment variables and as JSON on stdin.
+// Environment variables are expanded in the program path, but not in its arguments.
+type ExecConfig struct {
+	Enabled  bool     `json:"enabled"`
+	Command  []string `json:"command"`            // Program and arguments, run without a shell, e.g. ["espeak", "Claude needs you"]
+	Timeout  string   `json:"timeout"`            // Kill the command after this long, default: "10s"
+	Statuses []string `json:"statuses,omitempty"` // Only run for these statuses (empty = all)
+}
+
+// TmuxConfig represents marking the tmux window of a session that needs attention.
+// The mark is cleared when the user submits the next prompt.
+type TmuxConfig struct {
+	Enabled  bool     `json:"enabled"`
+	Statuses []string `json:"statuses,omitempty"` // Mark the window for these statuses (empty = all), default: question, plan_ready
+	Rename   bool     `json:"rename"`             // Prefix the window name with the status emoji, default: true
+	Bell     bool     `json:"bell"`               // Ring the bell in the session's pane, default: false
+}
+
+// ScheduleConfig represents quiet hours: notifications are delivered as usual inside
+// the windows and quieted for the rest of the week
+type ScheduleConfig struct {
+	Enabled      bool             `json:"enabled"`
+	Timezone     string           `json:"timezone,omitempty"`     // IANA time zone, e.g. "Europe/Berlin" (empty = local time)
+	Windows      []ScheduleWindow `json:"windows"`                // Active hours
+	Desktop      string           `json:"desktop"`                // Quiet hours: "mute" (no sound) or "suppress" (no notification), default: "mute"
+	Webhook      string           `json:"webhook"`                // Quiet hours: "deliver" or "defer" (kept in the outbox until active hours), default: "deliver"
+	BreakThrough []string         `json:"breakThrough,omitempty"` // Statuses that ignore quiet hours, default: api_error, session_limit_reached
+}
+
+// ScheduleWindow represents a time-of-day window on some weekdays
+type ScheduleWindow struct {
+	Days  []string `json:"days,omitempty"` // "mon" ... "sun" (empty = every day)
+	Start string   `json:"start"`          // e.g. "09:00"
+	End   string   `json:"end"`            // e.g. "18:00"; before start = ends the next day, "24:00" = midnight
+}
+
 // StatusInfo represents configuration for a specific status
 type StatusInfo struct {
 	Title string `json:"title"`
@@ -96,31 +289,13 @@ func DefaultConfig() *Config {
 				ClickToFocus: true, // macOS: activate terminal on click (default: enabled)
 				// TerminalBundleID: "" - empty means auto-detect
 			},
-			Webhook: WebhookConfig{
-				Enabled: false,
-				Preset:  "custom",
-				URL:     "",
-				ChatID:  "",
-				Format:  "json",
-				Headers: make(map[string]string),
-				Retry: RetryConfig{
-					Enabled:        true,
-					MaxAttempts:    3,
-					InitialBackoff: "1s",
-					MaxBackoff:     "10s",
-				},
-				CircuitBreaker: CircuitBreakerConfig{
-					Enabled:          true,
-					FailureThreshold: 5,
-					Timeout:          "30s",
-					SuccessThreshold: 2,
-				},
-				RateLimit: RateLimitConfig{
-					Enabled:           true,
-					RequestsPerMinute: 10,
-				},
-			},
-			SuppressQuestionAfterTaskCompleteSeconds:    12,
+			Webhook:                                  DefaultWebhookConfig(),
+			Email:                                    DefaultEmailConfig(),
+			MQTT:                                     DefaultMQTTConfig(),
+			Exec:                                     ExecConfig{Timeout: "10s"},
+			Tmux:                                     DefaultTmuxConfig(),
+			Schedule:                                 DefaultScheduleConfig(),
+			SuppressQuestionAfterTaskCompleteSeconds: 12,
 			SuppressQuestionAfterAnyNotificationSeconds: 12,
 		},
 		Statuses: map[string]StatusInfo{
@@ -152,6 +327,115 @@ func DefaultConfig() *Config {
 	}
 }
 
+// DefaultWebhookConfig returns the defaults for a webhook target (disabled)
+func DefaultWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		Enabled: false,
+		Preset:  "custom",
+		URL:     "",
+		ChatID:  "",
+		Format:  "json",
+		Headers: make(map[string]string),
+		Retry: RetryConfig{
+			Enabled:        true,
+			MaxAttempts:    3,
+			InitialBackoff: "1s",
+			MaxBackoff:     "10s",
+		},
+		CircuitBreaker: CircuitBreakerConfig{
+			Enabled:          true,
+			FailureThreshold: 5,
+			Timeout:          "30s",
+			SuccessThreshold: 2,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:           true,
+			RequestsPerMinute: 10,
+		},
+		Signing: SigningConfig{
+			Enabled:   false,
+			Algorithm: "sha256",
+			Header:    DefaultSignatureHeader,
+		},
+		Pushover: PushoverConfig{
+			Retry:  60,
+			Expire: 3600,
+		},
+	}
+}
+
+// DefaultEmailConfig returns the defaults for email notifications (disabled)
+func DefaultEmailConfig() EmailConfig {
+	return EmailConfig{
+		Enabled: false,
+		TLS:     "starttls",
+		Retry: RetryConfig{
+			Enabled:        true,
+			MaxAttempts:    3,
+			InitialBackoff: "2s",
+			MaxBackoff:     "10s",
+		},
+		CircuitBreaker: CircuitBreakerConfig{
+			Enabled:          true,
+			FailureThreshold: 5,
+			Timeout:          "60s",
+			SuccessThreshold: 1,
+		},
+	}
+}
+
+// DefaultTmuxConfig returns the defaults for tmux window marks (disabled)
+func DefaultTmuxConfig() TmuxConfig {
+	return TmuxConfig{
+		Statuses: []string{"question", "plan_ready"},
+		Rename:   true,
+	}
+}
+
+// DefaultScheduleConfig returns the defaults for quiet hours (disabled)
+func DefaultScheduleConfig() ScheduleConfig {
+	return ScheduleConfig{
+		Desktop:      "mute",
+		Webhook:      "deliver",
+		BreakThrough: []string{"api_error", "session_limit_reached"},
+	}
+}
+
+// ParseTimeOfDay parses a schedule time ("HH:MM", "00:00" to "24:00") into the
+// time since midnight
+func ParseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		if s == "24:00" {
+			return 24 * time.Hour, nil
+		}
+		return 0, fmt.Errorf("invalid time of day: %q (must be HH:MM)", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// ParseWeekday parses a schedule day: "mon" or "monday", in any case
+func ParseWeekday(s string) (time.Weekday, error) {
+	name := strings.ToLower(s)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if name == full || name == full[:3] {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday: %q", s)
+}
+
+// DefaultMQTTConfig returns the defaults for MQTT publishing (disabled)
+func DefaultMQTTConfig() MQTTConfig {
+	return MQTTConfig{
+		Enabled:     false,
+		TopicPrefix: "claude",
+		QoS:         1,
+		Retain:      true,
+	}
+}
+
 // Load loads configuration from a file
 // If the file doesn't exist, returns default config
 func Load(path string) (*Config, error) {
@@ -171,14 +455,7 @@ func Load(path string) (*Config, error) {
 	}
 
 	// Expand environment variables in paths
-	config.Notifications.Desktop.AppIcon = platform.ExpandEnv(config.Notifications.Desktop.AppIcon)
-	config.Notifications.Webhook.URL = platform.ExpandEnv(config.Notifications.Webhook.URL)
-
-	// Expand environment variables in sound paths
-	for status, info := range config.Statuses {
-		info.Sound = platform.ExpandEnv(info.Sound)
-		config.Statuses[status] = info
-	}
+	config.expandEnv()
 
 	// Apply defaults for missing fields
 	config.ApplyDefaults()
@@ -186,10 +463,10 @@ func Load(path string) (*Config, error) {
 	return config, nil
 }
 
-// LoadFromPluginRoot loads configuration from plugin root directory
+// LoadFromPluginRoot loads configuration from plugin root directory,
+// merged with the user config and environment overrides (see LoadLayered)
 func LoadFromPluginRoot(pluginRoot string) (*Config, error) {
-	configPath := filepath.Join(pluginRoot, "config", "config.json")
-	return Load(configPath)
+	return LoadLayered(LoadOptions{PluginRoot: pluginRoot})
 }
 
 // ApplyDefaults fills in missing fields with default values
@@ -201,14 +478,45 @@ func (c *Config) ApplyDefaults() {
 	// AppIcon: Keep empty if not set (no default)
 
 	// Webhook defaults
-	if c.Notifications.Webhook.Preset == "" {
-		c.Notifications.Webhook.Preset = "custom"
+	c.Notifications.Webhook.applyDefaults()
+	for i := range c.Notifications.Webhooks {
+		c.Notifications.Webhooks[i].applyDefaults()
+		if c.Notifications.Webhooks[i].Name == "" {
+			c.Notifications.Webhooks[i].Name = fmt.Sprintf("webhook-%d", i+1)
+		}
 	}
-	if c.Notifications.Webhook.Format == "" {
-		c.Notifications.Webhook.Format = "json"
+
+	// Email defaults
+	if c.Notifications.Email.TLS == "" {
+		c.Notifications.Email.TLS = "starttls"
+	}
+	if c.Notifications.Email.Port == 0 {
+		switch c.Notifications.Email.TLS {
+		case "tls":
+			c.Notifications.Email.Port = 465
+		case "none":
+			c.Notifications.Email.Port = 25
+		default:
+			c.Notifications.Email.Port = 587
+		}
+	}
+
+	// MQTT defaults
+	if c.Notifications.MQTT.TopicPrefix == "" {
+		c.Notifications.MQTT.TopicPrefix = "claude"
 	}
-	if c.Notifications.Webhook.Headers == nil {
-		c.Notifications.Webhook.Headers = make(map[string]string)
+
+	// Exec defaults
+	if c.Notifications.Exec.Timeout == "" {
+		c.Notifications.Exec.Timeout = "10s"
+	}
+
+	// Schedule defaults
+	if c.Notifications.Schedule.Desktop == "" {
+		c.Notifications.Schedule.Desktop = "mute"
+	}
+	if c.Notifications.Schedule.Webhook == "" {
+		c.Notifications.Schedule.Webhook = "deliver"
 	}
 
 	// Cooldown defaults
@@ -233,18 +541,55 @@ func (c *Config) ApplyDefaults() {
 	}
 }
 
+// applyDefaults fills in missing webhook target fields
+func (w *WebhookConfig) applyDefaults() {
+	if w.Preset == "" {
+		w.Preset = "custom"
+	}
+	if w.Format == "" {
+		w.Format = "json"
+	}
+	if w.Headers == nil {
+		w.Headers = make(map[string]string)
+	}
+	if w.Signing.Algorithm == "" {
+		w.Signing.Algorithm = "sha256"
+	}
+	if w.Signing.Header == "" {
+		w.Signing.Header = DefaultSignatureHeader
+	}
+	if w.Preset == "pushover" && w.URL == "" {
+		w.URL = PushoverURL
+	}
+	if w.Pushover.Retry == 0 {
+		w.Pushover.Retry = 60
+	}
+	if w.Pushover.Expire == 0 {
+		w.Pushover.Expire = 3600
+	}
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
 	// Validate notification method
 	validMethods := map[string]bool{
-		"":                   true, // empty means auto
-		"auto":               true,
-		"osc9":               true,
-		"terminal-notifier":  true,
-		"beeep":              true,
+		"":                  true, // empty means auto
+		"auto":              true,
+		"osc":               true, // picks osc9, osc99 or osc777 from TERM/TERM_PROGRAM
+		"osc9":              true,
+		"osc99":             true,
+		"osc777":            true,
+		"terminal-notifier": true,
+		"dbus":              true,
+		"beeep":             true,
 	}
 	if !validMethods[c.Notifications.Desktop.Method] {
-		return fmt.Errorf("invalid notification method: %s (must be one of: auto, osc9, terminal-notifier, beeep)", c.Notifications.Desktop.Method)
+		return fmt.Errorf("invalid notification method: %s (must be one of: auto, osc, osc9, osc99, osc777, terminal-notifier, dbus, beeep)", c.Notifications.Desktop.Method)
+	}
+	if expire := c.Notifications.Desktop.Expire; expire != "" {
+		if d, err := time.ParseDuration(expire); err != nil || d < 0 {
+			return fmt.Errorf("invalid desktop expire: %s (must be a duration, e.g. \"10s\")", expire)
+		}
 	}
 
 	// Validate volume
@@ -252,45 +597,390 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("desktop volume must be between 0.0 and 1.0 (got %.2f)", c.Notifications.Desktop.Volume)
 	}
 
-	// Validate webhook preset (only if webhooks are enabled)
-	validPresets := map[string]bool{
-		"slack":    true,
-		"discord":  true,
-		"telegram": true,
-		"lark":     true,
-		"custom":   true,
+	// Validate webhook targets (only enabled ones)
+	names := make(map[string]bool)
+	for _, target := range c.WebhookTargets() {
+		if names[target.Name] {
+			return fmt.Errorf("duplicate webhook name: %s", target.Name)
+		}
+		names[target.Name] = true
+
+		if err := c.validateWebhook(target); err != nil {
+			if target.Name == DefaultWebhookName {
+				return err
+			}
+			return fmt.Errorf("webhook %s: %w", target.Name, err)
+		}
+	}
+
+	// Validate email
+	if c.Notifications.Email.Enabled {
+		if err := c.validateEmail(c.Notifications.Email); err != nil {
+			return fmt.Errorf("email: %w", err)
+		}
 	}
-	if c.Notifications.Webhook.Enabled && !validPresets[c.Notifications.Webhook.Preset] {
-		return fmt.Errorf("invalid webhook preset: %s (must be one of: slack, discord, telegram, lark, custom)", c.Notifications.Webhook.Preset)
+
+	// Validate MQTT
+	if c.Notifications.MQTT.Enabled {
+		if err := validateMQTT(c.Notifications.MQTT); err != nil {
+			return fmt.Errorf("mqtt: %w", err)
+		}
+	}
+
+	// Validate exec
+	if c.Notifications.Exec.Enabled {
+		if err := c.validateExec(c.Notifications.Exec); err != nil {
+			return fmt.Errorf("exec: %w", err)
+		}
+	}
+
+	// Validate tmux
+	if c.Notifications.Tmux.Enabled {
+		for _, status := range c.Notifications.Tmux.Statuses {
+			if _, ok := c.Statuses[status]; !ok {
+				return fmt.Errorf("unknown status in tmux statuses: %s", status)
+			}
+		}
+	}
+
+	// Validate schedule
+	if c.Notifications.Schedule.Enabled {
+		if err := c.validateSchedule(c.Notifications.Schedule); err != nil {
+			return fmt.Errorf("schedule: %w", err)
+		}
+	}
+
+	// Validate cooldown
+	if c.Notifications.SuppressQuestionAfterTaskCompleteSeconds < 0 {
+		return fmt.Errorf("suppressQuestionAfterTaskCompleteSeconds must be >= 0")
+	}
+
+	return nil
+}
+
+// validateWebhook validates a single enabled webhook target
+func (c *Config) validateWebhook(w WebhookConfig) error {
+	if !isWebhookPreset(w.Preset) {
+		return fmt.Errorf("invalid webhook preset: %s (must be one of: %s)", w.Preset, strings.Join(webhookPresets, ", "))
 	}
 
-	// Validate webhook format (only if webhooks are enabled)
 	validFormats := map[string]bool{
 		"json": true,
 		"text": true,
 	}
-	if c.Notifications.Webhook.Enabled && !validFormats[c.Notifications.Webhook.Format] {
-		return fmt.Errorf("invalid webhook format: %s (must be one of: json, text)", c.Notifications.Webhook.Format)
+	if !validFormats[w.Format] {
+		return fmt.Errorf("invalid webhook format: %s (must be one of: json, text)", w.Format)
 	}
 
-	// Validate webhook URL if enabled
-	if c.Notifications.Webhook.Enabled && c.Notifications.Webhook.URL == "" {
+	if w.URL == "" {
 		return fmt.Errorf("webhook URL is required when webhooks are enabled")
 	}
 
-	// Validate Telegram chat_id if Telegram preset is used
-	if c.Notifications.Webhook.Enabled && c.Notifications.Webhook.Preset == "telegram" && c.Notifications.Webhook.ChatID == "" {
-		return fmt.Errorf("chat_id is required for Telegram webhook")
+	if err := validatePresetSettings(w); err != nil {
+		return err
 	}
 
-	// Validate cooldown
-	if c.Notifications.SuppressQuestionAfterTaskCompleteSeconds < 0 {
-		return fmt.Errorf("suppressQuestionAfterTaskCompleteSeconds must be >= 0")
+	if w.Preset == "matrix" && len(c.Notifications.MatrixHomeservers) > 0 {
+		if err := validateHomeserver(w.URL, c.Notifications.MatrixHomeservers); err != nil {
+			return err
+		}
+	}
+
+	if w.Template != "" || w.TemplateFile != "" {
+		if w.Preset != "custom" {
+			return fmt.Errorf("template and templateFile are only supported by the custom preset")
+		}
+		if w.Template != "" && w.TemplateFile != "" {
+			return fmt.Errorf("template and templateFile cannot both be set")
+		}
+		if w.TemplateFile != "" && !filepath.IsAbs(w.TemplateFile) && !strings.HasPrefix(w.TemplateFile, "~/") {
+			return fmt.Errorf("templateFile must be an absolute path or start with ~/ (got %s)", w.TemplateFile)
+		}
+	}
+
+	if w.Signing.Enabled {
+		if err := validateSigning(w.Signing); err != nil {
+			return err
+		}
+	}
+
+	for _, status := range w.Statuses {
+		if _, ok := c.Statuses[status]; !ok {
+			return fmt.Errorf("unknown status in webhook statuses: %s", status)
+		}
+	}
+
+	for _, pattern := range w.Projects {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid webhook project pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// validateEmail validates enabled email settings
+func (c *Config) validateEmail(e EmailConfig) error {
+	if e.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if e.Port < 1 || e.Port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535 (got %d)", e.Port)
+	}
+	if e.TLS != "starttls" && e.TLS != "tls" && e.TLS != "none" {
+		return fmt.Errorf("invalid tls mode: %s (must be one of: starttls, tls, none)", e.TLS)
+	}
+	if e.PasswordEnv != "" && e.Username == "" {
+		return fmt.Errorf("passwordEnv requires a username")
+	}
+	if _, err := mail.ParseAddress(e.From); err != nil {
+		return fmt.Errorf("invalid from address %q: %w", e.From, err)
 	}
+	if len(e.To) == 0 {
+		return fmt.Errorf("at least one to address is required")
+	}
+	for _, to := range e.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("invalid to address %q: %w", to, err)
+		}
+	}
+	for _, status := range e.Statuses {
+		if _, ok := c.Statuses[status]; !ok {
+			return fmt.Errorf("unknown status in email statuses: %s", status)
+		}
+	}
+	return nil
+}
 
+// validateMQTT validates enabled MQTT settings
+func validateMQTT(m MQTTConfig) error {
+	if m.Broker == "" {
+		return fmt.Errorf("broker is required")
+	}
+	u, err := url.Parse(m.Broker)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid broker URL: %s", m.Broker)
+	}
+	switch u.Scheme {
+	case "tcp", "mqtt", "ssl", "tls", "mqtts":
+	default:
+		return fmt.Errorf("invalid broker scheme: %s (must be one of: tcp, mqtt, ssl, tls, mqtts)", u.Scheme)
+	}
+	if m.QoS < 0 || m.QoS > 2 {
+		return fmt.Errorf("qos must be 0, 1 or 2 (got %d)", m.QoS)
+	}
+	if strings.ContainsAny(m.TopicPrefix, "+#") {
+		return fmt.Errorf("topicPrefix must not contain wildcards: %s", m.TopicPrefix)
+	}
+	if strings.HasPrefix(m.TopicPrefix, "/") || strings.HasSuffix(m.TopicPrefix, "/") {
+		return fmt.Errorf("topicPrefix must not start or end with /: %s", m.TopicPrefix)
+	}
+	if m.Password != "" && m.Username == "" {
+		return fmt.Errorf("password requires a username")
+	}
 	return nil
 }
 
+// validateExec validates enabled exec channel settings
+func (c *Config) validateExec(e ExecConfig) error {
+	if len(e.Command) == 0 || e.Command[0] == "" {
+		return fmt.Errorf("command is required")
+	}
+	timeout, err := time.ParseDuration(e.Timeout)
+	if err != nil || timeout <= 0 {
+		return fmt.Errorf("invalid timeout: %s (must be a positive duration, e.g. \"10s\")", e.Timeout)
+	}
+	for _, status := range e.Statuses {
+		if _, ok := c.Statuses[status]; !ok {
+			return fmt.Errorf("unknown status in exec statuses: %s", status)
+		}
+	}
+	return nil
+}
+
+// validateSchedule validates enabled quiet hours settings
+func (c *Config) validateSchedule(s ScheduleConfig) error {
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone: %s", s.Timezone)
+	}
+	if len(s.Windows) == 0 {
+		return fmt.Errorf("at least one window is required")
+	}
+	for i, w := range s.Windows {
+		for _, day := range w.Days {
+			if _, err := ParseWeekday(day); err != nil {
+				return fmt.Errorf("window %d: %w", i+1, err)
+			}
+		}
+		start, err := ParseTimeOfDay(w.Start)
+		if err != nil {
+			return fmt.Errorf("window %d: start: %w", i+1, err)
+		}
+		end, err := ParseTimeOfDay(w.End)
+		if err != nil {
+			return fmt.Errorf("window %d: end: %w", i+1, err)
+		}
+		if start == end || start == 24*time.Hour {
+			return fmt.Errorf("window %d: start and end must differ and start must be before 24:00", i+1)
+		}
+	}
+	if s.Desktop != "mute" && s.Desktop != "suppress" {
+		return fmt.Errorf("invalid desktop action: %s (must be mute or suppress)", s.Desktop)
+	}
+	if s.Webhook != "deliver" && s.Webhook != "defer" {
+		return fmt.Errorf("invalid webhook action: %s (must be deliver or defer)", s.Webhook)
+	}
+	for _, status := range s.BreakThrough {
+		if _, ok := c.Statuses[status]; !ok {
+			return fmt.Errorf("unknown status in breakThrough: %s", status)
+		}
+	}
+	return nil
+}
+
+// validatePresetSettings validates the settings specific to a target's preset
+func validatePresetSettings(w WebhookConfig) error {
+	switch w.Preset {
+	case "telegram":
+		// Telegram needs a chat to post to
+		if w.ChatID == "" {
+			return fmt.Errorf("chat_id is required for Telegram webhook")
+		}
+	case "ntfy":
+		// ntfy takes either an access token or a username and password
+		if w.Ntfy.Token != "" && w.Ntfy.Username != "" {
+			return fmt.Errorf("ntfy token and username cannot both be set")
+		}
+		if w.Ntfy.Password != "" && w.Ntfy.Username == "" {
+			return fmt.Errorf("ntfy password requires a username")
+		}
+	case "gotify":
+		if w.Gotify.Token == "" {
+			return fmt.Errorf("gotify token is required")
+		}
+	case "pushover":
+		if w.Pushover.Token == "" || w.Pushover.User == "" {
+			return fmt.Errorf("pushover token and user are required")
+		}
+		// Pushover's limits for emergency priority
+		if w.Pushover.Retry < 30 {
+			return fmt.Errorf("pushover retry must be at least 30 seconds (got %d)", w.Pushover.Retry)
+		}
+		if w.Pushover.Expire < 1 || w.Pushover.Expire > 10800 {
+			return fmt.Errorf("pushover expire must be between 1 and 10800 seconds (got %d)", w.Pushover.Expire)
+		}
+	case "mattermost":
+		// Mattermost takes the channel name, not the display name shown with "#"
+		if strings.HasPrefix(w.Mattermost.Channel, "#") {
+			return fmt.Errorf("mattermost channel must be a channel name without # (got %s)", w.Mattermost.Channel)
+		}
+		if w.Mattermost.IconURL != "" && !isHTTPURL(w.Mattermost.IconURL) {
+			return fmt.Errorf("mattermost iconUrl must be an http(s) URL (got %s)", w.Mattermost.IconURL)
+		}
+	case "rocketchat":
+		if c := w.RocketChat.Channel; c != "" && !strings.HasPrefix(c, "#") && !strings.HasPrefix(c, "@") {
+			return fmt.Errorf("rocketchat channel must start with # or @ (got %s)", c)
+		}
+		if e := w.RocketChat.Emoji; e != "" && (len(e) < 3 || !strings.HasPrefix(e, ":") || !strings.HasSuffix(e, ":")) {
+			return fmt.Errorf("rocketchat emoji must look like :name: (got %s)", e)
+		}
+	case "matrix":
+		if w.Matrix.AccessToken == "" {
+			return fmt.Errorf("matrix accessToken is required")
+		}
+		// Aliases (#room:server) would need a directory lookup first
+		if !strings.HasPrefix(w.Matrix.RoomID, "!") {
+			return fmt.Errorf("matrix roomId must be a room ID starting with ! (got %q)", w.Matrix.RoomID)
+		}
+	case "googlechat":
+		// Google Chat rejects webhook calls without both the key and the token
+		if !hasQueryParams(w.URL, "key", "token") {
+			return fmt.Errorf("googlechat URL must include the key and token query parameters (copy the full webhook URL)")
+		}
+	case "dingtalk":
+		if !hasQueryParams(w.URL, "access_token") {
+			return fmt.Errorf("dingtalk URL must include the access_token query parameter")
+		}
+		if w.DingTalk.Secret != "" && !strings.HasPrefix(w.DingTalk.Secret, "SEC") {
+			return fmt.Errorf("dingtalk secret must be the robot's signing secret starting with SEC")
+		}
+		if strings.ContainsAny(w.DingTalk.Keyword, "\r\n") {
+			return fmt.Errorf("dingtalk keyword must be a single line")
+		}
+	case "wecom":
+		if !hasQueryParams(w.URL, "key") {
+			return fmt.Errorf("wecom URL must include the key query parameter")
+		}
+	}
+	return nil
+}
+
+// validateHomeserver checks that a matrix target posts to one of the allowed homeserver hosts
+func validateHomeserver(rawURL string, allowed []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid matrix homeserver URL: %w", err)
+	}
+	for _, host := range allowed {
+		if strings.EqualFold(u.Host, host) {
+			return nil
+		}
+	}
+	return fmt.Errorf("matrix homeserver %s is not allowed (matrixHomeservers: %s)", u.Host, strings.Join(allowed, ", "))
+}
+
+// hasQueryParams reports whether rawURL has non-empty values for all the query parameters
+func hasQueryParams(rawURL string, names ...string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	for _, name := range names {
+		if u.Query().Get(name) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// isHTTPURL reports whether s is an absolute http or https URL
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// validateSigning validates the signing settings of a webhook target
+func validateSigning(s SigningConfig) error {
+	if (s.SecretEnv == "") == (s.SecretFile == "") {
+		return fmt.Errorf("signing requires exactly one of secretEnv or secretFile")
+	}
+	if s.SecretFile != "" && !filepath.IsAbs(s.SecretFile) && !strings.HasPrefix(s.SecretFile, "~/") {
+		return fmt.Errorf("signing secretFile must be an absolute path or start with ~/ (got %s)", s.SecretFile)
+	}
+	if s.Algorithm != "sha256" && s.Algorithm != "sha512" {
+		return fmt.Errorf("invalid signing algorithm: %s (must be one of: sha256, sha512)", s.Algorithm)
+	}
+	return nil
+}
+
+// WebhookTargets returns the enabled webhook targets: the main webhook followed by the webhooks list
+func (c *Config) WebhookTargets() []WebhookConfig {
+	var targets []WebhookConfig
+	if c.Notifications.Webhook.Enabled {
+		target := c.Notifications.Webhook
+		if target.Name == "" {
+			target.Name = DefaultWebhookName
+		}
+		targets = append(targets, target)
+	}
+	for _, target := range c.Notifications.Webhooks {
+		if target.Enabled {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
 // GetStatusInfo returns status information for a given status
 func (c *Config) GetStatusInfo(status string) (StatusInfo, bool) {
 	info, exists := c.Statuses[status]
@@ -302,14 +992,34 @@ func (c *Config) IsDesktopEnabled() bool {
 	return c.Notifications.Desktop.Enabled
 }
 
-// IsWebhookEnabled returns true if webhook notifications are enabled
+// IsWebhookEnabled returns true if at least one webhook target is enabled
 func (c *Config) IsWebhookEnabled() bool {
-	return c.Notifications.Webhook.Enabled
+	return len(c.WebhookTargets()) > 0
+}
+
+// IsEmailEnabled returns true if email notifications are enabled
+func (c *Config) IsEmailEnabled() bool {
+	return c.Notifications.Email.Enabled
+}
+
+// IsMQTTEnabled returns true if MQTT publishing is enabled
+func (c *Config) IsMQTTEnabled() bool {
+	return c.Notifications.MQTT.Enabled
+}
+
+// IsExecEnabled returns true if the exec channel is enabled
+func (c *Config) IsExecEnabled() bool {
+	return c.Notifications.Exec.Enabled
+}
+
+// IsTmuxEnabled returns true if tmux window marks are enabled
+func (c *Config) IsTmuxEnabled() bool {
+	return c.Notifications.Tmux.Enabled
 }
 
 // IsAnyNotificationEnabled returns true if at least one notification method is enabled
 func (c *Config) IsAnyNotificationEnabled() bool {
-	return c.IsDesktopEnabled() || c.IsWebhookEnabled()
+	return c.IsDesktopEnabled() || c.IsWebhookEnabled() || c.IsEmailEnabled() || c.IsMQTTEnabled() || c.IsExecEnabled() || c.IsTmuxEnabled()
 }
 
 // ShouldNotifyOnTextResponse returns true if notifications should be sent for text-only responses (default: true)
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -508,7 +509,7 @@ func TestValidate_NegativeCooldown(t *testing.T) {
 }
 
 func TestValidate_NotificationMethod(t *testing.T) {
-	validMethods := []string{"", "auto", "osc9", "terminal-notifier", "beeep"}
+	validMethods := []string{"", "auto", "osc", "osc9", "osc99", "osc777", "terminal-notifier", "dbus", "beeep"}
 	for _, method := range validMethods {
 		t.Run("valid_method_"+method, func(t *testing.T) {
 			cfg := DefaultConfig()
@@ -530,6 +531,26 @@ func TestValidate_NotificationMethod(t *testing.T) {
 	}
 }
 
+func TestValidate_DesktopExpire(t *testing.T) {
+	for _, expire := range []string{"", "0s", "10s", "1m30s"} {
+		t.Run("valid_"+expire, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Notifications.Desktop.Expire = expire
+			assert.NoError(t, cfg.Validate())
+		})
+	}
+
+	for _, expire := range []string{"10", "soon", "-5s"} {
+		t.Run("invalid_"+expire, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Notifications.Desktop.Expire = expire
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid desktop expire")
+		})
+	}
+}
+
 // === Tests for Click-to-Focus settings ===
 
 func TestDefaultConfig_ClickToFocus(t *testing.T) {
@@ -653,3 +674,624 @@ func TestApplyDefaults_ClickToFocus(t *testing.T) {
 	// Volume should be preserved
 	assert.Equal(t, 0.5, cfg.Notifications.Desktop.Volume)
 }
+
+func TestLoadConfig_WebhookTargets(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+
+	configJSON := `{
+		"notifications": {
+			"webhook": {
+				"enabled": true,
+				"preset": "slack",
+				"url": "https://hooks.slack.com/test",
+				"statuses": ["task_complete"]
+			},
+			"webhooks": [
+				{
+					"name": "questions",
+					"preset": "telegram",
+					"url": "https://api.telegram.org/bot123/sendMessage",
+					"chat_id": "42",
+					"statuses": ["question"],
+					"retry": {"enabled": false}
+				},
+				{
+					"url": "https://example.com/paused",
+					"enabled": false
+				}
+			]
+		}
+	}`
+	require.NoError(t, os.WriteFile(configPath, []byte(configJSON), 0644))
+
+	cfg, err := Load(configPath)
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate())
+	require.Len(t, cfg.Notifications.Webhooks, 2)
+
+	questions := cfg.Notifications.Webhooks[0]
+	assert.True(t, questions.Enabled, "listed targets are enabled by default")
+	assert.Equal(t, "json", questions.Format)
+	assert.False(t, questions.Retry.Enabled)
+	assert.Equal(t, 3, questions.Retry.MaxAttempts, "omitted retry fields keep their defaults")
+	assert.True(t, questions.CircuitBreaker.Enabled)
+	assert.Equal(t, 10, questions.RateLimit.RequestsPerMinute)
+	assert.Equal(t, "webhook-2", cfg.Notifications.Webhooks[1].Name)
+
+	targets := cfg.WebhookTargets()
+	require.Len(t, targets, 2)
+	assert.Equal(t, DefaultWebhookName, targets[0].Name)
+	assert.Equal(t, []string{"task_complete"}, targets[0].Statuses)
+	assert.Equal(t, "questions", targets[1].Name)
+	assert.True(t, cfg.IsWebhookEnabled())
+}
+
+func TestValidate_WebhookTargets(t *testing.T) {
+	newConfig := func(targets ...WebhookConfig) *Config {
+		cfg := DefaultConfig()
+		cfg.Notifications.Webhooks = targets
+		return cfg
+	}
+	target := func(name string) WebhookConfig {
+		w := DefaultWebhookConfig()
+		w.Enabled = true
+		w.Name = name
+		w.URL = "https://example.com/" + name
+		return w
+	}
+
+	assert.NoError(t, newConfig(target("a"), target("b")).Validate())
+
+	err := newConfig(target("a"), target("a")).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate webhook name: a")
+
+	noURL := target("b")
+	noURL.URL = ""
+	err = newConfig(target("a"), noURL).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "webhook b: webhook URL is required")
+
+	badStatus := target("c")
+	badStatus.Statuses = []string{"finished"}
+	err = newConfig(badStatus).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown status in webhook statuses: finished")
+
+	badPattern := target("d")
+	badPattern.Projects = []string{"[acme"}
+	err = newConfig(badPattern).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook project pattern")
+
+	// Disabled targets are not validated
+	disabled := target("e")
+	disabled.Enabled = false
+	disabled.URL = ""
+	assert.NoError(t, newConfig(disabled).Validate())
+	assert.False(t, newConfig(disabled).IsWebhookEnabled())
+}
+
+func TestValidate_WebhookTemplate(t *testing.T) {
+	newConfig := func(w WebhookConfig) *Config {
+		cfg := DefaultConfig()
+		w.Enabled = true
+		w.URL = "https://example.com/hook"
+		cfg.Notifications.Webhook = w
+		return cfg
+	}
+
+	w := DefaultWebhookConfig()
+	w.Template = `{"text": {{json .Message}}}`
+	assert.NoError(t, newConfig(w).Validate())
+
+	w = DefaultWebhookConfig()
+	w.TemplateFile = "~/templates/hook.tmpl"
+	assert.NoError(t, newConfig(w).Validate())
+
+	w = DefaultWebhookConfig()
+	w.Preset = "slack"
+	w.Template = `{"text": {{json .Message}}}`
+	err := newConfig(w).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only supported by the custom preset")
+
+	w = DefaultWebhookConfig()
+	w.Template = "{}"
+	w.TemplateFile = "/etc/hook.tmpl"
+	err = newConfig(w).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot both be set")
+
+	w = DefaultWebhookConfig()
+	w.TemplateFile = "templates/hook.tmpl"
+	err = newConfig(w).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "templateFile must be an absolute path")
+}
+
+func TestValidate_WebhookSigning(t *testing.T) {
+	newConfig := func(s SigningConfig) *Config {
+		cfg := DefaultConfig()
+		cfg.Notifications.Webhook.Enabled = true
+		cfg.Notifications.Webhook.URL = "https://example.com/hook"
+		cfg.Notifications.Webhook.Signing = s
+		return cfg
+	}
+	signing := func() SigningConfig {
+		s := DefaultWebhookConfig().Signing
+		s.Enabled = true
+		return s
+	}
+
+	assert.Equal(t, "sha256", DefaultWebhookConfig().Signing.Algorithm)
+	assert.Equal(t, DefaultSignatureHeader, DefaultWebhookConfig().Signing.Header)
+
+	s := signing()
+	s.SecretEnv = "WEBHOOK_SECRET"
+	assert.NoError(t, newConfig(s).Validate())
+
+	s = signing()
+	err := newConfig(s).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exactly one of secretEnv or secretFile")
+
+	s = signing()
+	s.SecretFile = "secret.txt"
+	err = newConfig(s).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "secretFile must be an absolute path")
+
+	s = signing()
+	s.SecretEnv = "WEBHOOK_SECRET"
+	s.Algorithm = "md5"
+	err = newConfig(s).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid signing algorithm: md5")
+
+	// Signing settings are ignored while disabled
+	assert.NoError(t, newConfig(SigningConfig{Algorithm: "md5"}).Validate())
+}
+
+func TestValidate_NtfyPreset(t *testing.T) {
+	newConfig := func(n NtfyConfig) *Config {
+		cfg := DefaultConfig()
+		cfg.Notifications.Webhook.Enabled = true
+		cfg.Notifications.Webhook.Preset = "ntfy"
+		cfg.Notifications.Webhook.URL = "https://ntfy.example.com/claude"
+		cfg.Notifications.Webhook.Ntfy = n
+		return cfg
+	}
+
+	assert.NoError(t, newConfig(NtfyConfig{}).Validate())
+	assert.NoError(t, newConfig(NtfyConfig{Token: "tk_abc", Click: "https://example.com"}).Validate())
+	assert.NoError(t, newConfig(NtfyConfig{Username: "phil", Password: "secret"}).Validate())
+
+	err := newConfig(NtfyConfig{Token: "tk_abc", Username: "phil"}).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ntfy token and username cannot both be set")
+
+	err = newConfig(NtfyConfig{Password: "secret"}).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ntfy password requires a username")
+}
+
+func TestLoadLayered_NtfyCredentialsFromEnv(t *testing.T) {
+	pluginRoot := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(pluginRoot, "config"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginRoot, "config", "config.json"), []byte(`{
+		"notifications": {"webhook": {"enabled": true, "preset": "ntfy", "url": "https://ntfy.sh/claude", "ntfy": {"token": "${TEST_NTFY_TOKEN}"}}}
+	}`), 0644))
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("TEST_NTFY_TOKEN", "tk_from_env")
+
+	cfg, err := LoadLayered(LoadOptions{PluginRoot: pluginRoot})
+	require.NoError(t, err)
+	assert.Equal(t, "tk_from_env", cfg.Notifications.Webhook.Ntfy.Token)
+}
+
+func TestValidate_GotifyAndPushoverPresets(t *testing.T) {
+	newConfig := func(preset string, configure func(w *WebhookConfig)) *Config {
+		cfg := DefaultConfig()
+		cfg.Notifications.Webhook.Enabled = true
+		cfg.Notifications.Webhook.Preset = preset
+		cfg.Notifications.Webhook.URL = "https://push.example.com/message"
+		configure(&cfg.Notifications.Webhook)
+		return cfg
+	}
+
+	assert.NoError(t, newConfig("gotify", func(w *WebhookConfig) { w.Gotify.Token = "AbCd" }).Validate())
+	err := newConfig("gotify", func(w *WebhookConfig) {}).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "gotify token is required")
+
+	pushover := func(w *WebhookConfig) {
+		w.Pushover.Token = "app-token"
+		w.Pushover.User = "user-key"
+	}
+	assert.NoError(t, newConfig("pushover", pushover).Validate())
+
+	err = newConfig("pushover", func(w *WebhookConfig) { w.Pushover.Token = "app-token" }).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pushover token and user are required")
+
+	err = newConfig("pushover", func(w *WebhookConfig) {
+		pushover(w)
+		w.Pushover.Retry = 10
+	}).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pushover retry must be at least 30 seconds")
+
+	err = newConfig("pushover", func(w *WebhookConfig) {
+		pushover(w)
+		w.Pushover.Expire = 20000
+	}).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pushover expire must be between 1 and 10800 seconds")
+}
+
+func TestValidate_ChatPresets(t *testing.T) {
+	newConfig := func(preset, url string, configure func(w *WebhookConfig)) *Config {
+		cfg := DefaultConfig()
+		cfg.Notifications.Webhook.Enabled = true
+		cfg.Notifications.Webhook.Preset = preset
+		cfg.Notifications.Webhook.URL = url
+		configure(&cfg.Notifications.Webhook)
+		return cfg
+	}
+	none := func(w *WebhookConfig) {}
+
+	assert.NoError(t, newConfig("mattermost", "https://mm.example.com/hooks/abc", none).Validate())
+	assert.NoError(t, newConfig("mattermost", "https://mm.example.com/hooks/abc", func(w *WebhookConfig) {
+		w.Mattermost = MattermostConfig{Channel: "town-square", IconURL: "https://example.com/claude.png"}
+	}).Validate())
+
+	err := newConfig("mattermost", "https://mm.example.com/hooks/abc", func(w *WebhookConfig) { w.Mattermost.Channel = "#town-square" }).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mattermost channel must be a channel name without #")
+
+	err = newConfig("mattermost", "https://mm.example.com/hooks/abc", func(w *WebhookConfig) { w.Mattermost.IconURL = "claude.png" }).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mattermost iconUrl must be an http(s) URL")
+
+	assert.NoError(t, newConfig("rocketchat", "https://chat.example.com/hooks/id/token", func(w *WebhookConfig) {
+		w.RocketChat = RocketChatConfig{Channel: "@alice", Emoji: ":robot:"}
+	}).Validate())
+
+	err = newConfig("rocketchat", "https://chat.example.com/hooks/id/token", func(w *WebhookConfig) { w.RocketChat.Channel = "general" }).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rocketchat channel must start with # or @")
+
+	err = newConfig("rocketchat", "https://chat.example.com/hooks/id/token", func(w *WebhookConfig) { w.RocketChat.Emoji = "robot" }).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rocketchat emoji must look like :name:")
+
+	assert.NoError(t, newConfig("dingtalk", "https://oapi.dingtalk.com/robot/send?access_token=abc", func(w *WebhookConfig) {
+		w.DingTalk = DingTalkConfig{Secret: "SEC123", Keyword: "Claude"}
+	}).Validate())
+
+	err = newConfig("dingtalk", "https://oapi.dingtalk.com/robot/send", none).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dingtalk URL must include the access_token query parameter")
+
+	err = newConfig("dingtalk", "https://oapi.dingtalk.com/robot/send?access_token=abc", func(w *WebhookConfig) { w.DingTalk.Secret = "abc" }).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dingtalk secret must be the robot's signing secret starting with SEC")
+
+	err = newConfig("dingtalk", "https://oapi.dingtalk.com/robot/send?access_token=abc", func(w *WebhookConfig) { w.DingTalk.Keyword = "a\nb" }).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dingtalk keyword must be a single line")
+
+	assert.NoError(t, newConfig("wecom", "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=abc", none).Validate())
+	err = newConfig("wecom", "https://qyapi.weixin.qq.com/cgi-bin/webhook/send", none).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "wecom URL must include the key query parameter")
+
+	assert.NoError(t, newConfig("googlechat", "https://chat.googleapis.com/v1/spaces/AAA/messages?key=k&token=t", none).Validate())
+	err = newConfig("googlechat", "https://chat.googleapis.com/v1/spaces/AAA/messages?key=k", none).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "googlechat URL must include the key and token query parameters")
+}
+
+func TestValidate_MatrixPreset(t *testing.T) {
+	newConfig := func(matrix MatrixConfig, homeservers ...string) *Config {
+		cfg := DefaultConfig()
+		cfg.Notifications.Webhook.Enabled = true
+		cfg.Notifications.Webhook.Preset = "matrix"
+		cfg.Notifications.Webhook.URL = "https://matrix.internal.example.com"
+		cfg.Notifications.Webhook.Matrix = matrix
+		cfg.Notifications.MatrixHomeservers = homeservers
+		return cfg
+	}
+	valid := MatrixConfig{RoomID: "!abc123:example.com", AccessToken: "syt_token"}
+
+	assert.NoError(t, newConfig(valid).Validate())
+	assert.NoError(t, newConfig(valid, "matrix.internal.example.com").Validate())
+
+	err := newConfig(MatrixConfig{RoomID: "!abc123:example.com"}).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "matrix accessToken is required")
+
+	err = newConfig(MatrixConfig{RoomID: "#alerts:example.com", AccessToken: "syt_token"}).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "matrix roomId must be a room ID starting with !")
+
+	err = newConfig(valid, "matrix.corp.example.com").Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "matrix homeserver matrix.internal.example.com is not allowed")
+}
+
+func TestLoadLayered_MatrixHomeserversIsUserOnly(t *testing.T) {
+	dirs := setupLayers(t)
+	writeJSON(t, dirs.userPath(), `{
+		"notifications": {
+			"matrixHomeservers": ["matrix.internal.example.com"],
+			"webhooks": [{"name": "chat", "preset": "matrix", "url": "https://matrix.org",
+				"matrix": {"roomId": "!abc:matrix.org", "accessToken": "${TEST_MATRIX_TOKEN}"}}]
+		}
+	}`)
+	t.Setenv("TEST_MATRIX_TOKEN", "syt_token")
+
+	cfg, err := LoadLayered(LoadOptions{PluginRoot: dirs.pluginRoot})
+	require.NoError(t, err)
+	assert.Equal(t, "syt_token", cfg.Notifications.Webhooks[0].Matrix.AccessToken)
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "webhook chat: matrix homeserver matrix.org is not allowed")
+
+	// A project can't widen the list
+	writeJSON(t, dirs.projectPath(), `{"notifications": {"matrixHomeservers": ["matrix.org"]}}`)
+	cfg, err = LoadLayered(LoadOptions{PluginRoot: dirs.pluginRoot, CWD: dirs.project})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"matrix.internal.example.com"}, cfg.Notifications.MatrixHomeservers)
+	require.Error(t, cfg.Validate())
+}
+
+func TestLoadLayered_PushoverDefaults(t *testing.T) {
+	pluginRoot := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(pluginRoot, "config"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginRoot, "config", "config.json"), []byte(`{
+		"notifications": {"webhooks": [{"name": "oncall", "preset": "pushover", "pushover": {"token": "${TEST_PUSHOVER_TOKEN}", "user": "user-key"}}]}
+	}`), 0644))
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("TEST_PUSHOVER_TOKEN", "app-token")
+
+	cfg, err := LoadLayered(LoadOptions{PluginRoot: pluginRoot})
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate())
+
+	target := cfg.Notifications.Webhooks[0]
+	assert.Equal(t, PushoverURL, target.URL)
+	assert.Equal(t, "app-token", target.Pushover.Token)
+	assert.Equal(t, 60, target.Pushover.Retry)
+	assert.Equal(t, 3600, target.Pushover.Expire)
+}
+
+func TestValidate_Email(t *testing.T) {
+	newConfig := func(configure func(e *EmailConfig)) *Config {
+		cfg := DefaultConfig()
+		cfg.Notifications.Email.Enabled = true
+		cfg.Notifications.Email.Host = "smtp.example.com"
+		cfg.Notifications.Email.From = "Claude <claude@example.com>"
+		cfg.Notifications.Email.To = []string{"alice@example.com"}
+		if configure != nil {
+			configure(&cfg.Notifications.Email)
+		}
+		cfg.ApplyDefaults()
+		return cfg
+	}
+
+	cfg := newConfig(nil)
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, "starttls", cfg.Notifications.Email.TLS)
+	assert.Equal(t, 587, cfg.Notifications.Email.Port)
+	assert.True(t, cfg.IsAnyNotificationEnabled())
+
+	assert.Equal(t, 465, newConfig(func(e *EmailConfig) { e.TLS = "tls" }).Notifications.Email.Port)
+	assert.Equal(t, 25, newConfig(func(e *EmailConfig) { e.TLS = "none" }).Notifications.Email.Port)
+	assert.Equal(t, 2525, newConfig(func(e *EmailConfig) { e.Port = 2525 }).Notifications.Email.Port)
+
+	tests := []struct {
+		name      string
+		configure func(e *EmailConfig)
+		wantErr   string
+	}{
+		{"missing host", func(e *EmailConfig) { e.Host = "" }, "email: host is required"},
+		{"invalid tls", func(e *EmailConfig) { e.TLS = "ssl" }, "invalid tls mode: ssl"},
+		{"password without username", func(e *EmailConfig) { e.PasswordEnv = "SMTP_PASSWORD" }, "passwordEnv requires a username"},
+		{"invalid from", func(e *EmailConfig) { e.From = "not an address" }, "invalid from address"},
+		{"no recipients", func(e *EmailConfig) { e.To = nil }, "at least one to address is required"},
+		{"invalid to", func(e *EmailConfig) { e.To = []string{"alice@example.com", "bob"} }, `invalid to address "bob"`},
+		{"unknown status", func(e *EmailConfig) { e.Statuses = []string{"done"} }, "unknown status in email statuses: done"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newConfig(tt.configure).Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidate_MQTT(t *testing.T) {
+	newConfig := func(configure func(m *MQTTConfig)) *Config {
+		cfg := DefaultConfig()
+		cfg.Notifications.MQTT.Enabled = true
+		cfg.Notifications.MQTT.Broker = "tcp://homeassistant.local:1883"
+		if configure != nil {
+			configure(&cfg.Notifications.MQTT)
+		}
+		return cfg
+	}
+
+	cfg := newConfig(nil)
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, "claude", cfg.Notifications.MQTT.TopicPrefix)
+	assert.Equal(t, 1, cfg.Notifications.MQTT.QoS)
+	assert.True(t, cfg.Notifications.MQTT.Retain)
+	assert.True(t, cfg.IsAnyNotificationEnabled())
+
+	assert.NoError(t, newConfig(func(m *MQTTConfig) { m.Broker = "ssl://broker.example.com:8883" }).Validate())
+
+	tests := []struct {
+		name      string
+		configure func(m *MQTTConfig)
+		wantErr   string
+	}{
+		{"missing broker", func(m *MQTTConfig) { m.Broker = "" }, "mqtt: broker is required"},
+		{"broker without scheme", func(m *MQTTConfig) { m.Broker = "homeassistant.local:1883" }, "invalid broker"},
+		{"websocket broker", func(m *MQTTConfig) { m.Broker = "ws://homeassistant.local:1884" }, "invalid broker scheme: ws"},
+		{"invalid qos", func(m *MQTTConfig) { m.QoS = 3 }, "qos must be 0, 1 or 2"},
+		{"wildcard prefix", func(m *MQTTConfig) { m.TopicPrefix = "claude/#" }, "topicPrefix must not contain wildcards"},
+		{"trailing slash", func(m *MQTTConfig) { m.TopicPrefix = "claude/" }, "topicPrefix must not start or end with /"},
+		{"password without username", func(m *MQTTConfig) { m.Password = "secret" }, "password requires a username"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newConfig(tt.configure).Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLoadLayered_MQTTCredentialsFromEnv(t *testing.T) {
+	dirs := setupLayers(t)
+	writeJSON(t, dirs.userPath(), `{
+		"notifications": {"mqtt": {"enabled": true, "broker": "tcp://localhost:1883", "username": "claude", "password": "${TEST_MQTT_PASSWORD}"}}
+	}`)
+	t.Setenv("TEST_MQTT_PASSWORD", "s3cret")
+
+	cfg, err := LoadLayered(LoadOptions{PluginRoot: dirs.pluginRoot})
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, "s3cret", cfg.Notifications.MQTT.Password)
+	assert.True(t, cfg.Notifications.MQTT.Retain, "retain defaults to true when not set")
+}
+
+func TestValidate_Exec(t *testing.T) {
+	newConfig := func(configure func(e *ExecConfig)) *Config {
+		cfg := DefaultConfig()
+		cfg.Notifications.Exec.Enabled = true
+		cfg.Notifications.Exec.Command = []string{"espeak", "Claude needs you"}
+		if configure != nil {
+			configure(&cfg.Notifications.Exec)
+		}
+		return cfg
+	}
+
+	cfg := newConfig(nil)
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, "10s", cfg.Notifications.Exec.Timeout)
+	assert.True(t, cfg.IsAnyNotificationEnabled())
+
+	tests := []struct {
+		name      string
+		configure func(e *ExecConfig)
+		wantErr   string
+	}{
+		{"missing command", func(e *ExecConfig) { e.Command = nil }, "exec: command is required"},
+		{"empty program", func(e *ExecConfig) { e.Command = []string{""} }, "exec: command is required"},
+		{"invalid timeout", func(e *ExecConfig) { e.Timeout = "10" }, "invalid timeout: 10"},
+		{"negative timeout", func(e *ExecConfig) { e.Timeout = "-1s" }, "invalid timeout: -1s"},
+		{"unknown status", func(e *ExecConfig) { e.Statuses = []string{"done"} }, "unknown status in exec statuses: done"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newConfig(tt.configure).Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidate_Tmux(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.False(t, cfg.IsTmuxEnabled(), "tmux marks are off by default")
+	assert.Equal(t, []string{"question", "plan_ready"}, cfg.Notifications.Tmux.Statuses)
+	assert.True(t, cfg.Notifications.Tmux.Rename)
+
+	cfg.Notifications.Tmux.Enabled = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.Notifications.Tmux.Statuses = []string{"done"}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown status in tmux statuses: done")
+}
+
+func TestValidate_Schedule(t *testing.T) {
+	newConfig := func(configure func(s *ScheduleConfig)) *Config {
+		cfg := DefaultConfig()
+		cfg.Notifications.Schedule.Enabled = true
+		cfg.Notifications.Schedule.Timezone = "UTC"
+		cfg.Notifications.Schedule.Windows = []ScheduleWindow{
+			{Days: []string{"mon", "Tuesday", "FRI"}, Start: "09:00", End: "18:00"},
+			{Start: "22:00", End: "24:00"},
+		}
+		if configure != nil {
+			configure(&cfg.Notifications.Schedule)
+		}
+		return cfg
+	}
+
+	cfg := newConfig(nil)
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, "mute", cfg.Notifications.Schedule.Desktop)
+	assert.Equal(t, "deliver", cfg.Notifications.Schedule.Webhook)
+	assert.Equal(t, []string{"api_error", "session_limit_reached"}, cfg.Notifications.Schedule.BreakThrough)
+
+	tests := []struct {
+		name      string
+		configure func(s *ScheduleConfig)
+		wantErr   string
+	}{
+		{"invalid timezone", func(s *ScheduleConfig) { s.Timezone = "Mars/Olympus" }, "schedule: invalid timezone: Mars/Olympus"},
+		{"no windows", func(s *ScheduleConfig) { s.Windows = nil }, "schedule: at least one window is required"},
+		{"invalid day", func(s *ScheduleConfig) { s.Windows[0].Days = []string{"mo"} }, `window 1: invalid weekday: "mo"`},
+		{"invalid start", func(s *ScheduleConfig) { s.Windows[0].Start = "9:00am" }, "window 1: start: invalid time of day"},
+		{"invalid end", func(s *ScheduleConfig) { s.Windows[1].End = "24:30" }, "window 2: end: invalid time of day"},
+		{"empty window", func(s *ScheduleConfig) { s.Windows[0].End = "09:00" }, "window 1: start and end must differ"},
+		{"invalid desktop", func(s *ScheduleConfig) { s.Desktop = "silent" }, "invalid desktop action: silent"},
+		{"invalid webhook", func(s *ScheduleConfig) { s.Webhook = "drop" }, "invalid webhook action: drop"},
+		{"unknown status", func(s *ScheduleConfig) { s.BreakThrough = []string{"done"} }, "unknown status in breakThrough: done"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newConfig(tt.configure).Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	tests := map[string]time.Duration{
+		"00:00": 0,
+		"09:30": 9*time.Hour + 30*time.Minute,
+		"23:59": 23*time.Hour + 59*time.Minute,
+		"24:00": 24 * time.Hour,
+	}
+	for value, want := range tests {
+		got, err := ParseTimeOfDay(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, got, value)
+	}
+	for _, value := range []string{"", "9", "25:00", "12:60", "noon"} {
+		_, err := ParseTimeOfDay(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestLoadLayered_ExecExpandsOnlyProgram(t *testing.T) {
+	dirs := setupLayers(t)
+	writeJSON(t, dirs.userPath(), `{
+		"notifications": {"exec": {"enabled": true, "command": ["${TEST_EXEC_DIR}/notify.sh", "$CLAUDE_NOTIFY_STATUS"]}}
+	}`)
+	t.Setenv("TEST_EXEC_DIR", "/opt/scripts")
+
+	cfg, err := LoadLayered(LoadOptions{PluginRoot: dirs.pluginRoot})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/opt/scripts/notify.sh", "$CLAUDE_NOTIFY_STATUS"}, cfg.Notifications.Exec.Command)
+}
@@ -0,0 +1,601 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/777genius/claude-notifications/internal/platform"
+)
+
+// Layer names, in merge order (later layers override earlier ones)
+const (
+	LayerDefault = "default" // Built-in defaults
+	LayerGlobal  = "global"  // <pluginRoot>/config/config.json
+	LayerUser    = "user"    // $XDG_CONFIG_HOME/claude-notifications/config.json
+	LayerProject = "project" // .claude/notifications.json in the project (or a parent directory)
+	LayerEnv     = "env"     // CLAUDE_NOTIFICATIONS_* environment variables
+)
+
+// EnvPrefix is the prefix of environment variables that override config values
+const EnvPrefix = "CLAUDE_NOTIFICATIONS_"
+
+// ProjectConfigPath is the location of the per-project config, relative to the project directory
+var ProjectConfigPath = filepath.Join(".claude", "notifications.json")
+
+// projectKeys are the config paths a project layer may set. A project file arrives
+// with any checked-out repository, so settings that run programs or send data to other
+// hosts (exec, webhooks, email, MQTT, matrixHomeservers) are only read from the global
+// and user layers.
+var projectKeys = []string{
+	"notifications.desktop",
+	"notifications.tmux",
+	"notifications.schedule",
+	"notifications.suppressQuestionAfterTaskCompleteSeconds",
+	"notifications.suppressQuestionAfterAnyNotificationSeconds",
+	"notifications.notifyOnSubagentStop",
+	"notifications.notifyOnTextResponse",
+	"statuses",
+}
+
+// projectFileKeys are paths under projectKeys that a project layer still may not set:
+// they point the plugin at local files. A "*" segment matches any key.
+var projectFileKeys = []string{
+	"notifications.desktop.appIcon",
+	"statuses.*.sound",
+}
+
+// LoadOptions selects the sources merged by LoadLayered
+type LoadOptions struct {
+	PluginRoot string // Plugin root for the global layer
+	CWD        string // Directory to search for a project config (empty = no project layer)
+}
+
+// Layer describes a configuration source considered by LoadLayered
+type Layer struct {
+	Name   string // One of the Layer* constants
+	Source string // File path or environment variable prefix (empty for defaults)
+	Loaded bool   // False if the file does not exist or no variables were set
+	// Ignored lists the keys of a project layer that only the global and user layers may set
+	Ignored []string
+}
+
+// Origin identifies the layer that supplied a config value
+type Origin struct {
+	Layer  string
+	Source string // File path or environment variable name (empty for defaults)
+}
+
+// Setting is a single resolved config value with its origin
+type Setting struct {
+	Path   string // JSON path, e.g. "notifications.desktop.volume"
+	Value  interface{}
+	Origin Origin
+}
+
+// LoadLayered loads configuration by merging, in order: built-in defaults,
+// the global plugin config, the user config, the project config found from
+// opts.CWD and CLAUDE_NOTIFICATIONS_* environment variables.
+// Objects are merged key by key, so a layer only needs to contain the values it changes.
+func LoadLayered(opts LoadOptions) (*Config, error) {
+	merged, err := toMap(DefaultConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode default config: %w", err)
+	}
+
+	origins := make(map[string]Origin)
+	recordOrigins(origins, "", merged, Origin{Layer: LayerDefault})
+	layers := []Layer{{Name: LayerDefault, Loaded: true}}
+
+	fileLayers := []Layer{
+		{Name: LayerGlobal, Source: filepath.Join(opts.PluginRoot, "config", "config.json")},
+		{Name: LayerUser, Source: UserConfigPath()},
+	}
+	if opts.CWD != "" {
+		fileLayers = append(fileLayers, Layer{Name: LayerProject, Source: FindProjectConfig(opts.CWD)})
+	}
+
+	for _, layer := range fileLayers {
+		if layer.Source != "" && platform.FileExists(layer.Source) {
+			values, err := readLayerFile(layer.Source)
+			if err != nil {
+				return nil, err
+			}
+			if layer.Name == LayerProject {
+				layer.Ignored = restrictKeys(values, "", projectKeys, projectFileKeys)
+			}
+			mergeMaps(merged, values)
+			recordOrigins(origins, "", values, Origin{Layer: layer.Name, Source: layer.Source})
+			layer.Loaded = true
+		}
+		layers = append(layers, layer)
+	}
+
+	envCount, err := applyEnv(merged, origins, os.Environ())
+	if err != nil {
+		return nil, err
+	}
+	layers = append(layers, Layer{Name: LayerEnv, Source: EnvPrefix + "*", Loaded: envCount > 0})
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode merged config: %w", err)
+	}
+	config := &Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to decode merged config: %w", err)
+	}
+
+	config.expandEnv()
+	config.ApplyDefaults()
+	config.layers = layers
+	config.origins = origins
+
+	return config, nil
+}
+
+// UserConfigPath returns the path of the user config file:
+// $XDG_CONFIG_HOME/claude-notifications/config.json, or ~/.config/claude-notifications/config.json
+func UserConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "claude-notifications", "config.json")
+}
+
+// FindProjectConfig searches cwd and its parents for .claude/notifications.json.
+// The home directory is skipped, since ~/.claude belongs to Claude Code itself.
+// Returns an empty string if no project config is found.
+func FindProjectConfig(cwd string) string {
+	if cwd == "" {
+		return ""
+	}
+	dir, err := filepath.Abs(cwd)
+	if err != nil {
+		return ""
+	}
+	home, _ := os.UserHomeDir()
+
+	for {
+		if dir != home {
+			path := filepath.Join(dir, ProjectConfigPath)
+			if platform.FileExists(path) {
+				return path
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Layers returns the sources considered when the config was loaded, in merge order.
+// Returns nil for configs not created by LoadLayered.
+func (c *Config) Layers() []Layer {
+	return c.layers
+}
+
+// Settings returns every config value with the layer that supplied it, sorted by path
+func (c *Config) Settings() []Setting {
+	values, err := toMap(c)
+	if err != nil {
+		return nil
+	}
+
+	leaves := make(map[string]interface{})
+	flatten(leaves, "", values)
+
+	settings := make([]Setting, 0, len(leaves))
+	for path, value := range leaves {
+		settings = append(settings, Setting{Path: path, Value: value, Origin: c.originOf(path)})
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Path < settings[j].Path })
+
+	return settings
+}
+
+// originOf returns the origin of a path, falling back to the closest parent that was set as a whole
+func (c *Config) originOf(path string) Origin {
+	for p := path; p != ""; {
+		if origin, ok := c.origins[p]; ok {
+			return origin
+		}
+		i := strings.LastIndex(p, ".")
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+	}
+	return Origin{Layer: LayerDefault}
+}
+
+// expandEnv expands environment variables in paths and URLs
+func (c *Config) expandEnv() {
+	c.Notifications.Desktop.AppIcon = platform.ExpandEnv(c.Notifications.Desktop.AppIcon)
+	c.Notifications.Webhook.expandEnv()
+	c.Notifications.Email.Username = platform.ExpandEnv(c.Notifications.Email.Username)
+	c.Notifications.MQTT.Username = platform.ExpandEnv(c.Notifications.MQTT.Username)
+	c.Notifications.MQTT.Password = platform.ExpandEnv(c.Notifications.MQTT.Password)
+	c.Notifications.MQTT.CAFile = platform.ExpandEnv(c.Notifications.MQTT.CAFile)
+	// Only the program: arguments are often shell scripts that reference CLAUDE_NOTIFY_* at run time
+	if len(c.Notifications.Exec.Command) > 0 {
+		c.Notifications.Exec.Command[0] = platform.ExpandEnv(c.Notifications.Exec.Command[0])
+	}
+	for i := range c.Notifications.Webhooks {
+		c.Notifications.Webhooks[i].expandEnv()
+	}
+
+	for status, info := range c.Statuses {
+		info.Sound = platform.ExpandEnv(info.Sound)
+		c.Statuses[status] = info
+	}
+}
+
+// expandEnv expands environment variables in a webhook target's URL, file paths and credentials
+func (w *WebhookConfig) expandEnv() {
+	w.URL = platform.ExpandEnv(w.URL)
+	w.TemplateFile = platform.ExpandEnv(w.TemplateFile)
+	w.Signing.SecretFile = platform.ExpandEnv(w.Signing.SecretFile)
+	w.Ntfy.Token = platform.ExpandEnv(w.Ntfy.Token)
+	w.Ntfy.Password = platform.ExpandEnv(w.Ntfy.Password)
+	w.Gotify.Token = platform.ExpandEnv(w.Gotify.Token)
+	w.Pushover.Token = platform.ExpandEnv(w.Pushover.Token)
+	w.Pushover.User = platform.ExpandEnv(w.Pushover.User)
+	w.Matrix.AccessToken = platform.ExpandEnv(w.Matrix.AccessToken)
+	w.DingTalk.Secret = platform.ExpandEnv(w.DingTalk.Secret)
+}
+
+// redactedValue replaces secrets in Redacted configs
+const redactedValue = "***"
+
+// Redacted returns a copy of the config with tokens, passwords, secrets and
+// credential headers replaced by "***", for printing (e.g. `config show`)
+func (c *Config) Redacted() *Config {
+	r := *c
+	r.Notifications.Webhook = c.Notifications.Webhook.redacted()
+	if c.Notifications.Webhooks != nil {
+		r.Notifications.Webhooks = make([]WebhookConfig, len(c.Notifications.Webhooks))
+		for i, w := range c.Notifications.Webhooks {
+			r.Notifications.Webhooks[i] = w.redacted()
+		}
+	}
+	redact(&r.Notifications.MQTT.Password)
+	return &r
+}
+
+// redacted returns a copy of a webhook target with its credentials redacted
+func (w WebhookConfig) redacted() WebhookConfig {
+	for _, secret := range []*string{
+		&w.Ntfy.Token, &w.Ntfy.Password, &w.Gotify.Token, &w.Pushover.Token,
+		&w.Pushover.User, &w.Matrix.AccessToken, &w.DingTalk.Secret,
+	} {
+		redact(secret)
+	}
+	if w.Headers != nil {
+		headers := make(map[string]string, len(w.Headers))
+		for name, value := range w.Headers {
+			if isCredentialHeader(name) {
+				redact(&value)
+			}
+			headers[name] = value
+		}
+		w.Headers = headers
+	}
+	return w
+}
+
+// redact replaces a non-empty secret with redactedValue
+func redact(secret *string) {
+	if *secret != "" {
+		*secret = redactedValue
+	}
+}
+
+// isCredentialHeader reports whether a header usually carries a credential
+func isCredentialHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range []string{"authorization", "token", "key", "secret", "password", "cookie"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// readLayerFile reads a config file as a generic JSON object
+func readLayerFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// restrictKeys removes the values whose paths are not in allowed (or below one of its
+// entries) or that match denied, and returns the removed paths, sorted
+func restrictKeys(values map[string]interface{}, prefix string, allowed, denied []string) []string {
+	var removed []string
+	for key, value := range values {
+		path := joinPath(prefix, key)
+		m, isMap := value.(map[string]interface{})
+		switch {
+		case keyMatches(path, denied):
+			// Removed below, even inside an allowed object
+		case keyAllowed(path, allowed):
+			if isMap && isParentPattern(path, denied) {
+				removed = append(removed, restrictKeys(m, path, allowed, denied)...)
+			}
+			continue
+		case isMap && isParentKey(path, allowed):
+			removed = append(removed, restrictKeys(m, path, allowed, denied)...)
+			continue
+		}
+		delete(values, key)
+		removed = append(removed, path)
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// keyAllowed reports whether path is one of allowed or below one of them
+func keyAllowed(path string, allowed []string) bool {
+	for _, a := range allowed {
+		if path == a || strings.HasPrefix(path, a+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// isParentKey reports whether path is an object containing one of allowed
+func isParentKey(path string, allowed []string) bool {
+	for _, a := range allowed {
+		if strings.HasPrefix(a, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// keyMatches reports whether path matches one of patterns or is below a match
+func keyMatches(path string, patterns []string) bool {
+	segments := strings.Split(path, ".")
+	for _, p := range patterns {
+		pattern := strings.Split(p, ".")
+		if len(segments) >= len(pattern) && segmentsMatch(pattern, segments[:len(pattern)]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isParentPattern reports whether path is an object that may contain a match of patterns
+func isParentPattern(path string, patterns []string) bool {
+	segments := strings.Split(path, ".")
+	for _, p := range patterns {
+		pattern := strings.Split(p, ".")
+		if len(segments) < len(pattern) && segmentsMatch(pattern[:len(segments)], segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// segmentsMatch compares path segments with pattern segments of the same length
+func segmentsMatch(pattern, segments []string) bool {
+	for i := range pattern {
+		if pattern[i] != "*" && pattern[i] != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// toMap converts a value to a generic JSON object
+func toMap(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+// mergeMaps merges src into dst, recursing into objects present in both
+func mergeMaps(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeMaps(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// flatten collects the leaf values of a JSON object keyed by dotted path.
+// Empty objects are leaves.
+func flatten(out map[string]interface{}, prefix string, values map[string]interface{}) {
+	for key, value := range values {
+		path := joinPath(prefix, key)
+		if m, ok := value.(map[string]interface{}); ok && len(m) > 0 {
+			flatten(out, path, m)
+			continue
+		}
+		out[path] = value
+	}
+}
+
+// recordOrigins marks every leaf of values as supplied by origin
+func recordOrigins(origins map[string]Origin, prefix string, values map[string]interface{}, origin Origin) {
+	leaves := make(map[string]interface{})
+	flatten(leaves, prefix, values)
+	for path := range leaves {
+		origins[path] = origin
+	}
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// envField maps an environment variable to a config path
+type envField struct {
+	path []string
+	typ  reflect.Type
+}
+
+// applyEnv applies CLAUDE_NOTIFICATIONS_* variables to merged and returns how many were applied.
+// Variable names are derived from JSON paths: notifications.desktop.audioDevice becomes
+// CLAUDE_NOTIFICATIONS_DESKTOP_AUDIO_DEVICE and statuses.question.sound becomes
+// CLAUDE_NOTIFICATIONS_STATUS_QUESTION_SOUND. Unknown variables are ignored.
+func applyEnv(merged map[string]interface{}, origins map[string]Origin, environ []string) (int, error) {
+	fields := envFields(merged)
+
+	applied := 0
+	for _, entry := range environ {
+		name, raw, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+		field, ok := fields[name]
+		if !ok {
+			continue
+		}
+
+		value, err := parseEnvValue(raw, field.typ)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+
+		setPath(merged, field.path, value)
+		origins[strings.Join(field.path, ".")] = Origin{Layer: LayerEnv, Source: name}
+		applied++
+	}
+
+	return applied, nil
+}
+
+// EnvVarName returns the environment variable that overrides a config path
+func EnvVarName(path string) string {
+	segments := strings.Split(path, ".")
+	if segments[0] == "notifications" {
+		segments = segments[1:]
+	} else if segments[0] == "statuses" {
+		segments[0] = "status"
+	}
+
+	parts := make([]string, len(segments))
+	for i, segment := range segments {
+		parts[i] = upperSnake(segment)
+	}
+	return EnvPrefix + strings.Join(parts, "_")
+}
+
+// envFields returns the overridable scalar fields keyed by environment variable name
+func envFields(merged map[string]interface{}) map[string]envField {
+	fields := make(map[string]envField)
+
+	var walk func(prefix []string, t reflect.Type)
+	walk = func(prefix []string, t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := strings.Split(f.Tag.Get("json"), ",")[0]
+			if !f.IsExported() || tag == "" || tag == "-" {
+				continue
+			}
+			path := append(append([]string{}, prefix...), tag)
+
+			ft := f.Type
+			switch {
+			case ft.Kind() == reflect.Struct:
+				walk(path, ft)
+			case ft.Kind() == reflect.Slice:
+				// Lists (webhook targets, filters) are only set by config files
+				continue
+			case ft.Kind() == reflect.Map:
+				// Only statuses have a known value schema; header maps are not overridable
+				if tag != "statuses" {
+					continue
+				}
+				statuses, _ := merged[tag].(map[string]interface{})
+				for status := range statuses {
+					walk(append(append([]string{}, path...), status), ft.Elem())
+				}
+			default:
+				fields[EnvVarName(strings.Join(path, "."))] = envField{path: path, typ: ft}
+			}
+		}
+	}
+	walk(nil, reflect.TypeOf(Config{}))
+
+	return fields
+}
+
+// parseEnvValue converts a raw environment value to the JSON value for a field type
+func parseEnvValue(raw string, t reflect.Type) (interface{}, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int64:
+		return strconv.Atoi(raw)
+	case reflect.Float64:
+		return strconv.ParseFloat(raw, 64)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+// setPath sets a value in a nested JSON object, creating intermediate objects
+func setPath(m map[string]interface{}, path []string, value interface{}) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := m[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[key] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = value
+}
+
+// upperSnake converts camelCase or snake_case to UPPER_SNAKE_CASE
+func upperSnake(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
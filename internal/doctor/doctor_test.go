@@ -0,0 +1,325 @@
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validHooksJSON = `{
+  "hooks": {
+    "PreToolUse": [{"matcher": "ExitPlanMode|AskUserQuestion", "hooks": [{"type": "command", "command": "sh ${CLAUDE_PLUGIN_ROOT}/bin/hook-wrapper.sh handle-hook PreToolUse"}]}],
+    "Notification": [{"matcher": "permission_prompt", "hooks": [{"type": "command", "command": "sh ${CLAUDE_PLUGIN_ROOT}/bin/hook-wrapper.sh handle-hook Notification"}]}],
+    "Stop": [{"hooks": [{"type": "command", "command": "sh ${CLAUDE_PLUGIN_ROOT}/bin/hook-wrapper.sh handle-hook Stop"}]}],
+    "SubagentStop": [{"hooks": [{"type": "command", "command": "sh ${CLAUDE_PLUGIN_ROOT}/bin/hook-wrapper.sh handle-hook SubagentStop"}]}],
+    "UserPromptSubmit": [{"hooks": [{"type": "command", "command": "sh ${CLAUDE_PLUGIN_ROOT}/bin/hook-wrapper.sh handle-hook UserPromptSubmit"}]}],
+    "SessionEnd": [{"hooks": [{"type": "command", "command": "sh ${CLAUDE_PLUGIN_ROOT}/bin/hook-wrapper.sh handle-hook SessionEnd"}]}]
+  }
+}`
+
+// setupPluginRoot creates a plugin root with a config, sounds and hooks.json
+func setupPluginRoot(t *testing.T, configJSON, hooksJSON string) string {
+	t.Helper()
+	root := t.TempDir()
+
+	// Default status sounds are resolved relative to CLAUDE_PLUGIN_ROOT
+	t.Setenv("CLAUDE_PLUGIN_ROOT", root)
+	// Keep the user config layer away from the real home directory
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "xdg"))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "config"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "hooks"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "sounds"), 0755))
+
+	if configJSON != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(root, "config", "config.json"), []byte(configJSON), 0644))
+	}
+	if hooksJSON != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(root, "hooks", "hooks.json"), []byte(hooksJSON), 0644))
+	}
+	// Sounds are decoded, so they must be real audio
+	mp3, err := os.ReadFile(filepath.Join("..", "..", "sounds", "task-complete.mp3"))
+	require.NoError(t, err)
+	for _, sound := range []string{"done.mp3", "task-complete.mp3", "review-complete.mp3", "question.mp3", "plan-ready.mp3"} {
+		require.NoError(t, os.WriteFile(filepath.Join(root, "sounds", sound), mp3, 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(root, "icon.png"), []byte("png"), 0644))
+
+	return root
+}
+
+// newTestDoctor creates a doctor with deterministic external dependencies
+func newTestDoctor(root string) *Doctor {
+	d := New(root)
+	d.cwd = root
+	d.tempDir = root
+	d.goos = "linux"
+	d.lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	d.terminalNotifierPath = func() (string, error) { return "/usr/local/bin/terminal-notifier", nil }
+	return d
+}
+
+// findCheck returns the check with the given name
+func findCheck(t *testing.T, report *Report, name string) Check {
+	t.Helper()
+	for _, c := range report.Checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("check %q not found in report: %+v", name, report.Checks)
+	return Check{}
+}
+
+func validConfig(root string) string {
+	return `{
+  "notifications": {"desktop": {"enabled": true, "sound": true, "appIcon": "` + filepath.ToSlash(filepath.Join(root, "icon.png")) + `"}},
+  "statuses": {"task_complete": {"title": "Done", "sound": "` + filepath.ToSlash(filepath.Join(root, "sounds", "done.mp3")) + `"}}
+}`
+}
+
+func TestRun_HealthySetup(t *testing.T) {
+	root := setupPluginRoot(t, "", validHooksJSON)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "config", "config.json"), []byte(validConfig(root)), 0644))
+
+	report := newTestDoctor(root).Run()
+
+	assert.Equal(t, LevelPass, findCheck(t, report, "config").Level)
+	assert.Equal(t, LevelPass, findCheck(t, report, "config validation").Level)
+	assert.Equal(t, LevelPass, findCheck(t, report, "sound: task_complete").Level)
+	assert.Equal(t, LevelPass, findCheck(t, report, "app icon").Level)
+	assert.Equal(t, LevelPass, findCheck(t, report, "temp dir").Level)
+	assert.Equal(t, LevelPass, findCheck(t, report, "git").Level)
+	assert.Equal(t, LevelPass, findCheck(t, report, "log file").Level)
+	assert.Equal(t, LevelPass, findCheck(t, report, "hooks").Level)
+	assert.False(t, report.HasFailures())
+
+	// The log file check must not leave a file behind
+	assert.NoFileExists(t, filepath.Join(root, "notification-debug.log"))
+}
+
+func TestRun_MissingConfigUsesDefaults(t *testing.T) {
+	root := setupPluginRoot(t, "", validHooksJSON)
+
+	report := newTestDoctor(root).Run()
+
+	check := findCheck(t, report, "config")
+	assert.Equal(t, LevelWarn, check.Level)
+	assert.Contains(t, check.Message, "using defaults")
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	root := setupPluginRoot(t, `{"notifications": {"desktop": {"volume": 1.5}}}`, validHooksJSON)
+
+	report := newTestDoctor(root).Run()
+
+	check := findCheck(t, report, "config validation")
+	assert.Equal(t, LevelFail, check.Level)
+	assert.Contains(t, check.Message, "volume")
+	assert.True(t, report.HasFailures())
+}
+
+func TestRun_MalformedConfig(t *testing.T) {
+	root := setupPluginRoot(t, `{not json`, validHooksJSON)
+
+	report := newTestDoctor(root).Run()
+
+	assert.Equal(t, LevelFail, findCheck(t, report, "config").Level)
+	for _, c := range report.Checks {
+		assert.NotEqual(t, "app icon", c.Name, "config-dependent checks should be skipped")
+	}
+}
+
+func TestCheckSounds(t *testing.T) {
+	root := setupPluginRoot(t, "", validHooksJSON)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "sounds", "beep.flac"), []byte("flac"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "sounds", "broken.wav"), []byte("not a wav file"), 0644))
+
+	configJSON := `{
+  "notifications": {"desktop": {"enabled": true, "sound": %s}},
+  "statuses": {
+    "task_complete": {"sound": "` + filepath.ToSlash(filepath.Join(root, "sounds", "done.mp3")) + `"},
+    "question": {"sound": "` + filepath.ToSlash(filepath.Join(root, "sounds", "missing.mp3")) + `"},
+    "plan_ready": {"sound": "` + filepath.ToSlash(filepath.Join(root, "sounds", "beep.flac")) + `"},
+    "review_complete": {"sound": "` + filepath.ToSlash(filepath.Join(root, "sounds", "broken.wav")) + `"}
+  }
+}`
+
+	t.Run("sound enabled", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(root, "config", "config.json"), []byte(fmt.Sprintf(configJSON, "true")), 0644))
+		report := newTestDoctor(root).Run()
+
+		assert.Equal(t, LevelPass, findCheck(t, report, "sound: task_complete").Level)
+		assert.Equal(t, LevelFail, findCheck(t, report, "sound: question").Level)
+		assert.Contains(t, findCheck(t, report, "sound: question").Message, "file not found")
+		assert.Equal(t, LevelFail, findCheck(t, report, "sound: plan_ready").Level)
+		assert.Contains(t, findCheck(t, report, "sound: plan_ready").Message, "unsupported format")
+		assert.Equal(t, LevelFail, findCheck(t, report, "sound: review_complete").Level)
+		assert.Contains(t, findCheck(t, report, "sound: review_complete").Message, "failed to decode")
+	})
+
+	t.Run("sound disabled", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(root, "config", "config.json"), []byte(fmt.Sprintf(configJSON, "false")), 0644))
+		report := newTestDoctor(root).Run()
+
+		assert.Equal(t, LevelWarn, findCheck(t, report, "sound: question").Level)
+		assert.Equal(t, LevelWarn, findCheck(t, report, "sound: plan_ready").Level)
+		assert.Equal(t, LevelWarn, findCheck(t, report, "sound: review_complete").Level)
+	})
+}
+
+func TestCheckAppIcon_Missing(t *testing.T) {
+	root := setupPluginRoot(t, `{"notifications": {"desktop": {"appIcon": "/nonexistent/icon.png"}}}`, validHooksJSON)
+
+	report := newTestDoctor(root).Run()
+
+	check := findCheck(t, report, "app icon")
+	assert.Equal(t, LevelWarn, check.Level)
+	assert.Contains(t, check.Message, "/nonexistent/icon.png")
+}
+
+func TestCheckTempDir_NotWritable(t *testing.T) {
+	root := setupPluginRoot(t, "", validHooksJSON)
+	d := newTestDoctor(root)
+	d.tempDir = filepath.Join(root, "does-not-exist")
+
+	report := d.Run()
+
+	assert.Equal(t, LevelFail, findCheck(t, report, "temp dir").Level)
+}
+
+func TestCheckGit_NotFound(t *testing.T) {
+	root := setupPluginRoot(t, "", validHooksJSON)
+	d := newTestDoctor(root)
+	d.lookPath = func(string) (string, error) { return "", errors.New("not found") }
+
+	report := d.Run()
+
+	check := findCheck(t, report, "git")
+	assert.Equal(t, LevelWarn, check.Level)
+	assert.Contains(t, check.Message, "branch names")
+}
+
+func TestCheckLogFile_KeepsExistingLog(t *testing.T) {
+	root := setupPluginRoot(t, "", validHooksJSON)
+	logPath := filepath.Join(root, "notification-debug.log")
+	require.NoError(t, os.WriteFile(logPath, []byte("existing\n"), 0644))
+
+	report := newTestDoctor(root).Run()
+
+	assert.Equal(t, LevelPass, findCheck(t, report, "log file").Level)
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Equal(t, "existing\n", string(data))
+}
+
+func TestCheckHooks(t *testing.T) {
+	tests := []struct {
+		name      string
+		hooksJSON string
+		wantLevel Level
+		wantMsg   string
+	}{
+		{
+			name:      "missing file",
+			hooksJSON: "",
+			wantLevel: LevelFail,
+			wantMsg:   "failed to read",
+		},
+		{
+			name:      "malformed",
+			hooksJSON: `{"hooks": [`,
+			wantLevel: LevelFail,
+			wantMsg:   "failed to parse",
+		},
+		{
+			name:      "unsupported event",
+			hooksJSON: `{"hooks": {"Stop": [{"hooks": [{"command": "handle-hook Stop"}]}], "PostToolUse": [{"hooks": [{"command": "handle-hook PostToolUse"}]}]}}`,
+			wantLevel: LevelFail,
+			wantMsg:   "PostToolUse is not handled",
+		},
+		{
+			name:      "command for wrong event",
+			hooksJSON: `{"hooks": {"Stop": [{"hooks": [{"command": "handle-hook SubagentStop"}]}]}}`,
+			wantLevel: LevelFail,
+			wantMsg:   "expected handle-hook Stop",
+		},
+		{
+			name:      "missing events",
+			hooksJSON: `{"hooks": {"Stop": [{"hooks": [{"command": "handle-hook Stop"}]}]}}`,
+			wantLevel: LevelWarn,
+			wantMsg:   "PreToolUse, Notification, SubagentStop, UserPromptSubmit, SessionEnd not registered",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := setupPluginRoot(t, "", tt.hooksJSON)
+
+			check := findCheck(t, newTestDoctor(root).Run(), "hooks")
+			assert.Equal(t, tt.wantLevel, check.Level)
+			assert.Contains(t, check.Message, tt.wantMsg)
+		})
+	}
+}
+
+func TestCheckHooks_BundledHooksFile(t *testing.T) {
+	// The hooks.json shipped with the plugin must match the binary
+	report := newTestDoctor(filepath.Join("..", "..")).Run()
+	assert.Equal(t, LevelPass, findCheck(t, report, "hooks").Level)
+}
+
+func TestCheckTerminalNotifier(t *testing.T) {
+	root := setupPluginRoot(t, "", validHooksJSON)
+
+	t.Run("not checked on other platforms", func(t *testing.T) {
+		report := newTestDoctor(root).Run()
+		for _, c := range report.Checks {
+			assert.NotEqual(t, "terminal-notifier", c.Name)
+		}
+	})
+
+	t.Run("available on macOS", func(t *testing.T) {
+		d := newTestDoctor(root)
+		d.goos = "darwin"
+		assert.Equal(t, LevelPass, findCheck(t, d.Run(), "terminal-notifier").Level)
+	})
+
+	t.Run("missing on macOS", func(t *testing.T) {
+		d := newTestDoctor(root)
+		d.goos = "darwin"
+		d.terminalNotifierPath = func() (string, error) { return "", errors.New("terminal-notifier not found") }
+		assert.Equal(t, LevelWarn, findCheck(t, d.Run(), "terminal-notifier").Level)
+	})
+}
+
+func TestReport_Count(t *testing.T) {
+	report := &Report{}
+	report.add("a", LevelPass, "ok")
+	report.add("b", LevelWarn, "hmm")
+	report.add("c", LevelWarn, "hmm")
+
+	assert.Equal(t, 1, report.Count(LevelPass))
+	assert.Equal(t, 2, report.Count(LevelWarn))
+	assert.False(t, report.HasFailures())
+
+	report.add("d", LevelFail, "bad")
+	assert.True(t, report.HasFailures())
+}
+
+func TestRun_ProjectConfigLayer(t *testing.T) {
+	root := setupPluginRoot(t, "", validHooksJSON)
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".claude"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".claude", "notifications.json"), []byte(`{"notifications": {"desktop": {"volume": 0.5}}}`), 0644))
+
+	report := newTestDoctor(root).Run()
+
+	check := findCheck(t, report, "config")
+	assert.Equal(t, LevelPass, check.Level)
+	assert.Contains(t, check.Message, "(project)")
+}
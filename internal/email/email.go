@@ -0,0 +1,252 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/errorhandler"
+	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/777genius/claude-notifications/internal/webhook"
+)
+
+const (
+	// dialTimeout bounds connecting to the SMTP server
+	dialTimeout = 10 * time.Second
+
+	// sessionTimeout bounds a whole SMTP conversation, from greeting to QUIT
+	sessionTimeout = 30 * time.Second
+)
+
+// Sender sends email notifications over SMTP. It uses the retry and circuit breaker
+// implementation of the webhook package; the breaker state is shared by all hook processes.
+type Sender struct {
+	cfg            *config.Config
+	retry          *webhook.Retryer
+	circuitBreaker *webhook.CircuitBreaker
+	tlsConfig      *tls.Config // Base TLS settings (nil = system roots); ServerName is set per connection
+
+	// Graceful shutdown
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// New creates an email sender for the email settings of cfg
+func New(cfg *config.Config) *Sender {
+	e := cfg.Notifications.Email
+
+	circuitBreaker := webhook.NewCircuitBreakerFromConfig(e.CircuitBreaker)
+	if circuitBreaker != nil {
+		store := webhook.NewStateStore(webhook.DefaultStatePath())
+		circuitBreaker.UseStore(store, "email-"+net.JoinHostPort(e.Host, strconv.Itoa(e.Port)))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Sender{
+		cfg:            cfg,
+		retry:          webhook.NewRetryer(webhook.ParseRetryConfig(e.Retry)),
+		circuitBreaker: circuitBreaker,
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+// Send emails a notification to the configured recipients if email is enabled
+// and the status passes the statuses filter
+func (s *Sender) Send(status analyzer.Status, message, sessionID string) error {
+	e := s.cfg.Notifications.Email
+	if !e.Enabled {
+		logging.Debug("Email disabled, skipping")
+		return nil
+	}
+	if !matchesStatus(e.Statuses, status) {
+		logging.Debug("Email: filtered out (status=%s)", status)
+		return nil
+	}
+
+	statusInfo, _ := s.cfg.GetStatusInfo(string(status))
+	msg, err := buildMessage(e, status, statusInfo, message, sessionID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+
+	attempts := 0
+	sendFn := func(ctx context.Context) error {
+		attempts++
+		return s.deliver(ctx, msg)
+	}
+
+	start := time.Now()
+	if s.circuitBreaker != nil {
+		err = s.circuitBreaker.Execute(s.ctx, func() error {
+			return s.retry.Do(s.ctx, sendFn)
+		})
+	} else {
+		err = s.retry.Do(s.ctx, sendFn)
+	}
+
+	if err != nil {
+		logging.Error("Email to %d recipient(s) failed after %d attempt(s): %v", len(e.To), attempts, err)
+		return err
+	}
+	logging.Info("Email sent to %d recipient(s) (latency: %v)", len(e.To), time.Since(start))
+	return nil
+}
+
+// SendAsync sends an email in the background; Shutdown waits for it
+func (s *Sender) SendAsync(status analyzer.Status, message, sessionID string) {
+	s.wg.Add(1)
+	errorhandler.SafeGo(func() {
+		defer s.wg.Done()
+
+		if err := s.Send(status, message, sessionID); err != nil {
+			errorhandler.HandleError(err, "Async email send failed")
+		}
+	})
+}
+
+// Shutdown waits for in-flight emails to complete, and cancels them after timeout
+func (s *Sender) Shutdown(timeout time.Duration) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-time.After(timeout):
+		s.cancel()
+		logging.Warn("Email shutdown timeout, cancelled incomplete sends")
+		return fmt.Errorf("shutdown timeout after %v", timeout)
+	}
+}
+
+// deliver runs one SMTP conversation. Errors that a retry cannot fix, like SMTP 5xx
+// replies or a server without STARTTLS, are wrapped in webhook.PermanentError.
+func (s *Sender) deliver(ctx context.Context, msg []byte) error {
+	e := s.cfg.Notifications.Email
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(sessionTimeout))
+
+	// Unblock the conversation when the sender is shut down
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	tlsConfig := s.newTLSConfig(e.Host)
+	if e.TLS == "tls" {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	c, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		conn.Close()
+		return classify(fmt.Errorf("SMTP greeting failed: %w", err))
+	}
+	defer c.Close()
+
+	if e.TLS == "starttls" {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return &webhook.PermanentError{Err: fmt.Errorf("%s does not support STARTTLS", addr)}
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return classify(fmt.Errorf("STARTTLS failed: %w", err))
+		}
+	}
+
+	if e.Username != "" {
+		password := ""
+		if e.PasswordEnv != "" {
+			password = os.Getenv(e.PasswordEnv)
+			if password == "" {
+				return &webhook.PermanentError{Err: fmt.Errorf("SMTP password environment variable %s is not set", e.PasswordEnv)}
+			}
+		}
+		// PlainAuth refuses to send credentials over an unencrypted connection, except to localhost
+		if err := c.Auth(smtp.PlainAuth("", e.Username, password, e.Host)); err != nil {
+			return classify(fmt.Errorf("SMTP authentication failed: %w", err))
+		}
+	}
+
+	from, _ := mail.ParseAddress(e.From)
+	if err := c.Mail(from.Address); err != nil {
+		return classify(fmt.Errorf("MAIL FROM rejected: %w", err))
+	}
+	for _, to := range e.To {
+		rcpt, _ := mail.ParseAddress(to)
+		if err := c.Rcpt(rcpt.Address); err != nil {
+			return classify(fmt.Errorf("RCPT TO %s rejected: %w", rcpt.Address, err))
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return classify(fmt.Errorf("DATA rejected: %w", err))
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return classify(fmt.Errorf("message rejected: %w", err))
+	}
+
+	return c.Quit()
+}
+
+// newTLSConfig returns the TLS settings for a connection to host
+func (s *Sender) newTLSConfig(host string) *tls.Config {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if s.tlsConfig != nil {
+		cfg = s.tlsConfig.Clone()
+	}
+	cfg.ServerName = host
+	return cfg
+}
+
+// classify marks SMTP 5xx replies and certificate errors as permanent; 4xx replies
+// and network errors stay retryable
+func classify(err error) error {
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) && smtpErr.Code >= 500 {
+		return &webhook.PermanentError{Err: err}
+	}
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) {
+		return &webhook.PermanentError{Err: err}
+	}
+	return err
+}
+
+// matchesStatus reports whether status passes a statuses filter (empty = all)
+func matchesStatus(statuses []string, status analyzer.Status) bool {
+	if len(statuses) == 0 {
+		return true
+	}
+	for _, s := range statuses {
+		if s == string(status) {
+			return true
+		}
+	}
+	return false
+}
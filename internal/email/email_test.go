@@ -0,0 +1,386 @@
+package email
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+// receivedMail is a message accepted by the fake SMTP server
+type receivedMail struct {
+	from string
+	to   []string
+	data string
+	auth string // Decoded AUTH PLAIN response
+	tls  bool
+}
+
+// fakeSMTP is an in-process SMTP server that speaks just enough of the protocol for net/smtp
+type fakeSMTP struct {
+	listener net.Listener
+	tls      *tls.Config
+	mode     string // "starttls", "tls" or "none"
+
+	mu          sync.Mutex
+	connections int
+	mails       []receivedMail
+	busyFirst   bool // Reply 421 to the first connection's greeting
+	rejectRcpt  bool // Reply 550 to RCPT TO
+}
+
+func newFakeSMTP(t *testing.T, mode string) (*fakeSMTP, *x509.CertPool) {
+	t.Helper()
+
+	// Borrow httptest's certificate for 127.0.0.1
+	certServer := httptest.NewUnstartedServer(nil)
+	certServer.StartTLS()
+	cert := certServer.TLS.Certificates[0]
+	roots := x509.NewCertPool()
+	roots.AddCert(certServer.Certificate())
+	certServer.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	f := &fakeSMTP{
+		listener: listener,
+		tls:      &tls.Config{Certificates: []tls.Certificate{cert}},
+		mode:     mode,
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			f.serve(conn)
+		}
+	}()
+	return f, roots
+}
+
+func (f *fakeSMTP) port() int {
+	return f.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (f *fakeSMTP) connectionCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.connections
+}
+
+func (f *fakeSMTP) received() []receivedMail {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]receivedMail(nil), f.mails...)
+}
+
+func (f *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	f.mu.Lock()
+	f.connections++
+	busy := f.busyFirst && f.connections == 1
+	f.mu.Unlock()
+
+	mail := receivedMail{}
+	if f.mode == "tls" {
+		conn = tls.Server(conn, f.tls)
+		mail.tls = true
+	}
+	tp := textproto.NewConn(conn)
+
+	if busy {
+		_ = tp.PrintfLine("421 Service not available, try again later")
+		return
+	}
+	_ = tp.PrintfLine("220 fake ESMTP")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			if f.mode == "starttls" && !mail.tls {
+				_ = tp.PrintfLine("250-fake\r\n250-STARTTLS\r\n250 AUTH PLAIN")
+			} else {
+				_ = tp.PrintfLine("250-fake\r\n250 AUTH PLAIN")
+			}
+		case "STARTTLS":
+			_ = tp.PrintfLine("220 Ready to start TLS")
+			tlsConn := tls.Server(conn, f.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			tp = textproto.NewConn(conn)
+			mail.tls = true
+		case "AUTH":
+			_, initial, _ := strings.Cut(arg, " ")
+			decoded, _ := base64.StdEncoding.DecodeString(initial)
+			mail.auth = string(decoded)
+			_ = tp.PrintfLine("235 Authentication successful")
+		case "MAIL":
+			mail.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			_ = tp.PrintfLine("250 OK")
+		case "RCPT":
+			if f.rejectRcpt {
+				_ = tp.PrintfLine("550 No such user")
+				continue
+			}
+			mail.to = append(mail.to, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			_ = tp.PrintfLine("250 OK")
+		case "DATA":
+			_ = tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			mail.data = string(data)
+			f.mu.Lock()
+			f.mails = append(f.mails, mail)
+			f.mu.Unlock()
+			_ = tp.PrintfLine("250 Queued")
+		case "QUIT":
+			_ = tp.PrintfLine("221 Bye")
+			return
+		default:
+			_ = tp.PrintfLine("250 OK")
+		}
+	}
+}
+
+func newTestSender(t *testing.T, server *fakeSMTP, roots *x509.CertPool, configure func(e *config.EmailConfig)) *Sender {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Notifications.Email = config.EmailConfig{
+		Enabled: true,
+		Host:    "127.0.0.1",
+		Port:    server.port(),
+		TLS:     server.mode,
+		From:    "Claude <claude@example.com>",
+		To:      []string{"alice@example.com", "Bob <bob@example.com>"},
+		Retry: config.RetryConfig{
+			Enabled:        true,
+			MaxAttempts:    3,
+			InitialBackoff: "10ms",
+			MaxBackoff:     "20ms",
+		},
+	}
+	if configure != nil {
+		configure(&cfg.Notifications.Email)
+	}
+
+	s := New(cfg)
+	s.tlsConfig = &tls.Config{RootCAs: roots}
+	t.Cleanup(func() { _ = s.Shutdown(time.Second) })
+	return s
+}
+
+func TestSendSTARTTLSWithAuth(t *testing.T) {
+	t.Setenv("TEST_SMTP_PASSWORD", "s3cret")
+	server, roots := newFakeSMTP(t, "starttls")
+	sender := newTestSender(t, server, roots, func(e *config.EmailConfig) {
+		e.Username = "bot"
+		e.PasswordEnv = "TEST_SMTP_PASSWORD"
+	})
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "[my-app|main] Done", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	mails := server.received()
+	if len(mails) != 1 {
+		t.Fatalf("Expected 1 mail, got %d", len(mails))
+	}
+	got := mails[0]
+	if !got.tls {
+		t.Error("Expected the connection to be upgraded with STARTTLS")
+	}
+	if got.auth != "\x00bot\x00s3cret" {
+		t.Errorf("Expected PLAIN auth with the password from the environment, got %q", got.auth)
+	}
+	if got.from != "claude@example.com" {
+		t.Errorf("Expected envelope sender claude@example.com, got %s", got.from)
+	}
+	if strings.Join(got.to, ",") != "alice@example.com,bob@example.com" {
+		t.Errorf("Expected both recipients, got %v", got.to)
+	}
+}
+
+func TestSendImplicitTLS(t *testing.T) {
+	server, roots := newFakeSMTP(t, "tls")
+	sender := newTestSender(t, server, roots, nil)
+
+	if err := sender.Send(analyzer.StatusQuestion, "Which database?", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if mails := server.received(); len(mails) != 1 || !mails[0].tls {
+		t.Errorf("Expected 1 mail over TLS, got %+v", mails)
+	}
+}
+
+func TestSendRequiresSTARTTLS(t *testing.T) {
+	server, roots := newFakeSMTP(t, "none")
+	sender := newTestSender(t, server, roots, func(e *config.EmailConfig) { e.TLS = "starttls" })
+
+	err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123")
+	if err == nil || !strings.Contains(err.Error(), "does not support STARTTLS") {
+		t.Errorf("Expected STARTTLS error, got %v", err)
+	}
+	if server.connectionCount() != 1 {
+		t.Errorf("Expected no retries for a missing STARTTLS, got %d connections", server.connectionCount())
+	}
+	if len(server.received()) != 0 {
+		t.Error("Expected no mail over an unencrypted connection")
+	}
+}
+
+func TestSendRetriesTransientErrors(t *testing.T) {
+	server, roots := newFakeSMTP(t, "none")
+	server.busyFirst = true
+	sender := newTestSender(t, server, roots, nil)
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123"); err != nil {
+		t.Fatalf("Expected the 421 reply to be retried, got %v", err)
+	}
+	if server.connectionCount() != 2 || len(server.received()) != 1 {
+		t.Errorf("Expected delivery on the second connection, got %d connections", server.connectionCount())
+	}
+}
+
+func TestSendPermanentRejection(t *testing.T) {
+	server, roots := newFakeSMTP(t, "none")
+	server.rejectRcpt = true
+	sender := newTestSender(t, server, roots, nil)
+
+	err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123")
+	if err == nil || !strings.Contains(err.Error(), "550") {
+		t.Fatalf("Expected 550 error, got %v", err)
+	}
+	if server.connectionCount() != 1 {
+		t.Errorf("Expected no retries after a 5xx reply, got %d connections", server.connectionCount())
+	}
+}
+
+func TestSendStatusFilter(t *testing.T) {
+	server, roots := newFakeSMTP(t, "none")
+	sender := newTestSender(t, server, roots, func(e *config.EmailConfig) { e.Statuses = []string{"question"} })
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if server.connectionCount() != 0 {
+		t.Errorf("Expected task_complete to be filtered out, got %d connections", server.connectionCount())
+	}
+
+	if err := sender.Send(analyzer.StatusQuestion, "Which database?", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if len(server.received()) != 1 {
+		t.Errorf("Expected question to be sent")
+	}
+}
+
+func TestBuildMessage(t *testing.T) {
+	e := config.EmailConfig{
+		From: "Claude <claude@example.com>",
+		To:   []string{"alice@example.com"},
+	}
+	now := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	data, err := buildMessage(e, analyzer.StatusQuestion, config.StatusInfo{Title: "❓ Question"},
+		"Use <b>Postgres</b>?\nOr SQLite", "session-123", now)
+	if err != nil {
+		t.Fatalf("buildMessage failed: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("Invalid message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "❓ Question: Use <b>Postgres</b>?" {
+		t.Errorf("Expected title and first line in subject, got %q (%v)", subject, err)
+	}
+	if msg.Header.Get("Date") != "Wed, 15 Jan 2025 10:30:00 +0000" {
+		t.Errorf("Unexpected date: %s", msg.Header.Get("Date"))
+	}
+	if !strings.HasSuffix(msg.Header.Get("Message-ID"), "@example.com>") {
+		t.Errorf("Expected Message-ID in the sender's domain, got %s", msg.Header.Get("Message-ID"))
+	}
+	if msg.Header.Get("X-Claude-Notifications-Status") != "question" {
+		t.Errorf("Expected status header, got %q", msg.Header.Get("X-Claude-Notifications-Status"))
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Expected multipart/alternative, got %s", msg.Header.Get("Content-Type"))
+	}
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+
+	var bodies []string
+	var types []string
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Invalid part: %v", err)
+		}
+		content, _ := io.ReadAll(part) // NextPart decodes quoted-printable
+		types = append(types, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(content))
+	}
+
+	if len(bodies) != 2 || !strings.HasPrefix(types[0], "text/plain") || !strings.HasPrefix(types[1], "text/html") {
+		t.Fatalf("Expected text and HTML parts, got %v", types)
+	}
+	if bodies[0] != "❓ Question\r\n\r\nUse <b>Postgres</b>?\r\nOr SQLite\r\n\r\nSession: session-123\r\n" {
+		t.Errorf("Unexpected text part: %q", bodies[0])
+	}
+	if !strings.Contains(bodies[1], "Use &lt;b&gt;Postgres&lt;/b&gt;?<br>Or SQLite") {
+		t.Errorf("Expected escaped HTML message, got %s", bodies[1])
+	}
+}
+
+func TestSubject(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"", "Title"},
+		{"First line\nSecond line", "Title: First line"},
+		{long, "Title: " + long[:maxSubjectMessage] + "..."},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := subject("Title", tt.message); got != tt.want {
+				t.Errorf("subject(%q) = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
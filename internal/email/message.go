@@ -0,0 +1,117 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/google/uuid"
+)
+
+// maxSubjectMessage is the number of message characters included in the subject
+const maxSubjectMessage = 80
+
+// buildMessage builds a multipart/alternative email with a plain text and an HTML
+// part, both made from the status title and the message
+func buildMessage(e config.EmailConfig, status analyzer.Status, statusInfo config.StatusInfo, message, sessionID string, now time.Time) ([]byte, error) {
+	from, err := mail.ParseAddress(e.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address: %w", err)
+	}
+	to := make([]string, 0, len(e.To))
+	for _, addr := range e.To {
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to address: %w", err)
+		}
+		to = append(to, parsed.String())
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+
+	text := fmt.Sprintf("%s\n\n%s\n\nSession: %s\n", statusInfo.Title, message, sessionID)
+	if err := writePart(parts, "text/plain; charset=utf-8", text); err != nil {
+		return nil, err
+	}
+
+	htmlBody := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, 'Segoe UI', Helvetica, Arial, sans-serif; font-size: 14px;">
+<h2 style="margin: 0 0 12px;">%s</h2>
+<p>%s</p>
+<p style="color: #6c757d; font-size: 12px;">Session: %s</p>
+</body>
+</html>
+`, html.EscapeString(statusInfo.Title),
+		strings.ReplaceAll(html.EscapeString(message), "\n", "<br>"),
+		html.EscapeString(sessionID))
+	if err := writePart(parts, "text/html; charset=utf-8", htmlBody); err != nil {
+		return nil, err
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&msg, "%s: %s\r\n", key, value)
+	}
+	header("From", from.String())
+	header("To", strings.Join(to, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", subject(statusInfo.Title, message)))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s@%s>", uuid.New().String(), domain(from.Address)))
+	header("MIME-Version", "1.0")
+	header("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", parts.Boundary()))
+	header("X-Claude-Notifications-Status", string(status))
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+// writePart adds a quoted-printable encoded part to a multipart body
+func writePart(parts *multipart.Writer, contentType, content string) error {
+	w, err := parts.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// subject returns the status title followed by the first line of the message
+func subject(title, message string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	if runes := []rune(line); len(runes) > maxSubjectMessage {
+		line = string(runes[:maxSubjectMessage]) + "..."
+	}
+	if line == "" {
+		return title
+	}
+	return title + ": " + line
+}
+
+// domain returns the domain part of an email address, for Message-ID
+func domain(address string) string {
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		return address[i+1:]
+	}
+	return "localhost"
+}
@@ -0,0 +1,272 @@
+// Package focus detects whether the user is already looking at a Claude session, so
+// the desktop notification for it can be skipped.
+package focus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/777genius/claude-notifications/internal/platform"
+	"github.com/777genius/claude-notifications/internal/tmux"
+)
+
+// commandTimeout bounds each helper command (xdotool, swaymsg, hyprctl, tmux)
+const commandTimeout = 2 * time.Second
+
+// ErrUnsupported is returned by detectors that can't tell in the current environment
+var ErrUnsupported = errors.New("focus detection not supported here")
+
+// Session identifies the process tree of the Claude session a hook runs for
+type Session struct {
+	PID    int    // Hook process
+	PaneID string // $TMUX_PANE
+	CWD    string
+}
+
+// WindowDetector reports the process that owns the focused desktop window
+type WindowDetector interface {
+	Name() string
+	// ActivePID returns the PID of the focused window, or ErrUnsupported
+	ActivePID() (int, error)
+}
+
+// PaneDetector reports which terminal clients show a session's terminal multiplexer pane
+type PaneDetector interface {
+	Name() string
+	// ClientPIDs returns the PIDs of the clients the session's pane is active in (none
+	// when the pane isn't visible), or ErrUnsupported outside the multiplexer
+	ClientPIDs(s Session) ([]int, error)
+}
+
+// runFunc runs a helper command and returns its stdout
+type runFunc func(name string, args ...string) ([]byte, error)
+
+// runCommand runs name with commandTimeout
+func runCommand(name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	return exec.CommandContext(ctx, name, args...).Output()
+}
+
+// Checker decides whether a session is focused from a pane detector and window
+// detectors, all replaceable in tests
+type Checker struct {
+	Panes   PaneDetector                // nil = no multiplexer check
+	Windows []WindowDetector            // The first one that is supported is used
+	Parents func() (map[int]int, error) // Process table, PID → parent PID
+}
+
+// NewChecker creates a checker for tmux and the X11, Sway and Hyprland window systems
+func NewChecker() *Checker {
+	return &Checker{
+		Panes: &tmuxPanes{client: tmux.NewClient("")},
+		Windows: []WindowDetector{
+			&swayWindows{run: runCommand},
+			&hyprlandWindows{run: runCommand},
+			&x11Windows{run: runCommand},
+		},
+		Parents: platform.ProcessParents,
+	}
+}
+
+// Focused reports whether the user is looking at the session: its tmux pane (if any)
+// is active in an attached client, and the focused window belongs to the terminal
+// running the session (or that client). Any failure counts as not focused, so a
+// notification is never lost to a detection error.
+func (c *Checker) Focused(s Session) bool {
+	focused, err := c.check(s)
+	if err != nil {
+		logging.Debug("focus: %v", err)
+		return false
+	}
+	return focused
+}
+
+// check implements Focused
+func (c *Checker) check(s Session) (bool, error) {
+	// The terminal showing the session is an ancestor of the hook, or, in tmux, of a
+	// client the session's pane is visible in
+	roots := []int{s.PID}
+	paneChecked := false
+	if c.Panes != nil {
+		clients, err := c.Panes.ClientPIDs(s)
+		switch {
+		case errors.Is(err, ErrUnsupported):
+		case err != nil:
+			return false, fmt.Errorf("%s: %w", c.Panes.Name(), err)
+		case len(clients) == 0:
+			logging.Debug("focus: %s pane is not visible in any client", c.Panes.Name())
+			return false, nil
+		default:
+			roots = clients
+			paneChecked = true
+		}
+	}
+
+	for _, w := range c.Windows {
+		active, err := w.ActivePID()
+		if errors.Is(err, ErrUnsupported) {
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", w.Name(), err)
+		}
+
+		parents, err := c.Parents()
+		if err != nil {
+			return false, fmt.Errorf("failed to read the process table: %w", err)
+		}
+		for _, root := range roots {
+			for _, pid := range platform.ProcessAncestors(parents, root) {
+				if pid == active {
+					logging.Debug("focus: %s window of pid %d is focused", w.Name(), active)
+					return true, nil
+				}
+			}
+		}
+		logging.Debug("focus: %s window of pid %d belongs to another process", w.Name(), active)
+		return false, nil
+	}
+
+	// Without a window system (e.g. over SSH) the visible tmux pane is all we know
+	if paneChecked {
+		return true, nil
+	}
+	return false, ErrUnsupported
+}
+
+// tmuxPanes detects the tmux clients showing the session's pane
+type tmuxPanes struct {
+	client *tmux.Client
+}
+
+// Name returns "tmux"
+func (t *tmuxPanes) Name() string { return "tmux" }
+
+// ClientPIDs returns the PIDs of the attached clients whose current pane is the session's
+func (t *tmuxPanes) ClientPIDs(s Session) ([]int, error) {
+	if !tmux.InTmux() {
+		return nil, ErrUnsupported
+	}
+	pane, err := t.client.FindPane(s.PaneID, s.PID, s.CWD)
+	if err != nil {
+		return nil, err
+	}
+	return t.client.ClientPIDs(pane)
+}
+
+// x11Windows reads the focused X11 window with xdotool
+type x11Windows struct {
+	run runFunc
+}
+
+// Name returns "x11"
+func (x *x11Windows) Name() string { return "x11" }
+
+// ActivePID returns the _NET_WM_PID of the active window
+func (x *x11Windows) ActivePID() (int, error) {
+	if os.Getenv("DISPLAY") == "" || os.Getenv("WAYLAND_DISPLAY") != "" {
+		return 0, ErrUnsupported
+	}
+	out, err := x.run("xdotool", "getactivewindow", "getwindowpid")
+	if err != nil {
+		return 0, fmt.Errorf("xdotool: %w", err)
+	}
+	return parsePID(string(out))
+}
+
+// swayWindows reads the focused Sway window from the layout tree
+type swayWindows struct {
+	run runFunc
+}
+
+// Name returns "sway"
+func (w *swayWindows) Name() string { return "sway" }
+
+// swayNode is the part of a Sway tree node needed to find the focused window
+type swayNode struct {
+	Focused       bool       `json:"focused"`
+	PID           int        `json:"pid"`
+	Nodes         []swayNode `json:"nodes"`
+	FloatingNodes []swayNode `json:"floating_nodes"`
+}
+
+// ActivePID returns the PID of the focused node
+func (w *swayWindows) ActivePID() (int, error) {
+	if os.Getenv("SWAYSOCK") == "" {
+		return 0, ErrUnsupported
+	}
+	out, err := w.run("swaymsg", "-t", "get_tree", "-r")
+	if err != nil {
+		return 0, fmt.Errorf("swaymsg: %w", err)
+	}
+	var root swayNode
+	if err := json.Unmarshal(out, &root); err != nil {
+		return 0, fmt.Errorf("failed to parse sway tree: %w", err)
+	}
+	if pid := focusedSwayPID(root); pid > 0 {
+		return pid, nil
+	}
+	return 0, errors.New("no focused sway window")
+}
+
+// focusedSwayPID returns the PID of the focused node below n, or 0
+func focusedSwayPID(n swayNode) int {
+	if n.Focused && n.PID > 0 {
+		return n.PID
+	}
+	for _, children := range [][]swayNode{n.Nodes, n.FloatingNodes} {
+		for _, child := range children {
+			if pid := focusedSwayPID(child); pid > 0 {
+				return pid
+			}
+		}
+	}
+	return 0
+}
+
+// hyprlandWindows reads the active Hyprland window with hyprctl
+type hyprlandWindows struct {
+	run runFunc
+}
+
+// Name returns "hyprland"
+func (w *hyprlandWindows) Name() string { return "hyprland" }
+
+// ActivePID returns the PID of the active window
+func (w *hyprlandWindows) ActivePID() (int, error) {
+	if os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") == "" {
+		return 0, ErrUnsupported
+	}
+	out, err := w.run("hyprctl", "activewindow", "-j")
+	if err != nil {
+		return 0, fmt.Errorf("hyprctl: %w", err)
+	}
+	var window struct {
+		PID int `json:"pid"`
+	}
+	if err := json.Unmarshal(out, &window); err != nil {
+		return 0, fmt.Errorf("failed to parse hyprctl output: %w", err)
+	}
+	if window.PID <= 0 {
+		return 0, errors.New("no active hyprland window")
+	}
+	return window.PID, nil
+}
+
+// parsePID parses a PID printed by a helper command
+func parsePID(out string) (int, error) {
+	pid, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("invalid window pid %q", strings.TrimSpace(out))
+	}
+	return pid, nil
+}
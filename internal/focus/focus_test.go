@@ -0,0 +1,174 @@
+package focus
+
+import (
+	"errors"
+	"testing"
+)
+
+type fakeWindows struct {
+	pid int
+	err error
+}
+
+func (f *fakeWindows) Name() string            { return "fake" }
+func (f *fakeWindows) ActivePID() (int, error) { return f.pid, f.err }
+
+type fakePanes struct {
+	clients []int
+	err     error
+}
+
+func (f *fakePanes) Name() string                        { return "fake-tmux" }
+func (f *fakePanes) ClientPIDs(s Session) ([]int, error) { return f.clients, f.err }
+
+// Process tree used by the tests:
+//
+//	900 terminal -> 910 shell -> 920 claude -> 930 sh -> 940 hook
+//	500 other terminal -> 510 shell
+//	800 terminal -> 810 tmux client
+//	700 tmux server -> 710 shell -> 720 claude -> 730 hook
+var testParents = map[int]int{
+	940: 930, 930: 920, 920: 910, 910: 900, 900: 1,
+	510: 500, 500: 1,
+	810: 800, 800: 1,
+	730: 720, 720: 710, 710: 700, 700: 1,
+}
+
+func newTestChecker(panes PaneDetector, windows ...WindowDetector) *Checker {
+	return &Checker{
+		Panes:   panes,
+		Windows: windows,
+		Parents: func() (map[int]int, error) { return testParents, nil },
+	}
+}
+
+func TestFocused(t *testing.T) {
+	unsupported := &fakeWindows{err: ErrUnsupported}
+	noTmux := &fakePanes{err: ErrUnsupported}
+
+	tests := []struct {
+		name    string
+		checker *Checker
+		pid     int
+		want    bool
+	}{
+		{"terminal of the session focused", newTestChecker(noTmux, &fakeWindows{pid: 900}), 940, true},
+		{"other terminal focused", newTestChecker(noTmux, &fakeWindows{pid: 500}), 940, false},
+		{"window detection fails", newTestChecker(noTmux, &fakeWindows{err: errors.New("xdotool: exit status 1")}), 940, false},
+		{"no supported detector", newTestChecker(noTmux, unsupported), 940, false},
+		{"first supported detector wins", newTestChecker(nil, unsupported, &fakeWindows{pid: 900}, &fakeWindows{pid: 500}), 940, true},
+		{"tmux pane visible in focused client", newTestChecker(&fakePanes{clients: []int{810}}, &fakeWindows{pid: 800}), 730, true},
+		{"tmux pane visible, other window focused", newTestChecker(&fakePanes{clients: []int{810}}, &fakeWindows{pid: 500}), 730, false},
+		{"tmux pane not visible", newTestChecker(&fakePanes{}, &fakeWindows{pid: 800}), 730, false},
+		{"tmux pane visible, no window system", newTestChecker(&fakePanes{clients: []int{810}}, unsupported), 730, true},
+		{"tmux lookup fails", newTestChecker(&fakePanes{err: errors.New("no server")}, &fakeWindows{pid: 700}), 730, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.checker.Focused(Session{PID: tt.pid, CWD: "/src/app"}); got != tt.want {
+				t.Errorf("Focused() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFocusedProcessTableError(t *testing.T) {
+	c := newTestChecker(nil, &fakeWindows{pid: 900})
+	c.Parents = func() (map[int]int, error) { return nil, errors.New("ps not found") }
+
+	if c.Focused(Session{PID: 940}) {
+		t.Error("Expected not focused when the process table can't be read")
+	}
+}
+
+// fakeRun returns a runFunc that records the command and replies with out
+func fakeRun(out string, err error, got *[]string) runFunc {
+	return func(name string, args ...string) ([]byte, error) {
+		*got = append([]string{name}, args...)
+		return []byte(out), err
+	}
+}
+
+func TestX11Windows(t *testing.T) {
+	var cmd []string
+	w := &x11Windows{run: fakeRun("4242\n", nil, &cmd)}
+
+	t.Setenv("DISPLAY", "")
+	if _, err := w.ActivePID(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported without DISPLAY, got %v", err)
+	}
+
+	t.Setenv("DISPLAY", ":0")
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	if _, err := w.ActivePID(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported under Wayland, got %v", err)
+	}
+
+	t.Setenv("WAYLAND_DISPLAY", "")
+	pid, err := w.ActivePID()
+	if err != nil || pid != 4242 {
+		t.Errorf("Expected pid 4242, got %d (%v)", pid, err)
+	}
+	if len(cmd) == 0 || cmd[0] != "xdotool" {
+		t.Errorf("Expected xdotool to be run, got %v", cmd)
+	}
+
+	w.run = fakeRun("", nil, &cmd)
+	if _, err := w.ActivePID(); err == nil {
+		t.Error("Expected an error for empty xdotool output")
+	}
+}
+
+func TestSwayWindows(t *testing.T) {
+	tree := `{"focused": false, "nodes": [
+		{"focused": false, "nodes": [{"focused": false, "pid": 500}]},
+		{"focused": false, "nodes": [], "floating_nodes": [{"focused": true, "pid": 900}]}
+	]}`
+	var cmd []string
+	w := &swayWindows{run: fakeRun(tree, nil, &cmd)}
+
+	t.Setenv("SWAYSOCK", "")
+	if _, err := w.ActivePID(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported without SWAYSOCK, got %v", err)
+	}
+
+	t.Setenv("SWAYSOCK", "/run/user/1000/sway-ipc.sock")
+	pid, err := w.ActivePID()
+	if err != nil || pid != 900 {
+		t.Errorf("Expected pid 900, got %d (%v)", pid, err)
+	}
+
+	w.run = fakeRun(`{"focused": true, "nodes": []}`, nil, &cmd)
+	if _, err := w.ActivePID(); err == nil {
+		t.Error("Expected an error when no window is focused")
+	}
+}
+
+func TestHyprlandWindows(t *testing.T) {
+	var cmd []string
+	w := &hyprlandWindows{run: fakeRun(`{"class": "kitty", "pid": 900}`, nil, &cmd)}
+
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "")
+	if _, err := w.ActivePID(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported outside Hyprland, got %v", err)
+	}
+
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "abc")
+	pid, err := w.ActivePID()
+	if err != nil || pid != 900 {
+		t.Errorf("Expected pid 900, got %d (%v)", pid, err)
+	}
+
+	w.run = fakeRun(`{}`, nil, &cmd)
+	if _, err := w.ActivePID(); err == nil {
+		t.Error("Expected an error without an active window")
+	}
+}
+
+func TestTmuxPanesOutsideTmux(t *testing.T) {
+	t.Setenv("TMUX", "")
+	p := &tmuxPanes{}
+	if _, err := p.ClientPIDs(Session{PID: 1}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported outside tmux, got %v", err)
+	}
+}
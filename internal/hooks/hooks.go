@@ -6,18 +6,25 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/command"
 	"github.com/777genius/claude-notifications/internal/config"
 	"github.com/777genius/claude-notifications/internal/dedup"
+	"github.com/777genius/claude-notifications/internal/email"
 	"github.com/777genius/claude-notifications/internal/errorhandler"
+	"github.com/777genius/claude-notifications/internal/focus"
 	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/777genius/claude-notifications/internal/mqtt"
 	"github.com/777genius/claude-notifications/internal/notifier"
 	"github.com/777genius/claude-notifications/internal/platform"
+	"github.com/777genius/claude-notifications/internal/schedule"
 	"github.com/777genius/claude-notifications/internal/sessionname"
 	"github.com/777genius/claude-notifications/internal/state"
 	"github.com/777genius/claude-notifications/internal/summary"
+	"github.com/777genius/claude-notifications/internal/tmux"
 	"github.com/777genius/claude-notifications/internal/webhook"
 )
 
@@ -30,18 +37,76 @@ type HookData struct {
 	HookEventName  string `json:"hook_event_name,omitempty"`
 }
 
+// SupportedEvents lists the hook events handled by HandleHook
+var SupportedEvents = []string{"PreToolUse", "Notification", "Stop", "SubagentStop", "UserPromptSubmit", "SessionEnd"}
+
 // notifierInterface defines the interface for sending desktop notifications
 type notifierInterface interface {
-	SendDesktop(status analyzer.Status, message string) error
+	SendDesktop(status analyzer.Status, message, sessionID string) error
+	SendDesktopSilent(status analyzer.Status, message, sessionID string) error
 	Close() error
 }
 
 // webhookInterface defines the interface for sending webhook notifications
 type webhookInterface interface {
+	SendTest(status analyzer.Status, message, sessionID, cwd string) ([]webhook.Result, error)
+	SendAsync(status analyzer.Status, message, sessionID, cwd string)
+	Defer(status analyzer.Status, message, sessionID, cwd string, until time.Time) (int, error)
+	FlushAsync()
+	Shutdown(timeout time.Duration) error
+}
+
+// emailInterface defines the interface for sending email notifications
+type emailInterface interface {
+	Send(status analyzer.Status, message, sessionID string) error
 	SendAsync(status analyzer.Status, message, sessionID string)
 	Shutdown(timeout time.Duration) error
 }
 
+// mqttInterface defines the interface for publishing MQTT status events
+type mqttInterface interface {
+	Publish(status analyzer.Status, message, sessionID, cwd string) error
+	PublishAsync(status analyzer.Status, message, sessionID, cwd string)
+	Clear(sessionID, cwd string) error
+	Shutdown(timeout time.Duration) error
+}
+
+// execInterface defines the interface for running the exec channel's command
+type execInterface interface {
+	Run(status analyzer.Status, message, sessionID, cwd string) error
+	RunAsync(status analyzer.Status, message, sessionID, cwd string)
+	Shutdown(timeout time.Duration) error
+}
+
+// tmuxInterface defines the interface for marking the session's tmux window
+type tmuxInterface interface {
+	Mark(status analyzer.Status, cwd string) error
+	Clear(cwd string) error
+}
+
+// focusInterface defines the interface for checking whether the user is looking at a session
+type focusInterface interface {
+	Focused(s focus.Session) bool
+}
+
+// Notification channels accepted by SendTest
+const (
+	ChannelDesktop = "desktop"
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+	ChannelMQTT    = "mqtt"
+	ChannelExec    = "exec"
+	ChannelAll     = "all"
+)
+
+// ChannelResult reports the outcome of a test notification on a single channel
+type ChannelResult struct {
+	Channel  string
+	Skipped  bool             // Channel is disabled in config
+	Webhooks []webhook.Result // Delivery details per webhook target (webhook channel only)
+	Err      error
+}
+
 // Handler handles hook events
 type Handler struct {
 	cfg         *config.Config
@@ -49,7 +114,18 @@ type Handler struct {
 	stateMgr    *state.Manager
 	notifierSvc notifierInterface
 	webhookSvc  webhookInterface
+	emailSvc    emailInterface // nil when not set up (e.g. handlers built in tests)
+	mqttSvc     mqttInterface  // nil when not set up (e.g. handlers built in tests)
+	execSvc     execInterface  // nil when not set up (e.g. handlers built in tests)
+	tmuxSvc     tmuxInterface  // nil when not set up (e.g. handlers built in tests)
+	focusSvc    focusInterface // nil when not set up (e.g. handlers built in tests)
 	pluginRoot  string
+
+	// now is the clock for quiet hours and mute expiry (nil = time.Now)
+	now func() time.Time
+
+	// projectConfig enables reloading the config with the project layer found from HookData.CWD
+	projectConfig bool
 }
 
 // NewHandler creates a new hook handler
@@ -71,10 +147,75 @@ func NewHandler(pluginRoot string) (*Handler, error) {
 		stateMgr:    state.NewManager(),
 		notifierSvc: notifier.New(cfg),
 		webhookSvc:  webhook.New(cfg),
+		emailSvc:    email.New(cfg),
+		mqttSvc:     mqtt.New(cfg),
+		execSvc:     command.New(cfg),
+		tmuxSvc:     tmux.NewMarker(cfg),
+		focusSvc:    focus.NewChecker(),
 		pluginRoot:  pluginRoot,
+		now:         time.Now,
+
+		projectConfig: true,
 	}, nil
 }
 
+// applyProjectConfig reloads the config with the project layer found from cwd
+// (.claude/notifications.json) and recreates the notification services.
+func (h *Handler) applyProjectConfig(cwd string) error {
+	cfg, err := h.loadProjectConfig(cwd)
+	if err != nil || cfg == nil {
+		return err
+	}
+
+	h.cfg = cfg
+	h.notifierSvc = notifier.New(cfg)
+	h.webhookSvc = webhook.New(cfg)
+	h.emailSvc = email.New(cfg)
+	h.mqttSvc = mqtt.New(cfg)
+	h.execSvc = command.New(cfg)
+	h.tmuxSvc = tmux.NewMarker(cfg)
+	return nil
+}
+
+// loadProjectConfig loads the config with the project layer found from cwd. Returns nil
+// when there is no project config.
+func (h *Handler) loadProjectConfig(cwd string) (*config.Config, error) {
+	if !h.projectConfig {
+		return nil, nil
+	}
+	projectPath := config.FindProjectConfig(cwd)
+	if projectPath == "" {
+		return nil, nil
+	}
+
+	cfg, err := config.LoadLayered(config.LoadOptions{PluginRoot: h.pluginRoot, CWD: cwd})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid project config: %w", err)
+	}
+
+	logging.Debug("Using project config: %s", projectPath)
+	for _, layer := range cfg.Layers() {
+		var ignored []string
+		for _, key := range layer.Ignored {
+			// A repository must never choose the program the exec channel runs
+			if key == "notifications.exec" || strings.HasPrefix(key, "notifications.exec.") {
+				logging.Warn("Project config %s: ignoring notifications.exec, commands are only run from the global or user config",
+					layer.Source)
+				continue
+			}
+			ignored = append(ignored, key)
+		}
+		if len(ignored) > 0 {
+			logging.Warn("Project config %s: ignoring %s (only allowed in the global or user config)",
+				layer.Source, strings.Join(ignored, ", "))
+		}
+	}
+	return cfg, nil
+}
+
 // HandleHook handles a hook event
 func (h *Handler) HandleHook(hookEvent string, input io.Reader) error {
 	// Add panic recovery for robustness
@@ -100,6 +241,9 @@ func (h *Handler) HandleHook(hookEvent string, input io.Reader) error {
 			logging.Warn("Failed to shutdown webhook sender: %v", err)
 		}
 	}()
+	defer h.shutdownEmail()
+	defer h.shutdownMQTT()
+	defer h.shutdownExec()
 
 	logging.SetPrefix(fmt.Sprintf("PID:%d", os.Getpid()))
 	logging.Debug("=== Hook triggered: %s ===", hookEvent)
@@ -119,6 +263,23 @@ func (h *Handler) HandleHook(hookEvent string, input io.Reader) error {
 		logging.Warn("Session ID is empty, using 'unknown'")
 	}
 
+	// The user is back: clear the tmux mark set while the session waited. This runs on
+	// every prompt, so it sets up nothing but the tmux marker.
+	if hookEvent == "UserPromptSubmit" {
+		return h.handleUserPromptSubmit(hookData.CWD)
+	}
+
+	// Per-project settings override the global and user config
+	if err := h.applyProjectConfig(hookData.CWD); err != nil {
+		return err
+	}
+
+	// SessionEnd sends no notification, it only clears the session's retained MQTT status
+	// and tmux mark
+	if hookEvent == "SessionEnd" {
+		return h.handleSessionEnd(&hookData)
+	}
+
 	// Phase 1: Early duplicate check (per hook event type)
 	if h.dedupMgr.CheckEarlyDuplicate(hookData.SessionID, hookEvent) {
 		logging.Debug("Early duplicate detected, skipping")
@@ -176,6 +337,17 @@ func (h *Handler) HandleHook(hookEvent string, input io.Reader) error {
 		return nil
 	}
 
+	// Skip if the user muted this session, project or status. This comes before any
+	// state update, so a muted notification starts no cooldown and the first one after
+	// unmute isn't taken for its duplicate.
+	if h.isMuted(status, hookData.SessionID, hookData.CWD) {
+		return nil
+	}
+
+	if hookEvent == "PreToolUse" {
+		h.recordInteractiveTool(&hookData, status)
+	}
+
 	// Phase 2: Acquire lock before sending (per hook event type)
 	acquired, err := h.dedupMgr.AcquireLock(hookData.SessionID, hookEvent)
 	if err != nil {
@@ -285,23 +457,115 @@ func (h *Handler) HandleHook(hookEvent string, input io.Reader) error {
 	return nil
 }
 
+// SendTest sends a synthetic notification for status through the selected channel
+// ("desktop", "webhook", "email", "mqtt", "exec" or "all"). Unlike HandleHook it bypasses dedup and cooldown
+// checks and sends synchronously, so each channel's result can be reported. Failed
+// webhooks are not saved to the outbox.
+// An error is returned only if the arguments are invalid.
+func (h *Handler) SendTest(hookData *HookData, status analyzer.Status, channel string) ([]ChannelResult, error) {
+	defer func() {
+		if err := h.notifierSvc.Close(); err != nil {
+			logging.Warn("Failed to close notifier: %v", err)
+		}
+	}()
+	defer func() {
+		if err := h.webhookSvc.Shutdown(5 * time.Second); err != nil {
+			logging.Warn("Failed to shutdown webhook sender: %v", err)
+		}
+	}()
+	defer h.shutdownEmail()
+	defer h.shutdownMQTT()
+	defer h.shutdownExec()
+
+	if err := h.applyProjectConfig(hookData.CWD); err != nil {
+		return nil, err
+	}
+
+	if _, ok := h.cfg.GetStatusInfo(string(status)); !ok {
+		return nil, fmt.Errorf("unknown status: %s", status)
+	}
+
+	var channels []string
+	switch channel {
+	case ChannelDesktop, ChannelWebhook, ChannelEmail, ChannelMQTT, ChannelExec:
+		channels = []string{channel}
+	case ChannelAll:
+		channels = []string{ChannelDesktop, ChannelWebhook, ChannelEmail, ChannelMQTT, ChannelExec}
+	default:
+		return nil, fmt.Errorf("unknown channel: %s (must be desktop, webhook, email, mqtt, exec or all)", channel)
+	}
+
+	logging.Debug("=== Test notification: status=%s, channel=%s ===", status, channel)
+
+	message := h.enhanceMessage(h.generateMessage(hookData, status), hookData.SessionID, hookData.CWD)
+
+	results := make([]ChannelResult, 0, len(channels))
+	for _, ch := range channels {
+		result := ChannelResult{Channel: ch}
+
+		switch ch {
+		case ChannelDesktop:
+			if !h.cfg.IsDesktopEnabled() {
+				result.Skipped = true
+				break
+			}
+			result.Err = h.notifierSvc.SendDesktop(status, message, hookData.SessionID)
+		case ChannelWebhook:
+			if !h.cfg.IsWebhookEnabled() {
+				result.Skipped = true
+				break
+			}
+			result.Webhooks, result.Err = h.webhookSvc.SendTest(status, message, hookData.SessionID, hookData.CWD)
+		case ChannelEmail:
+			if !h.cfg.IsEmailEnabled() || h.emailSvc == nil {
+				result.Skipped = true
+				break
+			}
+			result.Err = h.emailSvc.Send(status, message, hookData.SessionID)
+		case ChannelMQTT:
+			if !h.cfg.IsMQTTEnabled() || h.mqttSvc == nil {
+				result.Skipped = true
+				break
+			}
+			result.Err = h.mqttSvc.Publish(status, message, hookData.SessionID, hookData.CWD)
+		case ChannelExec:
+			if !h.cfg.IsExecEnabled() || h.execSvc == nil {
+				result.Skipped = true
+				break
+			}
+			result.Err = h.execSvc.Run(status, message, hookData.SessionID, hookData.CWD)
+		}
+
+		// A channel that was asked for explicitly must be enabled
+		if result.Skipped && channel != ChannelAll {
+			result.Err = fmt.Errorf("%s notifications are disabled in config", ch)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
 // handlePreToolUse handles PreToolUse hook
 func (h *Handler) handlePreToolUse(hookData *HookData) analyzer.Status {
 	logging.Debug("PreToolUse: tool_name='%s'", hookData.ToolName)
 
-	status := analyzer.GetStatusForPreToolUse(hookData.ToolName)
+	return analyzer.GetStatusForPreToolUse(hookData.ToolName)
+}
 
-	// Write session state BEFORE returning (prevents race with Notification hook)
-	// This matches bash version behavior: state is written BEFORE notification is sent
-	if status == analyzer.StatusPlanReady || status == analyzer.StatusQuestion {
-		if err := h.stateMgr.UpdateInteractiveTool(hookData.SessionID, hookData.ToolName, hookData.CWD); err != nil {
-			logging.Warn("Failed to update interactive tool state: %v", err)
-		} else {
-			logging.Debug("PreToolUse: session state written (tool=%s)", hookData.ToolName)
-		}
+// recordInteractiveTool writes the session state for a PreToolUse of an interactive tool.
+// It runs before the notification is sent (prevents race with Notification hook), which
+// matches the bash version.
+func (h *Handler) recordInteractiveTool(hookData *HookData, status analyzer.Status) {
+	if status != analyzer.StatusPlanReady && status != analyzer.StatusQuestion {
+		return
+	}
+	if err := h.stateMgr.UpdateInteractiveTool(hookData.SessionID, hookData.ToolName, hookData.CWD); err != nil {
+		logging.Warn("Failed to update interactive tool state: %v", err)
+	} else {
+		logging.Debug("PreToolUse: session state written (tool=%s)", hookData.ToolName)
 	}
-
-	return status
 }
 
 // handleNotificationEvent handles Notification hook
@@ -346,37 +610,221 @@ func (h *Handler) generateMessage(hookData *HookData, status analyzer.Status) st
 	return summary.GenerateSimple(status, h.cfg)
 }
 
-// sendNotifications sends desktop and webhook notifications
+// sendNotifications sends desktop, webhook and email notifications, publishes the MQTT
+// status and runs the exec command
 func (h *Handler) sendNotifications(status analyzer.Status, message, sessionID, cwd string) {
 	// Add panic recovery to prevent notification failures from crashing the plugin
 	defer errorhandler.HandlePanic()
 
-	// Add session name, git branch and folder name to message
-	sessionName := sessionname.GenerateSessionName(sessionID)
-	gitBranch := platform.GetGitBranch(cwd)
-	folderName := filepath.Base(cwd)
-
-	// Format: "[folder|branch] message" or "[folder] message"
-	var enhancedMessage string
-	if gitBranch != "" {
-		enhancedMessage = fmt.Sprintf("[%s|%s] %s", folderName, gitBranch, message)
-	} else {
-		enhancedMessage = fmt.Sprintf("[%s] %s", folderName, message)
-	}
-
-	logging.Debug("Session name: %s, git branch: %s, folder: %s", sessionName, gitBranch, folderName)
+	enhancedMessage := h.enhanceMessage(message, sessionID, cwd)
+	quiet := h.quietHours(status)
 
-	// Send desktop notification
+	// Send desktop notification, unless the user is looking at the session or quiet
+	// hours suppress it
 	if h.cfg.IsDesktopEnabled() {
-		if err := h.notifierSvc.SendDesktop(status, enhancedMessage); err != nil {
+		var err error
+		switch {
+		case h.sessionFocused(cwd):
+			logging.Debug("Session is focused, skipping desktop notification")
+		case quiet.SuppressDesktop:
+			logging.Debug("Quiet hours, skipping desktop notification")
+		case quiet.MuteDesktop:
+			err = h.notifierSvc.SendDesktopSilent(status, enhancedMessage, sessionID)
+		default:
+			err = h.notifierSvc.SendDesktop(status, enhancedMessage, sessionID)
+		}
+		if err != nil {
 			errorhandler.HandleError(err, "Failed to send desktop notification")
 		}
 	}
 
-	// Send webhook notification (async)
+	// Send webhook notification (async) and redeliver anything left in the outbox.
+	// During quiet hours with deferral, the webhook joins the outbox instead.
 	if h.cfg.IsWebhookEnabled() {
-		h.webhookSvc.SendAsync(status, enhancedMessage, sessionID)
+		if quiet.DeferWebhook {
+			if _, err := h.webhookSvc.Defer(status, enhancedMessage, sessionID, cwd, quiet.Until); err != nil {
+				errorhandler.HandleError(err, "Failed to defer webhook")
+			}
+		} else {
+			h.webhookSvc.SendAsync(status, enhancedMessage, sessionID, cwd)
+			h.webhookSvc.FlushAsync()
+		}
+	}
+
+	// Send email notification (async)
+	if h.cfg.IsEmailEnabled() && h.emailSvc != nil {
+		h.emailSvc.SendAsync(status, enhancedMessage, sessionID)
+	}
+
+	// Publish status event (async)
+	if h.cfg.IsMQTTEnabled() && h.mqttSvc != nil {
+		h.mqttSvc.PublishAsync(status, enhancedMessage, sessionID, cwd)
+	}
+
+	// Run user command (async)
+	if h.cfg.IsExecEnabled() && h.execSvc != nil {
+		h.execSvc.RunAsync(status, enhancedMessage, sessionID, cwd)
+	}
+
+	// Mark the session's tmux window (or clear the mark for other statuses)
+	if h.cfg.IsTmuxEnabled() && h.tmuxSvc != nil {
+		if err := h.tmuxSvc.Mark(status, cwd); err != nil {
+			errorhandler.HandleError(err, "Failed to mark tmux window")
+		}
+	}
+}
+
+// isMuted reports whether an active mute (`claude-notifications mute`) applies to the
+// notification. A mute store that can't be read doesn't silence anything.
+func (h *Handler) isMuted(status analyzer.Status, sessionID, cwd string) bool {
+	mute, err := h.stateMgr.MatchingMute(sessionID, string(status), cwd, h.clock())
+	if err != nil {
+		logging.Warn("Failed to check mutes: %v", err)
+		return false
+	}
+	if mute == nil {
+		return false
+	}
+	if mute.ExpiresAt.IsZero() {
+		logging.Debug("Muted (%s: %s), skipping notification", mute.ID, mute.Describe())
+	} else {
+		logging.Debug("Muted until %s (%s: %s), skipping notification",
+			mute.ExpiresAt.Format(time.RFC3339), mute.ID, mute.Describe())
+	}
+	return true
+}
+
+// clock returns the current time from the handler's clock
+func (h *Handler) clock() time.Time {
+	if h.now != nil {
+		return h.now()
+	}
+	return time.Now()
+}
+
+// quietHours returns what the quiet hours schedule does to a notification with status
+func (h *Handler) quietHours(status analyzer.Status) schedule.Decision {
+	if !h.cfg.Notifications.Schedule.Enabled {
+		return schedule.Decision{}
+	}
+	sched, err := schedule.New(h.cfg.Notifications.Schedule)
+	if err != nil {
+		errorhandler.HandleError(err, "Invalid quiet hours schedule")
+		return schedule.Decision{}
+	}
+
+	t := h.clock()
+	decision := sched.Decide(status, t)
+	if decision.Quiet {
+		logging.Debug("Quiet hours until %s (status=%s)", decision.Until.Format(time.RFC3339), status)
+	}
+	return decision
+}
+
+// sessionFocused reports whether desktop notifications are suppressed for focused
+// sessions and the session's terminal is focused
+func (h *Handler) sessionFocused(cwd string) bool {
+	if !h.cfg.Notifications.Desktop.SuppressWhenFocused || h.focusSvc == nil {
+		return false
+	}
+	return h.focusSvc.Focused(focus.Session{
+		PID:    os.Getpid(),
+		PaneID: os.Getenv("TMUX_PANE"),
+		CWD:    cwd,
+	})
+}
+
+// handleSessionEnd clears the retained MQTT status and the tmux mark of an ended session
+func (h *Handler) handleSessionEnd(hookData *HookData) error {
+	h.clearTmuxMark(hookData.CWD)
+
+	if !h.cfg.IsMQTTEnabled() || h.mqttSvc == nil {
+		logging.Debug("SessionEnd: MQTT disabled, nothing to clear")
+		return nil
+	}
+	if err := h.mqttSvc.Clear(hookData.SessionID, hookData.CWD); err != nil {
+		errorhandler.HandleError(err, "Failed to clear MQTT status")
+	}
+	return nil
+}
+
+// clearTmuxMark removes the mark from the session's tmux window
+func (h *Handler) clearTmuxMark(cwd string) {
+	if !h.cfg.IsTmuxEnabled() || h.tmuxSvc == nil {
+		return
+	}
+	if err := h.tmuxSvc.Clear(cwd); err != nil {
+		errorhandler.HandleError(err, "Failed to clear tmux mark")
+	}
+}
+
+// handleUserPromptSubmit clears the tmux mark of the session. Outside tmux, or with tmux
+// marks disabled in the global and user config, it returns before loading anything.
+func (h *Handler) handleUserPromptSubmit(cwd string) error {
+	if !h.cfg.IsTmuxEnabled() || !tmux.InTmux() {
+		return nil
+	}
+
+	cfg, err := h.loadProjectConfig(cwd)
+	if err != nil {
+		return err
+	}
+	if cfg != nil {
+		h.cfg = cfg
+		h.tmuxSvc = tmux.NewMarker(cfg)
+	}
+	h.clearTmuxMark(cwd)
+	return nil
+}
+
+// shutdownEmail waits for in-flight emails before exit
+func (h *Handler) shutdownEmail() {
+	if h.emailSvc == nil {
+		return
+	}
+	if err := h.emailSvc.Shutdown(5 * time.Second); err != nil {
+		logging.Warn("Failed to shutdown email sender: %v", err)
+	}
+}
+
+// shutdownMQTT waits for in-flight MQTT publishes before exit
+func (h *Handler) shutdownMQTT() {
+	if h.mqttSvc == nil {
+		return
+	}
+	if err := h.mqttSvc.Shutdown(5 * time.Second); err != nil {
+		logging.Warn("Failed to shutdown MQTT publisher: %v", err)
+	}
+}
+
+// shutdownExec waits for running exec commands before exit. Commands are killed at
+// their own timeout, so it waits a little longer than that.
+func (h *Handler) shutdownExec() {
+	if h.execSvc == nil {
+		return
+	}
+	timeout, err := time.ParseDuration(h.cfg.Notifications.Exec.Timeout)
+	if err != nil || timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	if err := h.execSvc.Shutdown(timeout + 2*time.Second); err != nil {
+		logging.Warn("Failed to shutdown exec runner: %v", err)
+	}
+}
+
+// enhanceMessage adds the folder name and git branch to a message
+func (h *Handler) enhanceMessage(message, sessionID, cwd string) string {
+	sessionName := sessionname.GenerateSessionName(sessionID)
+	gitBranch := platform.GetGitBranch(cwd)
+	folderName := filepath.Base(cwd)
+
+	logging.Debug("Session name: %s, git branch: %s, folder: %s", sessionName, gitBranch, folderName)
+
+	// Format: "[folder|branch] message" or "[folder] message"
+	if gitBranch != "" {
+		return fmt.Sprintf("[%s|%s] %s", folderName, gitBranch, message)
 	}
+	return fmt.Sprintf("[%s] %s", folderName, message)
 }
 
 // cleanupOldLocks cleans up old lock and state files but preserves session state for cooldown
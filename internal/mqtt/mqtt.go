@@ -0,0 +1,238 @@
+package mqtt
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/errorhandler"
+	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/777genius/claude-notifications/internal/sessionname"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+)
+
+const (
+	// connectTimeout bounds connecting to the broker, including the TLS handshake
+	connectTimeout = 10 * time.Second
+
+	// publishTimeout bounds waiting for the broker to acknowledge a publish (QoS 1 and 2)
+	publishTimeout = 10 * time.Second
+
+	// disconnectQuiesce is how long Disconnect waits for in-flight work, in milliseconds
+	disconnectQuiesce = 250
+)
+
+// Event is the JSON payload published for each notification
+type Event struct {
+	Status      string `json:"status"`
+	Title       string `json:"title"`
+	Message     string `json:"message"`
+	SessionID   string `json:"sessionId"`
+	SessionName string `json:"sessionName"`
+	Project     string `json:"project"`
+	CWD         string `json:"cwd"`
+	Timestamp   string `json:"timestamp"`
+}
+
+// Publisher publishes status events to an MQTT broker. Hook processes are short-lived,
+// so every publish opens its own connection and closes it once the broker acknowledges.
+type Publisher struct {
+	cfg *config.Config
+	wg  sync.WaitGroup
+}
+
+// New creates a publisher for the MQTT settings of cfg
+func New(cfg *config.Config) *Publisher {
+	return &Publisher{cfg: cfg}
+}
+
+// Publish sends a status event for a session to <topicPrefix>/<project>/<session>/status
+func (p *Publisher) Publish(status analyzer.Status, message, sessionID, cwd string) error {
+	m := p.cfg.Notifications.MQTT
+	if !m.Enabled {
+		logging.Debug("MQTT disabled, skipping")
+		return nil
+	}
+
+	statusInfo, _ := p.cfg.GetStatusInfo(string(status))
+	payload, err := json.Marshal(Event{
+		Status:      string(status),
+		Title:       statusInfo.Title,
+		Message:     message,
+		SessionID:   sessionID,
+		SessionName: sessionname.GenerateSessionName(sessionID),
+		Project:     project(cwd),
+		CWD:         cwd,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal MQTT event: %w", err)
+	}
+
+	t := topic(m.TopicPrefix, cwd, sessionID)
+	start := time.Now()
+	if err := p.publish(t, payload, m.Retain); err != nil {
+		logging.Error("MQTT publish to %s failed: %v", t, err)
+		return err
+	}
+	logging.Info("MQTT: published %s to %s (latency: %v)", status, t, time.Since(start))
+	return nil
+}
+
+// PublishAsync publishes a status event in the background; Shutdown waits for it
+func (p *Publisher) PublishAsync(status analyzer.Status, message, sessionID, cwd string) {
+	p.wg.Add(1)
+	errorhandler.SafeGo(func() {
+		defer p.wg.Done()
+
+		if err := p.Publish(status, message, sessionID, cwd); err != nil {
+			errorhandler.HandleError(err, "Async MQTT publish failed")
+		}
+	})
+}
+
+// Clear removes the retained status of a session from the broker, so automations
+// stop seeing it once the session has ended. It is a no-op when retain is off.
+func (p *Publisher) Clear(sessionID, cwd string) error {
+	m := p.cfg.Notifications.MQTT
+	if !m.Enabled || !m.Retain {
+		return nil
+	}
+
+	// An empty retained message deletes the retained message of a topic
+	t := topic(m.TopicPrefix, cwd, sessionID)
+	if err := p.publish(t, []byte{}, true); err != nil {
+		logging.Error("MQTT clear of %s failed: %v", t, err)
+		return err
+	}
+	logging.Info("MQTT: cleared retained status of %s", t)
+	return nil
+}
+
+// Shutdown waits for in-flight publishes to complete
+func (p *Publisher) Shutdown(timeout time.Duration) error {
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		logging.Warn("MQTT shutdown timeout, abandoned incomplete publishes")
+		return fmt.Errorf("shutdown timeout after %v", timeout)
+	}
+}
+
+// publish connects to the broker, publishes one message and disconnects
+func (p *Publisher) publish(t string, payload []byte, retain bool) error {
+	m := p.cfg.Notifications.MQTT
+
+	opts := paho.NewClientOptions().
+		AddBroker(m.Broker).
+		SetClientID("claude-notifications-" + uuid.New().String()).
+		SetCleanSession(true).
+		SetAutoReconnect(false).
+		SetConnectTimeout(connectTimeout).
+		SetWriteTimeout(publishTimeout)
+	if m.Username != "" {
+		opts.SetUsername(m.Username)
+		opts.SetPassword(m.Password)
+	}
+	if isTLS(m.Broker) {
+		tlsConfig, err := newTLSConfig(m.CAFile)
+		if err != nil {
+			return err
+		}
+		opts.SetTLSConfig(tlsConfig)
+	}
+
+	client := paho.NewClient(opts)
+	token := client.Connect()
+	if !token.WaitTimeout(connectTimeout) {
+		return fmt.Errorf("timed out connecting to %s", m.Broker)
+	}
+	if err := token.Error(); err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", m.Broker, err)
+	}
+	defer client.Disconnect(disconnectQuiesce)
+
+	token = client.Publish(t, byte(m.QoS), retain, payload)
+	if !token.WaitTimeout(publishTimeout) {
+		return fmt.Errorf("timed out waiting for the broker to acknowledge %s", t)
+	}
+	return token.Error()
+}
+
+// newTLSConfig returns the TLS settings for the broker, trusting caFile if set
+func newTLSConfig(caFile string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile == "" {
+		return cfg, nil
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read caFile: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in caFile %s", caFile)
+	}
+	cfg.RootCAs = roots
+	return cfg, nil
+}
+
+// isTLS reports whether a broker URL uses TLS
+func isTLS(broker string) bool {
+	u, err := url.Parse(broker)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "ssl", "tls", "mqtts":
+		return true
+	}
+	return false
+}
+
+// topic returns the status topic of a session: <prefix>/<project>/<session>/status
+func topic(prefix, cwd, sessionID string) string {
+	return strings.Join([]string{prefix, topicLevel(project(cwd)), topicLevel(sessionID), "status"}, "/")
+}
+
+// project returns the project name of a working directory
+func project(cwd string) string {
+	if cwd == "" {
+		return ""
+	}
+	return filepath.Base(cwd)
+}
+
+// topicLevel makes s safe to use as a single topic level: separators and
+// wildcards are replaced, and an empty level becomes "unknown"
+func topicLevel(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '+', '#', 0:
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "" || s == "." {
+		return "unknown"
+	}
+	return s
+}
@@ -0,0 +1,261 @@
+package mqtt
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+)
+
+// brokerOptions configures the embedded test broker
+type brokerOptions struct {
+	tls      *tls.Config
+	username string // Require these credentials (empty = allow anonymous)
+	password string
+}
+
+// startBroker starts an embedded MQTT broker and returns it with its address
+func startBroker(t *testing.T, opts brokerOptions) (*mochi.Server, string) {
+	t.Helper()
+
+	server := mochi.New(&mochi.Options{
+		InlineClient: true,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	var err error
+	if opts.username != "" {
+		err = server.AddHook(new(auth.Hook), &auth.Options{
+			Ledger: &auth.Ledger{
+				Auth: auth.AuthRules{
+					{Username: auth.RString(opts.username), Password: auth.RString(opts.password), Allow: true},
+				},
+			},
+		})
+	} else {
+		err = server.AddHook(new(auth.AllowHook), nil)
+	}
+	if err != nil {
+		t.Fatalf("Failed to add auth hook: %v", err)
+	}
+
+	listener := listeners.NewTCP(listeners.Config{ID: "test", Address: "127.0.0.1:0", TLSConfig: opts.tls})
+	if err := server.AddListener(listener); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go func() { _ = server.Serve() }()
+	t.Cleanup(func() { _ = server.Close() })
+
+	return server, listener.Address()
+}
+
+// retained returns the retained message of a topic, or nil if there is none
+func retained(server *mochi.Server, topic string) *packets.Packet {
+	messages := server.Topics.Messages(topic)
+	if len(messages) == 0 {
+		return nil
+	}
+	return &messages[0]
+}
+
+func newTestPublisher(broker string, configure func(m *config.MQTTConfig)) *Publisher {
+	cfg := config.DefaultConfig()
+	cfg.Notifications.MQTT.Enabled = true
+	cfg.Notifications.MQTT.Broker = broker
+	if configure != nil {
+		configure(&cfg.Notifications.MQTT)
+	}
+	return New(cfg)
+}
+
+func TestPublishRetainedStatus(t *testing.T) {
+	server, addr := startBroker(t, brokerOptions{})
+	publisher := newTestPublisher("tcp://"+addr, nil)
+
+	if err := publisher.Publish(analyzer.StatusQuestion, "[my-app|main] Which database?", "session-123", "/home/user/my-app"); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	pk := retained(server, "claude/my-app/session-123/status")
+	if pk == nil {
+		t.Fatal("Expected a retained status message")
+	}
+	if pk.FixedHeader.Qos != 1 {
+		t.Errorf("Expected QoS 1, got %d", pk.FixedHeader.Qos)
+	}
+
+	var event Event
+	if err := json.Unmarshal(pk.Payload, &event); err != nil {
+		t.Fatalf("Invalid payload: %v", err)
+	}
+	if event.Status != "question" || event.Title != "❓ Question" || event.Message != "[my-app|main] Which database?" {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if event.SessionID != "session-123" || event.SessionName == "" || event.Project != "my-app" || event.CWD != "/home/user/my-app" {
+		t.Errorf("Unexpected session fields: %+v", event)
+	}
+	if _, err := time.Parse(time.RFC3339, event.Timestamp); err != nil {
+		t.Errorf("Expected RFC 3339 timestamp, got %q", event.Timestamp)
+	}
+
+	// The latest status replaces the previous one
+	if err := publisher.Publish(analyzer.StatusTaskComplete, "Done", "session-123", "/home/user/my-app"); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if pk := retained(server, "claude/my-app/session-123/status"); pk == nil || !strings.Contains(string(pk.Payload), `"status":"task_complete"`) {
+		t.Errorf("Expected retained task_complete status, got %v", pk)
+	}
+}
+
+func TestPublishWithoutRetain(t *testing.T) {
+	server, addr := startBroker(t, brokerOptions{})
+	publisher := newTestPublisher("mqtt://"+addr, func(m *config.MQTTConfig) {
+		m.TopicPrefix = "office/desk"
+		m.QoS = 2
+		m.Retain = false
+	})
+
+	received := make(chan packets.Packet, 1)
+	err := server.Subscribe("office/desk/#", 1, func(cl *mochi.Client, sub packets.Subscription, pk packets.Packet) {
+		received <- pk
+	})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	if err := publisher.Publish(analyzer.StatusTaskComplete, "Done", "session-123", "/home/user/my-app"); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	select {
+	case pk := <-received:
+		if pk.TopicName != "office/desk/my-app/session-123/status" {
+			t.Errorf("Unexpected topic: %s", pk.TopicName)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected subscriber to receive the status")
+	}
+
+	if retained(server, "office/desk/my-app/session-123/status") != nil {
+		t.Error("Expected no retained message with retain disabled")
+	}
+}
+
+func TestClearRemovesRetainedStatus(t *testing.T) {
+	server, addr := startBroker(t, brokerOptions{})
+	publisher := newTestPublisher("tcp://"+addr, nil)
+
+	if err := publisher.Publish(analyzer.StatusTaskComplete, "Done", "session-123", "/home/user/my-app"); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if err := publisher.Publish(analyzer.StatusQuestion, "Which database?", "session-456", "/home/user/my-app"); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if err := publisher.Clear("session-123", "/home/user/my-app"); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+
+	if retained(server, "claude/my-app/session-123/status") != nil {
+		t.Error("Expected retained status of the ended session to be cleared")
+	}
+	if retained(server, "claude/my-app/session-456/status") == nil {
+		t.Error("Expected other sessions to keep their retained status")
+	}
+}
+
+func TestPublishTLSWithAuth(t *testing.T) {
+	// Borrow httptest's certificate for 127.0.0.1
+	certServer := httptest.NewUnstartedServer(nil)
+	certServer.StartTLS()
+	cert := certServer.TLS.Certificates[0]
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certServer.Certificate().Raw})
+	certServer.Close()
+	if err := os.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+
+	server, addr := startBroker(t, brokerOptions{
+		tls:      &tls.Config{Certificates: []tls.Certificate{cert}},
+		username: "claude",
+		password: "s3cret",
+	})
+
+	publisher := newTestPublisher("ssl://"+addr, func(m *config.MQTTConfig) {
+		m.Username = "claude"
+		m.Password = "s3cret"
+		m.CAFile = caFile
+	})
+	if err := publisher.Publish(analyzer.StatusPlanReady, "Plan ready", "session-123", "/home/user/my-app"); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if retained(server, "claude/my-app/session-123/status") == nil {
+		t.Error("Expected status to be published over TLS")
+	}
+
+	// Wrong credentials are rejected by the broker
+	publisher = newTestPublisher("ssl://"+addr, func(m *config.MQTTConfig) {
+		m.Username = "claude"
+		m.Password = "wrong"
+		m.CAFile = caFile
+	})
+	if err := publisher.Publish(analyzer.StatusPlanReady, "Plan ready", "session-123", "/home/user/my-app"); err == nil {
+		t.Error("Expected error for wrong credentials")
+	}
+
+	// The broker certificate must be trusted
+	publisher = newTestPublisher("ssl://"+addr, func(m *config.MQTTConfig) {
+		m.Username = "claude"
+		m.Password = "s3cret"
+	})
+	if err := publisher.Publish(analyzer.StatusPlanReady, "Plan ready", "session-123", "/home/user/my-app"); err == nil {
+		t.Error("Expected error for an untrusted broker certificate")
+	}
+}
+
+func TestPublishDisabled(t *testing.T) {
+	publisher := newTestPublisher("tcp://127.0.0.1:1", func(m *config.MQTTConfig) { m.Enabled = false })
+
+	if err := publisher.Publish(analyzer.StatusTaskComplete, "Done", "session-123", "/test"); err != nil {
+		t.Errorf("Expected disabled publisher to skip, got %v", err)
+	}
+	if err := publisher.Clear("session-123", "/test"); err != nil {
+		t.Errorf("Expected disabled publisher to skip, got %v", err)
+	}
+}
+
+func TestTopic(t *testing.T) {
+	tests := []struct {
+		prefix    string
+		cwd       string
+		sessionID string
+		want      string
+	}{
+		{"claude", "/home/user/my-app", "abc-123", "claude/my-app/abc-123/status"},
+		{"home/office", "/home/user/my-app", "abc-123", "home/office/my-app/abc-123/status"},
+		{"claude", "/home/user/c++", "a#b/c", "claude/c__/a_b_c/status"},
+		{"claude", "", "", "claude/unknown/unknown/status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := topic(tt.prefix, tt.cwd, tt.sessionID); got != tt.want {
+				t.Errorf("topic(%q, %q, %q) = %q, want %q", tt.prefix, tt.cwd, tt.sessionID, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package notifier
+
+import (
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+)
+
+const (
+	// ActionListenerCommand is the hidden CLI command the D-Bus notifier starts in the
+	// background to handle the actions of a notification
+	ActionListenerCommand = "dbus-actions"
+
+	// ActionListenTimeout is how long the action listener waits for the user to act
+	ActionListenTimeout = 30 * time.Minute
+)
+
+// Urgency levels of the D-Bus "urgency" hint and the kitty OSC 99 "u" key
+const (
+	urgencyNormal   byte = 1
+	urgencyCritical byte = 2
+)
+
+// statusUrgency returns critical for statuses that block Claude until the user acts,
+// normal otherwise
+func statusUrgency(status analyzer.Status) byte {
+	switch status {
+	case analyzer.StatusQuestion, analyzer.StatusAPIError:
+		return urgencyCritical
+	default:
+		return urgencyNormal
+	}
+}
+
+// dbusCategory returns the vendor category hint for a status, e.g.
+// "x-claude-notifications.question"
+func dbusCategory(status analyzer.Status) string {
+	return "x-claude-notifications." + string(status)
+}
+
+// dbusExpireTimeout converts the desktop expire setting to the Notify expire_timeout in
+// milliseconds: -1 lets the server decide, 0 keeps the notification until dismissed
+func dbusExpireTimeout(expire string) int32 {
+	if expire == "" {
+		return -1
+	}
+	d, err := time.ParseDuration(expire)
+	if err != nil || d < 0 {
+		return -1
+	}
+	if d.Milliseconds() > int64(^uint32(0)>>1) {
+		return 0
+	}
+	return int32(d.Milliseconds())
+}
@@ -0,0 +1,277 @@
+//go:build linux
+
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/777genius/claude-notifications/internal/platform"
+)
+
+const (
+	dbusName = "org.freedesktop.Notifications"
+	dbusPath = dbus.ObjectPath("/org/freedesktop/Notifications")
+
+	// dbusAppName is the application name shown by the notification server
+	dbusAppName = "Claude Code"
+
+	// dbusCallTimeout bounds each call to the notification server
+	dbusCallTimeout = 5 * time.Second
+
+	// Action keys; "default" is also invoked by clicking the notification itself
+	actionFocus   = "default"
+	actionDismiss = "dismiss"
+
+	// dbusRecordPrefix prefixes the per-session files remembering the notification ID
+	dbusRecordPrefix = "claude-dbus-notification-"
+
+	// dbusRecordMaxAge is the age in seconds after which session records are removed
+	dbusRecordMaxAge = 24 * 60 * 60
+)
+
+// startActionListener starts the background process that handles the actions of a
+// notification and returns its PID (replaced in tests)
+var startActionListener = spawnActionListener
+
+// dbusRecord is what the notifier remembers about a session's notification
+type dbusRecord struct {
+	id       uint32 // notification ID, passed as replaces_id for the next notification
+	listener int    // PID of the action listener (0 = none)
+}
+
+// sendWithDBus sends a notification through the org.freedesktop.Notifications D-Bus
+// interface. It replaces the session's previous notification and, when the server
+// supports actions, offers "Focus terminal" and "Dismiss". A silent notification asks
+// the server not to play its own sound.
+func (n *Notifier) sendWithDBus(status analyzer.Status, title, message, appIcon, sessionID string, silent bool) error {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return fmt.Errorf("failed to connect to session bus: %w", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbusCallTimeout)
+	defer cancel()
+	obj := conn.Object(dbusName, dbusPath)
+
+	window := os.Getenv("WINDOWID")
+	var actions []string
+	if dbusSupportsActions(ctx, obj) {
+		if n.cfg.Notifications.Desktop.ClickToFocus && window != "" && focusCommand(window) != nil {
+			actions = append(actions, actionFocus, "Focus terminal")
+		}
+		actions = append(actions, actionDismiss, "Dismiss")
+	}
+
+	record := n.loadDBusRecord(sessionID)
+	hints := map[string]dbus.Variant{
+		"urgency":  dbus.MakeVariant(statusUrgency(status)),
+		"category": dbus.MakeVariant(dbusCategory(status)),
+	}
+	if silent || n.cfg.Notifications.Desktop.Sound {
+		// Quiet hours, or the plugin plays the status sound itself
+		hints["suppress-sound"] = dbus.MakeVariant(true)
+	}
+
+	var id uint32
+	err = obj.CallWithContext(ctx, dbusName+".Notify", 0,
+		dbusAppName, record.id, appIcon, title, message, actions, hints,
+		dbusExpireTimeout(n.cfg.Notifications.Desktop.Expire)).Store(&id)
+	if err != nil {
+		return fmt.Errorf("notify failed: %w", err)
+	}
+
+	// A listener already waiting on this ID also handles the replacement
+	listener := record.listener
+	if len(actions) > 0 && (id != record.id || !processAlive(listener)) {
+		pid, err := startActionListener(id, window)
+		if err != nil {
+			logging.Warn("Failed to start D-Bus action listener: %v", err)
+			listener = 0
+		} else {
+			listener = pid
+		}
+	}
+	n.saveDBusRecord(sessionID, dbusRecord{id: id, listener: listener})
+
+	logging.Debug("Desktop notification sent via D-Bus: id=%d, replaces=%d, title=%s", id, record.id, title)
+	return nil
+}
+
+// dbusSupportsActions reports whether the notification server advertises actions
+func dbusSupportsActions(ctx context.Context, obj dbus.BusObject) bool {
+	var caps []string
+	if err := obj.CallWithContext(ctx, dbusName+".GetCapabilities", 0).Store(&caps); err != nil {
+		logging.Debug("D-Bus GetCapabilities failed: %v", err)
+		return false
+	}
+	for _, c := range caps {
+		if c == "actions" {
+			return true
+		}
+	}
+	return false
+}
+
+// dbusRecordPath returns the file remembering a session's notification, or "" if the
+// session ID is empty
+func (n *Notifier) dbusRecordPath(sessionID string) string {
+	if sessionID == "" {
+		return ""
+	}
+	return filepath.Join(n.tempDir, dbusRecordPrefix+sanitizeID(sessionID))
+}
+
+// loadDBusRecord returns the session's last notification, or a zero record
+func (n *Notifier) loadDBusRecord(sessionID string) dbusRecord {
+	path := n.dbusRecordPath(sessionID)
+	if path == "" {
+		return dbusRecord{}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return dbusRecord{}
+	}
+
+	var record dbusRecord
+	fields := strings.Fields(string(data))
+	if len(fields) > 0 {
+		if id, err := strconv.ParseUint(fields[0], 10, 32); err == nil {
+			record.id = uint32(id)
+		}
+	}
+	if len(fields) > 1 {
+		record.listener, _ = strconv.Atoi(fields[1])
+	}
+	return record
+}
+
+// saveDBusRecord remembers the session's notification and removes stale records
+func (n *Notifier) saveDBusRecord(sessionID string, record dbusRecord) {
+	path := n.dbusRecordPath(sessionID)
+	if path == "" {
+		return
+	}
+	data := fmt.Sprintf("%d %d\n", record.id, record.listener)
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		logging.Warn("Failed to save D-Bus notification ID: %v", err)
+	}
+	_ = platform.CleanupOldFiles(n.tempDir, dbusRecordPrefix+"*", dbusRecordMaxAge)
+}
+
+// processAlive reports whether a process with the given PID exists
+func processAlive(pid int) bool {
+	return pid > 0 && syscall.Kill(pid, 0) == nil
+}
+
+// spawnActionListener starts "claude-notifications dbus-actions" detached from the
+// hook, so it keeps waiting for the user after the hook exits
+func spawnActionListener(id uint32, window string) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+
+	cmd := exec.Command(exe, ActionListenerCommand, "--id", strconv.FormatUint(uint64(id), 10), "--window", window)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	pid := cmd.Process.Pid
+	_ = cmd.Process.Release()
+	return pid, nil
+}
+
+// ListenForActions waits for the user to act on notification id. "Focus terminal" calls
+// focus; either action closes the notification. It returns when an action was handled,
+// the notification was closed or ctx is done.
+func ListenForActions(ctx context.Context, id uint32, focus func() error) error {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return fmt.Errorf("failed to connect to session bus: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.AddMatchSignalContext(ctx,
+		dbus.WithMatchObjectPath(dbusPath),
+		dbus.WithMatchInterface(dbusName),
+	); err != nil {
+		return fmt.Errorf("failed to subscribe to notification signals: %w", err)
+	}
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case sig, ok := <-signals:
+			if !ok {
+				return fmt.Errorf("session bus connection closed")
+			}
+			if len(sig.Body) < 2 {
+				continue
+			}
+			if sigID, _ := sig.Body[0].(uint32); sigID != id {
+				continue
+			}
+
+			switch sig.Name {
+			case dbusName + ".ActionInvoked":
+				key, _ := sig.Body[1].(string)
+				logging.Debug("D-Bus action invoked: id=%d, action=%s", id, key)
+				if key == actionFocus {
+					if err := focus(); err != nil {
+						logging.Warn("Failed to focus terminal: %v", err)
+					}
+				}
+				// Not every server closes the notification after an action
+				closeCtx, cancel := context.WithTimeout(context.Background(), dbusCallTimeout)
+				err := conn.Object(dbusName, dbusPath).CallWithContext(closeCtx, dbusName+".CloseNotification", 0, id).Err
+				cancel()
+				if err != nil {
+					logging.Debug("D-Bus CloseNotification failed: %v", err)
+				}
+				return nil
+			case dbusName + ".NotificationClosed":
+				return nil
+			}
+		}
+	}
+}
+
+// FocusTerminalWindow activates the X11 window of the terminal with xdotool or wmctrl
+func FocusTerminalWindow(window string) error {
+	cmd := focusCommand(window)
+	if cmd == nil {
+		return fmt.Errorf("xdotool or wmctrl is required to focus window %q", window)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w, output: %s", filepath.Base(cmd.Path), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// focusCommand returns the command that activates window, or nil if neither xdotool
+// nor wmctrl is installed
+func focusCommand(window string) *exec.Cmd {
+	if path, err := exec.LookPath("xdotool"); err == nil {
+		return exec.Command(path, "windowactivate", window)
+	}
+	if path, err := exec.LookPath("wmctrl"); err == nil {
+		return exec.Command(path, "-i", "-a", window)
+	}
+	return nil
+}
@@ -0,0 +1,446 @@
+//go:build linux
+
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+const busConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:path=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+func TestMain(m *testing.M) {
+	// Tests that reach a real notification server must not start the test binary as
+	// an action listener
+	startActionListener = func(id uint32, window string) (int, error) { return 0, nil }
+	os.Exit(m.Run())
+}
+
+// startPrivateBus starts a dbus-daemon for the test and points the session bus at it
+func startPrivateBus(t *testing.T) {
+	t.Helper()
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "bus")
+	configPath := filepath.Join(dir, "bus.conf")
+	if err := os.WriteFile(configPath, []byte(fmt.Sprintf(busConfig, socket)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(daemon, "--config-file="+configPath, "--nofork", "--nopidfile")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(socket); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("dbus-daemon did not create its socket")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:path="+socket)
+}
+
+// notifyCall is one Notify call received by fakeNotificationServer
+type notifyCall struct {
+	appName    string
+	replacesID uint32
+	appIcon    string
+	summary    string
+	body       string
+	actions    []string
+	hints      map[string]dbus.Variant
+	expire     int32
+}
+
+// fakeNotificationServer implements org.freedesktop.Notifications on the private bus
+type fakeNotificationServer struct {
+	conn         *dbus.Conn
+	capabilities []string
+
+	mu     sync.Mutex
+	nextID uint32
+	calls  []notifyCall
+	closed []uint32
+}
+
+func newFakeNotificationServer(t *testing.T, capabilities ...string) *fakeNotificationServer {
+	t.Helper()
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		t.Fatalf("Failed to connect to private bus: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	s := &fakeNotificationServer{conn: conn, capabilities: capabilities}
+	if err := conn.Export(s, dbusPath, dbusName); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := conn.RequestName(dbusName, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("Failed to own %s: %v (reply %v)", dbusName, err, reply)
+	}
+	return s
+}
+
+func (s *fakeNotificationServer) GetCapabilities() ([]string, *dbus.Error) {
+	return s.capabilities, nil
+}
+
+func (s *fakeNotificationServer) Notify(appName string, replacesID uint32, appIcon, summary, body string,
+	actions []string, hints map[string]dbus.Variant, expire int32) (uint32, *dbus.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = append(s.calls, notifyCall{appName, replacesID, appIcon, summary, body, actions, hints, expire})
+	if replacesID != 0 {
+		return replacesID, nil
+	}
+	s.nextID++
+	return s.nextID, nil
+}
+
+func (s *fakeNotificationServer) CloseNotification(id uint32) *dbus.Error {
+	s.mu.Lock()
+	s.closed = append(s.closed, id)
+	s.mu.Unlock()
+
+	_ = s.conn.Emit(dbusPath, dbusName+".NotificationClosed", id, uint32(3))
+	return nil
+}
+
+func (s *fakeNotificationServer) notifyCalls() []notifyCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]notifyCall(nil), s.calls...)
+}
+
+func (s *fakeNotificationServer) closedIDs() []uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]uint32(nil), s.closed...)
+}
+
+// listenerCall records a startActionListener call
+type listenerCall struct {
+	id     uint32
+	window string
+}
+
+// recordActionListeners replaces startActionListener; the returned "listener" is
+// the test process itself, so it counts as alive
+func recordActionListeners(t *testing.T) *[]listenerCall {
+	t.Helper()
+	var calls []listenerCall
+	original := startActionListener
+	startActionListener = func(id uint32, window string) (int, error) {
+		calls = append(calls, listenerCall{id, window})
+		return os.Getpid(), nil
+	}
+	t.Cleanup(func() { startActionListener = original })
+	return &calls
+}
+
+func newDBusNotifier(t *testing.T, configure func(d *config.DesktopConfig)) *Notifier {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Notifications.Desktop.Method = "dbus"
+	cfg.Notifications.Desktop.Sound = false
+	if configure != nil {
+		configure(&cfg.Notifications.Desktop)
+	}
+	n := New(cfg)
+	n.tempDir = t.TempDir()
+	return n
+}
+
+// installFakeXdotool puts an xdotool on PATH that records its arguments
+func installFakeXdotool(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	out := filepath.Join(dir, "args")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$@\" > %s\n", out)
+	if err := os.WriteFile(filepath.Join(dir, "xdotool"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	return out
+}
+
+func TestSendWithDBus_HintsAndActions(t *testing.T) {
+	startPrivateBus(t)
+	server := newFakeNotificationServer(t, "actions", "body")
+	listeners := recordActionListeners(t)
+	installFakeXdotool(t)
+	t.Setenv("WINDOWID", "4194311")
+
+	n := newDBusNotifier(t, func(d *config.DesktopConfig) {
+		d.Expire = "10s"
+		d.Sound = true
+	})
+	defer n.Close()
+
+	if err := n.SendDesktop(analyzer.StatusQuestion, "[bold-cat|main] Which database?", "session-1"); err != nil {
+		t.Fatalf("SendDesktop failed: %v", err)
+	}
+
+	calls := server.notifyCalls()
+	if len(calls) != 1 {
+		t.Fatalf("Expected 1 Notify call, got %d", len(calls))
+	}
+	c := calls[0]
+	if c.appName != "Claude Code" || c.summary != "❓ Question [bold-cat] main" || c.body != "Which database?" {
+		t.Errorf("Unexpected notification: app=%q summary=%q body=%q", c.appName, c.summary, c.body)
+	}
+	if c.replacesID != 0 || c.expire != 10000 {
+		t.Errorf("Expected replaces_id 0 and expire 10000, got %d and %d", c.replacesID, c.expire)
+	}
+	wantActions := []string{"default", "Focus terminal", "dismiss", "Dismiss"}
+	if !reflect.DeepEqual(c.actions, wantActions) {
+		t.Errorf("Expected actions %v, got %v", wantActions, c.actions)
+	}
+	if u, _ := c.hints["urgency"].Value().(byte); u != urgencyCritical {
+		t.Errorf("Expected critical urgency, got %v", c.hints["urgency"])
+	}
+	if cat, _ := c.hints["category"].Value().(string); cat != "x-claude-notifications.question" {
+		t.Errorf("Unexpected category hint %v", c.hints["category"])
+	}
+	if s, _ := c.hints["suppress-sound"].Value().(bool); !s {
+		t.Errorf("Expected suppress-sound when the plugin plays sounds, got %v", c.hints["suppress-sound"])
+	}
+
+	if want := []listenerCall{{1, "4194311"}}; !reflect.DeepEqual(*listeners, want) {
+		t.Errorf("Expected listener %v, got %v", want, *listeners)
+	}
+}
+
+func TestSendWithDBus_SilentSuppressesServerSound(t *testing.T) {
+	startPrivateBus(t)
+	server := newFakeNotificationServer(t, "body")
+	recordActionListeners(t)
+
+	// The plugin plays no sound, so the server may play its own, except when silent
+	n := newDBusNotifier(t, nil)
+	defer n.Close()
+
+	if err := n.SendDesktop(analyzer.StatusQuestion, "Which database?", "session-1"); err != nil {
+		t.Fatalf("SendDesktop failed: %v", err)
+	}
+	if err := n.SendDesktopSilent(analyzer.StatusQuestion, "Which database?", "session-2"); err != nil {
+		t.Fatalf("SendDesktopSilent failed: %v", err)
+	}
+
+	calls := server.notifyCalls()
+	if len(calls) != 2 {
+		t.Fatalf("Expected 2 Notify calls, got %d", len(calls))
+	}
+	if _, ok := calls[0].hints["suppress-sound"]; ok {
+		t.Errorf("Expected no suppress-sound hint, got %v", calls[0].hints["suppress-sound"])
+	}
+	if s, _ := calls[1].hints["suppress-sound"].Value().(bool); !s {
+		t.Errorf("Expected suppress-sound for a silent notification, got %v", calls[1].hints["suppress-sound"])
+	}
+}
+
+func TestSendWithDBus_ReplacesSessionNotification(t *testing.T) {
+	startPrivateBus(t)
+	server := newFakeNotificationServer(t, "actions")
+	listeners := recordActionListeners(t)
+	t.Setenv("WINDOWID", "")
+
+	n := newDBusNotifier(t, nil)
+	defer n.Close()
+
+	_ = n.SendDesktop(analyzer.StatusTaskComplete, "[bold-cat] Done", "session-1")
+	_ = n.SendDesktop(analyzer.StatusQuestion, "[bold-cat] Which database?", "session-1")
+	_ = n.SendDesktop(analyzer.StatusTaskComplete, "[swift-eagle] Done", "session-2")
+
+	calls := server.notifyCalls()
+	if len(calls) != 3 {
+		t.Fatalf("Expected 3 Notify calls, got %d", len(calls))
+	}
+	if calls[0].replacesID != 0 || calls[1].replacesID != 1 || calls[2].replacesID != 0 {
+		t.Errorf("Expected replaces_id 0, 1, 0; got %d, %d, %d", calls[0].replacesID, calls[1].replacesID, calls[2].replacesID)
+	}
+	// Without WINDOWID there is nothing to focus
+	if want := []string{"dismiss", "Dismiss"}; !reflect.DeepEqual(calls[0].actions, want) {
+		t.Errorf("Expected actions %v, got %v", want, calls[0].actions)
+	}
+	if u, _ := calls[0].hints["urgency"].Value().(byte); u != urgencyNormal {
+		t.Errorf("Expected normal urgency for task_complete, got %v", calls[0].hints["urgency"])
+	}
+	if calls[0].expire != -1 {
+		t.Errorf("Expected server default expire -1, got %d", calls[0].expire)
+	}
+
+	// The listener of notification 1 is still running and handles its replacement
+	if want := []listenerCall{{1, ""}, {2, ""}}; !reflect.DeepEqual(*listeners, want) {
+		t.Errorf("Expected listeners %v, got %v", want, *listeners)
+	}
+}
+
+func TestSendWithDBus_NoActionsCapability(t *testing.T) {
+	startPrivateBus(t)
+	server := newFakeNotificationServer(t, "body")
+	listeners := recordActionListeners(t)
+
+	n := newDBusNotifier(t, func(d *config.DesktopConfig) { d.Expire = "0s" })
+	defer n.Close()
+
+	if err := n.SendDesktop(analyzer.StatusAPIError, "[bold-cat] Rate limited", "session-1"); err != nil {
+		t.Fatalf("SendDesktop failed: %v", err)
+	}
+
+	calls := server.notifyCalls()
+	if len(calls) != 1 || len(calls[0].actions) != 0 || calls[0].expire != 0 {
+		t.Fatalf("Expected one notification without actions that never expires, got %+v", calls)
+	}
+	if len(*listeners) != 0 {
+		t.Errorf("Expected no action listener, got %v", *listeners)
+	}
+}
+
+func TestSendWithDBus_NoServerFallsBack(t *testing.T) {
+	startPrivateBus(t)
+
+	n := newDBusNotifier(t, nil)
+	defer n.Close()
+
+	if err := n.sendWithDBus(analyzer.StatusTaskComplete, "Done", "", "", "session-1", false); err == nil {
+		t.Error("Expected an error without a notification server")
+	}
+}
+
+func TestListenForActions(t *testing.T) {
+	tests := []struct {
+		name      string
+		signal    string
+		args      []interface{}
+		wantFocus int
+		wantClose bool
+	}{
+		{"focus", "ActionInvoked", []interface{}{uint32(7), "default"}, 1, true},
+		{"dismiss", "ActionInvoked", []interface{}{uint32(7), "dismiss"}, 0, true},
+		{"closed", "NotificationClosed", []interface{}{uint32(7), uint32(2)}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			startPrivateBus(t)
+			server := newFakeNotificationServer(t, "actions")
+
+			var mu sync.Mutex
+			focused := 0
+			done := make(chan error, 1)
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				done <- ListenForActions(ctx, 7, func() error {
+					mu.Lock()
+					focused++
+					mu.Unlock()
+					return nil
+				})
+			}()
+
+			// Signals for other notifications are ignored; repeat until the listener has subscribed
+			ticker := time.NewTicker(20 * time.Millisecond)
+			defer ticker.Stop()
+			for {
+				_ = server.conn.Emit(dbusPath, dbusName+".ActionInvoked", uint32(8), "default")
+				_ = server.conn.Emit(dbusPath, dbusName+"."+tt.signal, tt.args...)
+				select {
+				case err := <-done:
+					if err != nil {
+						t.Fatalf("ListenForActions failed: %v", err)
+					}
+				case <-ticker.C:
+					continue
+				}
+				break
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if focused != tt.wantFocus {
+				t.Errorf("Expected focus to be called %d times, got %d", tt.wantFocus, focused)
+			}
+			closed := server.closedIDs()
+			if tt.wantClose && !reflect.DeepEqual(closed, []uint32{7}) {
+				t.Errorf("Expected notification 7 to be closed, got %v", closed)
+			}
+			if !tt.wantClose && len(closed) != 0 {
+				t.Errorf("Expected no CloseNotification call, got %v", closed)
+			}
+		})
+	}
+}
+
+func TestFocusTerminalWindow(t *testing.T) {
+	out := installFakeXdotool(t)
+
+	if err := FocusTerminalWindow("4194311"); err != nil {
+		t.Fatalf("FocusTerminalWindow failed: %v", err)
+	}
+	args, err := os.ReadFile(out)
+	if err != nil || string(args) != "windowactivate 4194311\n" {
+		t.Errorf("Expected xdotool windowactivate 4194311, got %q (%v)", args, err)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if err := FocusTerminalWindow("4194311"); err == nil {
+		t.Error("Expected an error without xdotool or wmctrl")
+	}
+}
+
+func TestDBusExpireTimeout(t *testing.T) {
+	tests := map[string]int32{
+		"":      -1,
+		"10s":   10000,
+		"1m30s": 90000,
+		"0s":    0,
+		"bogus": -1,
+		"-5s":   -1,
+	}
+	for expire, want := range tests {
+		if got := dbusExpireTimeout(expire); got != want {
+			t.Errorf("dbusExpireTimeout(%q) = %d, want %d", expire, got, want)
+		}
+	}
+}
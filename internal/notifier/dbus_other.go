@@ -0,0 +1,25 @@
+//go:build !linux
+
+package notifier
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+)
+
+// sendWithDBus returns an error on non-Linux platforms
+func (n *Notifier) sendWithDBus(status analyzer.Status, title, message, appIcon, sessionID string, silent bool) error {
+	return fmt.Errorf("D-Bus notifications are only available on Linux")
+}
+
+// ListenForActions returns an error on non-Linux platforms
+func ListenForActions(ctx context.Context, id uint32, focus func() error) error {
+	return fmt.Errorf("D-Bus notifications are only available on Linux")
+}
+
+// FocusTerminalWindow returns an error on non-Linux platforms
+func FocusTerminalWindow(window string) error {
+	return fmt.Errorf("focusing a window by ID is only available on Linux")
+}
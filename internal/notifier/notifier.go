@@ -2,35 +2,74 @@ package notifier
 
 import (
 	"fmt"
-	"os"
+	"io"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gen2brain/beeep"
 
 	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/audio"
 	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/errorhandler"
 	"github.com/777genius/claude-notifications/internal/logging"
 	"github.com/777genius/claude-notifications/internal/platform"
 )
 
+// soundPlaybackTimeout bounds how long Close waits for a sound to finish
+const soundPlaybackTimeout = 30 * time.Second
+
+// soundPlayer plays sound files (implemented by *audio.Player)
+type soundPlayer interface {
+	Play(path string) error
+	Close() error
+}
+
 // Notifier sends desktop notifications
 type Notifier struct {
-	cfg *config.Config
+	cfg     *config.Config
+	tempDir string                         // D-Bus: per-session notification IDs
+	openTTY func() (io.WriteCloser, error) // Escape sequence methods: the terminal to write to
+
+	// Sound playback (player is created lazily on first sound)
+	newPlayer  func(deviceName string, volume float64) (soundPlayer, error)
+	player     soundPlayer
+	playerMu   sync.Mutex
+	playbackWg sync.WaitGroup
 }
 
 // New creates a new notifier
 func New(cfg *config.Config) *Notifier {
 	return &Notifier{
-		cfg: cfg,
+		cfg:     cfg,
+		tempDir: platform.TempDir(),
+		openTTY: openDevTTY,
+		newPlayer: func(deviceName string, volume float64) (soundPlayer, error) {
+			return audio.NewPlayer(deviceName, volume)
+		},
 	}
 }
 
 // SendDesktop sends a desktop notification using the configured method
-// Methods: "osc9", "terminal-notifier", "beeep", "auto" (default)
+// Methods: "osc9", "osc99", "osc777", "osc", "terminal-notifier", "dbus", "beeep", "auto" (default)
+// "osc" picks the escape sequence from TERM/TERM_PROGRAM; all escape sequences pass through tmux and screen
 // On macOS with clickToFocus enabled and method=auto, uses terminal-notifier for click-to-focus support
-func (n *Notifier) SendDesktop(status analyzer.Status, message string) error {
+// On Linux with method=auto, uses D-Bus so a session's notification replaces its previous one
+// If sound is enabled, the status sound is played in the background (see Close)
+func (n *Notifier) SendDesktop(status analyzer.Status, message, sessionID string) error {
+	return n.sendDesktop(status, message, sessionID, false)
+}
+
+// SendDesktopSilent sends a desktop notification like SendDesktop, without sound
+// (used during quiet hours)
+func (n *Notifier) SendDesktopSilent(status analyzer.Status, message, sessionID string) error {
+	return n.sendDesktop(status, message, sessionID, true)
+}
+
+// sendDesktop implements SendDesktop and SendDesktopSilent
+func (n *Notifier) sendDesktop(status analyzer.Status, message, sessionID string, silent bool) error {
 	if !n.cfg.IsDesktopEnabled() {
 		logging.Debug("Desktop notifications disabled, skipping")
 		return nil
@@ -63,14 +102,32 @@ func (n *Notifier) SendDesktop(status analyzer.Status, message string) error {
 		appIcon = ""
 	}
 
+	// Play sound in the background; Close() waits for playback to finish
+	if n.cfg.Notifications.Desktop.Sound && !silent {
+		n.playSoundAsync(statusInfo.Sound)
+	}
+
 	method := n.cfg.Notifications.Desktop.Method
 
 	// Handle explicit method selection
+	if method == "osc" {
+		method = detectOSCMethod()
+		logging.Debug("Detected terminal notification method: %s", method)
+	}
+
 	switch method {
 	case "osc9":
 		// OSC9: Terminal escape sequence notification (iTerm2, kitty, etc.)
 		return n.sendWithOSC9(title, cleanMessage)
 
+	case "osc99":
+		// OSC99: kitty notification with title, body, urgency and per-session replacement
+		return n.sendWithOSC99(status, title, cleanMessage, sessionID)
+
+	case "osc777":
+		// OSC777: rxvt-unicode, foot, WezTerm and Ghostty notification with title and body
+		return n.sendWithOSC777(title, cleanMessage)
+
 	case "terminal-notifier":
 		// terminal-notifier: macOS only with click-to-focus
 		if platform.IsMacOS() && IsTerminalNotifierAvailable() {
@@ -83,6 +140,18 @@ func (n *Notifier) SendDesktop(status analyzer.Status, message string) error {
 		logging.Debug("terminal-notifier not available or not on macOS, falling back to beeep")
 		return n.sendWithBeeep(title, cleanMessage, appIcon)
 
+	case "dbus":
+		// dbus: Linux notification server with urgency, actions and per-session replacement
+		if platform.IsLinux() {
+			if err := n.sendWithDBus(status, title, cleanMessage, appIcon, sessionID, silent); err != nil {
+				logging.Warn("D-Bus notification failed, falling back to beeep: %v", err)
+				return n.sendWithBeeep(title, cleanMessage, appIcon)
+			}
+			return nil
+		}
+		logging.Debug("D-Bus notifications are only available on Linux, falling back to beeep")
+		return n.sendWithBeeep(title, cleanMessage, appIcon)
+
 	case "beeep":
 		// beeep: Cross-platform desktop notifications
 		return n.sendWithBeeep(title, cleanMessage, appIcon)
@@ -104,7 +173,16 @@ func (n *Notifier) SendDesktop(status analyzer.Status, message string) error {
 			}
 		}
 
-		// Standard path: beeep (Windows, Linux, macOS fallback)
+		// Linux: Try the D-Bus notification server for urgency, actions and replacement
+		if platform.IsLinux() {
+			if err := n.sendWithDBus(status, title, cleanMessage, appIcon, sessionID, silent); err != nil {
+				logging.Debug("D-Bus notification failed, falling back to beeep: %v", err)
+			} else {
+				return nil
+			}
+		}
+
+		// Standard path: beeep (Windows, Linux fallback, macOS fallback)
 		return n.sendWithBeeep(title, cleanMessage, appIcon)
 	}
 }
@@ -189,33 +267,86 @@ func (n *Notifier) sendWithOSC9(title, message string) error {
 	}
 
 	// Truncate to prevent overly long notifications
-	if len(notifyText) > 200 {
-		notifyText = notifyText[:197] + "..."
-	}
-
-	// Write OSC9 escape sequence to /dev/tty
-	// Format: ESC ] 9 ; message ESC \
-	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
-	if err != nil {
-		logging.Error("Failed to open /dev/tty for OSC9: %v", err)
-		return fmt.Errorf("failed to open /dev/tty: %w", err)
-	}
-	defer tty.Close()
+	notifyText = truncateRunes(oscText(notifyText), oscMaxMessage)
 
 	// OSC9 sequence: \033]9;message\033\\
-	osc9 := fmt.Sprintf("\033]9;%s\033\\", notifyText)
-	if _, err := tty.WriteString(osc9); err != nil {
-		logging.Error("Failed to write OSC9 sequence: %v", err)
-		return fmt.Errorf("failed to write OSC9: %w", err)
+	if err := n.writeTTY("\033]9;" + notifyText + st); err != nil {
+		return err
 	}
 
 	logging.Debug("Desktop notification sent via OSC9: title=%s", title)
 	return nil
 }
 
-// Close is a no-op (kept for interface compatibility)
+// playSoundAsync plays a sound file in a goroutine tracked by Close()
+func (n *Notifier) playSoundAsync(path string) {
+	if path == "" {
+		return
+	}
+	if !platform.FileExists(path) {
+		logging.Warn("Sound file not found: %s", path)
+		return
+	}
+
+	n.playbackWg.Add(1)
+	errorhandler.SafeGo(func() {
+		defer n.playbackWg.Done()
+
+		if err := n.playSound(path); err != nil {
+			logging.Warn("Failed to play sound %s: %v", path, err)
+			return
+		}
+		logging.Debug("Sound played: %s (volume: %.2f)", path, n.cfg.Notifications.Desktop.Volume)
+	})
+}
+
+// PlaySound plays a sound file synchronously using the configured device and volume.
+// This is the same playback path used for hook notifications (e.g. for sound previews).
+func (n *Notifier) PlaySound(path string) error {
+	return n.playSound(path)
+}
+
+// playSound plays a sound file synchronously using the configured device and volume
+func (n *Notifier) playSound(path string) error {
+	n.playerMu.Lock()
+	defer n.playerMu.Unlock()
+
+	if n.player == nil {
+		desktop := n.cfg.Notifications.Desktop
+		player, err := n.newPlayer(desktop.AudioDevice, desktop.Volume)
+		if err != nil {
+			return err
+		}
+		n.player = player
+	}
+
+	return n.player.Play(path)
+}
+
+// Close waits for in-flight sound playback (with timeout) and releases the audio player
 func (n *Notifier) Close() error {
-	return nil
+	done := make(chan struct{})
+	go func() {
+		n.playbackWg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(soundPlaybackTimeout):
+		logging.Warn("Sound playback did not finish within %v", soundPlaybackTimeout)
+		return nil
+	}
+
+	n.playerMu.Lock()
+	defer n.playerMu.Unlock()
+
+	if n.player == nil {
+		return nil
+	}
+	err := n.player.Close()
+	n.player = nil
+	return err
 }
 
 // extractSessionInfo extracts session name and git branch from message
@@ -252,3 +383,13 @@ func extractSessionInfo(message string) (sessionName, gitBranch, cleanMessage st
 
 	return sessionName, gitBranch, cleanMessage
 }
+
+// sanitizeID makes a session ID safe for file names and notification identifiers
+func sanitizeID(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '_'
+	}, id)
+}
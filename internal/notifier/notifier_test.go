@@ -1,10 +1,13 @@
 package notifier
 
 import (
+	"errors"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -137,7 +140,7 @@ func TestSendDesktopRestoresAppName(t *testing.T) {
 	n := New(cfg)
 
 	// Call SendDesktop - should not change AppName since notifications are disabled
-	_ = n.SendDesktop(analyzer.StatusTaskComplete, "test message")
+	_ = n.SendDesktop(analyzer.StatusTaskComplete, "test message", "")
 
 	// Verify AppName is unchanged (because we skipped notification)
 	if beeep.AppName != testAppName {
@@ -150,7 +153,7 @@ func TestSendDesktopRestoresAppName(t *testing.T) {
 
 	// This will attempt to send a real notification and may fail in CI,
 	// but the important thing is that AppName is restored afterward
-	_ = n.SendDesktop(analyzer.StatusTaskComplete, "test message")
+	_ = n.SendDesktop(analyzer.StatusTaskComplete, "test message", "")
 
 	// Verify AppName is restored to testAppName after the defer runs
 	if beeep.AppName != testAppName {
@@ -172,7 +175,7 @@ func TestSendDesktop_ClickToFocusDisabled(t *testing.T) {
 	// Should not panic and should use beeep path
 	// We can't easily verify which path was taken without mocking,
 	// but we can verify it doesn't crash
-	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test-session] Task done")
+	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test-session] Task done", "")
 	// Error is acceptable in CI environment where notifications may not work
 	_ = err
 }
@@ -192,7 +195,7 @@ func TestSendDesktop_WithTerminalBundleIDOverride(t *testing.T) {
 	}
 
 	// SendDesktop should work without panic
-	err := n.SendDesktop(analyzer.StatusTaskComplete, "Test message")
+	err := n.SendDesktop(analyzer.StatusTaskComplete, "Test message", "")
 	_ = err // Error acceptable in CI
 }
 
@@ -269,7 +272,7 @@ func TestSendDesktop_AllStatuses(t *testing.T) {
 	for _, status := range statuses {
 		t.Run(string(status), func(t *testing.T) {
 			// Should not panic for any status
-			err := n.SendDesktop(status, "[test] Message for "+string(status))
+			err := n.SendDesktop(status, "[test] Message for "+string(status), "")
 			// Error is acceptable (notifications may not work in CI)
 			_ = err
 		})
@@ -283,7 +286,7 @@ func TestSendDesktop_Disabled(t *testing.T) {
 	n := New(cfg)
 
 	// Should return nil without doing anything
-	err := n.SendDesktop(analyzer.StatusTaskComplete, "test message")
+	err := n.SendDesktop(analyzer.StatusTaskComplete, "test message", "")
 	if err != nil {
 		t.Errorf("Expected nil error when disabled, got: %v", err)
 	}
@@ -296,7 +299,7 @@ func TestSendDesktop_UnknownStatus(t *testing.T) {
 	n := New(cfg)
 
 	// Should return error for unknown status
-	err := n.SendDesktop(analyzer.Status("unknown_status"), "test message")
+	err := n.SendDesktop(analyzer.Status("unknown_status"), "test message", "")
 	if err == nil {
 		t.Error("Expected error for unknown status, got nil")
 	}
@@ -311,7 +314,7 @@ func TestSendDesktop_WithSessionName(t *testing.T) {
 	n := New(cfg)
 
 	// Test with session name
-	err := n.SendDesktop(analyzer.StatusTaskComplete, "[my-session] Task completed")
+	err := n.SendDesktop(analyzer.StatusTaskComplete, "[my-session] Task completed", "")
 	// Error acceptable in CI
 	_ = err
 }
@@ -325,7 +328,7 @@ func TestSendDesktop_WithoutSessionName(t *testing.T) {
 	n := New(cfg)
 
 	// Test without session name
-	err := n.SendDesktop(analyzer.StatusTaskComplete, "Task completed without session")
+	err := n.SendDesktop(analyzer.StatusTaskComplete, "Task completed without session", "")
 	// Error acceptable in CI
 	_ = err
 }
@@ -462,7 +465,7 @@ func TestSendDesktop_ClickToFocusWithBeeepFallback(t *testing.T) {
 
 	// Should work regardless of terminal-notifier availability
 	// Will use terminal-notifier if available, otherwise beeep
-	err := n.SendDesktop(analyzer.StatusTaskComplete, "[fallback-test] Testing fallback")
+	err := n.SendDesktop(analyzer.StatusTaskComplete, "[fallback-test] Testing fallback", "")
 	// Error acceptable in CI where neither may work
 	_ = err
 }
@@ -655,7 +658,7 @@ func TestSendDesktop_FallbackWhenTerminalNotifierFails(t *testing.T) {
 	n := New(cfg)
 
 	// Should not return error - should fall back to beeep
-	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test] Fallback test")
+	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test] Fallback test", "")
 	// Error is acceptable in CI, but should not panic
 	_ = err
 }
@@ -669,7 +672,7 @@ func TestSendDesktop_ClickToFocusDisabledUsesBeeep(t *testing.T) {
 	n := New(cfg)
 
 	// Should use beeep path even on macOS
-	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test] Beeep path test")
+	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test] Beeep path test", "")
 	// Error acceptable in CI
 	_ = err
 }
@@ -777,7 +780,7 @@ func TestSendDesktop_AppIconNotFound(t *testing.T) {
 	n := New(cfg)
 
 	// Should handle missing icon gracefully
-	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test] Icon test")
+	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test] Icon test", "")
 	// Error acceptable in CI
 	_ = err
 }
@@ -791,7 +794,7 @@ func TestSendDesktop_EmptyMessage(t *testing.T) {
 	n := New(cfg)
 
 	// Empty message should still work
-	err := n.SendDesktop(analyzer.StatusTaskComplete, "")
+	err := n.SendDesktop(analyzer.StatusTaskComplete, "", "")
 	// Error acceptable in CI
 	_ = err
 }
@@ -806,7 +809,7 @@ func TestSendDesktop_VeryLongMessage(t *testing.T) {
 
 	// Very long message
 	longMessage := "[test-session] " + strings.Repeat("This is a very long message. ", 100)
-	err := n.SendDesktop(analyzer.StatusTaskComplete, longMessage)
+	err := n.SendDesktop(analyzer.StatusTaskComplete, longMessage, "")
 	// Error acceptable in CI
 	_ = err
 }
@@ -821,7 +824,7 @@ func TestSendDesktop_SpecialCharactersInMessage(t *testing.T) {
 
 	// Message with special characters
 	specialMessage := "[test] Message with \"quotes\", 'apostrophes', <brackets>, & ampersand, \n newline"
-	err := n.SendDesktop(analyzer.StatusTaskComplete, specialMessage)
+	err := n.SendDesktop(analyzer.StatusTaskComplete, specialMessage, "")
 	// Error acceptable in CI
 	_ = err
 }
@@ -836,7 +839,7 @@ func TestSendDesktop_UnicodeMessage(t *testing.T) {
 
 	// Unicode message
 	unicodeMessage := "[тест] Сообщение на русском 你好 🎉 émojis"
-	err := n.SendDesktop(analyzer.StatusTaskComplete, unicodeMessage)
+	err := n.SendDesktop(analyzer.StatusTaskComplete, unicodeMessage, "")
 	// Error acceptable in CI
 	_ = err
 }
@@ -918,7 +921,7 @@ func TestSendDesktop_OSC9Method(t *testing.T) {
 
 	// OSC9 requires /dev/tty which may not be available in CI
 	// We just verify it doesn't panic and handles errors gracefully
-	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test-session] OSC9 test")
+	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test-session] OSC9 test", "")
 	// Error is expected in CI (no tty)
 	_ = err
 }
@@ -943,7 +946,7 @@ func TestSendDesktop_OSC9MethodWithAllStatuses(t *testing.T) {
 	for _, status := range statuses {
 		t.Run(string(status), func(t *testing.T) {
 			// Should not panic for any status
-			err := n.SendDesktop(status, "[osc9-test] "+string(status))
+			err := n.SendDesktop(status, "[osc9-test] "+string(status), "")
 			// Error acceptable (no tty in CI)
 			_ = err
 		})
@@ -1024,7 +1027,7 @@ func TestSendDesktop_MethodBeeep(t *testing.T) {
 	n := New(cfg)
 
 	// Should use beeep regardless of ClickToFocus setting
-	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test] Beeep method test")
+	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test] Beeep method test", "")
 	// Error acceptable in CI
 	_ = err
 }
@@ -1042,7 +1045,7 @@ func TestSendDesktop_MethodTerminalNotifier(t *testing.T) {
 	n := New(cfg)
 
 	// Should try terminal-notifier first, fallback to beeep if not available
-	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test] terminal-notifier method test")
+	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test] terminal-notifier method test", "")
 	// Error acceptable
 	_ = err
 }
@@ -1056,7 +1059,7 @@ func TestSendDesktop_MethodAuto(t *testing.T) {
 	n := New(cfg)
 
 	// Auto should use the same logic as empty method
-	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test] Auto method test")
+	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test] Auto method test", "")
 	// Error acceptable in CI
 	_ = err
 }
@@ -1070,7 +1073,201 @@ func TestSendDesktop_MethodEmpty(t *testing.T) {
 	n := New(cfg)
 
 	// Empty method should behave like auto
-	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test] Empty method test")
+	err := n.SendDesktop(analyzer.StatusTaskComplete, "[test] Empty method test", "")
 	// Error acceptable in CI
 	_ = err
 }
+
+// === Tests for sound playback ===
+
+// fakePlayer records played sounds instead of using an audio device
+type fakePlayer struct {
+	mu      sync.Mutex
+	played  []string
+	delay   time.Duration
+	err     error
+	closed  int
+	device  string
+	volume  float64
+	created int
+}
+
+func (p *fakePlayer) Play(path string) error {
+	time.Sleep(p.delay)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.played = append(p.played, path)
+	return p.err
+}
+
+func (p *fakePlayer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed++
+	return nil
+}
+
+func newNotifierWithFakePlayer(cfg *config.Config, player *fakePlayer) *Notifier {
+	n := New(cfg)
+	n.newPlayer = func(deviceName string, volume float64) (soundPlayer, error) {
+		player.device = deviceName
+		player.volume = volume
+		player.created++
+		return player, nil
+	}
+	return n
+}
+
+func newSoundTestConfig(t *testing.T) (*config.Config, string) {
+	t.Helper()
+	soundPath := filepath.Join(t.TempDir(), "sound.mp3")
+	if err := os.WriteFile(soundPath, []byte("fake"), 0644); err != nil {
+		t.Fatalf("failed to create sound file: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Notifications.Desktop.Enabled = true
+	cfg.Notifications.Desktop.Method = "osc9" // avoid real desktop notifications
+	cfg.Notifications.Desktop.Sound = true
+	cfg.Notifications.Desktop.Volume = 0.4
+	cfg.Notifications.Desktop.AudioDevice = "Test Speakers"
+	cfg.Statuses["task_complete"] = config.StatusInfo{Title: "Done", Sound: soundPath}
+	return cfg, soundPath
+}
+
+func TestSendDesktop_PlaysStatusSound(t *testing.T) {
+	cfg, soundPath := newSoundTestConfig(t)
+	player := &fakePlayer{}
+	n := newNotifierWithFakePlayer(cfg, player)
+
+	_ = n.SendDesktop(analyzer.StatusTaskComplete, "[test] done", "")
+	if err := n.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+
+	if len(player.played) != 1 || player.played[0] != soundPath {
+		t.Errorf("played = %v, want [%s]", player.played, soundPath)
+	}
+	if player.device != "Test Speakers" {
+		t.Errorf("device = %q, want %q", player.device, "Test Speakers")
+	}
+	if player.volume != 0.4 {
+		t.Errorf("volume = %v, want 0.4", player.volume)
+	}
+	if player.closed != 1 {
+		t.Errorf("player closed %d times, want 1", player.closed)
+	}
+}
+
+func TestSendDesktop_SoundDisabled(t *testing.T) {
+	cfg, _ := newSoundTestConfig(t)
+	cfg.Notifications.Desktop.Sound = false
+	player := &fakePlayer{}
+	n := newNotifierWithFakePlayer(cfg, player)
+
+	_ = n.SendDesktop(analyzer.StatusTaskComplete, "[test] done", "")
+	_ = n.Close()
+
+	if player.created != 0 || len(player.played) != 0 {
+		t.Errorf("player should not be used when sound is disabled (created=%d, played=%v)", player.created, player.played)
+	}
+}
+
+func TestSendDesktopSilent_SkipsSound(t *testing.T) {
+	cfg, _ := newSoundTestConfig(t)
+	player := &fakePlayer{}
+	n := newNotifierWithFakePlayer(cfg, player)
+
+	_ = n.SendDesktopSilent(analyzer.StatusTaskComplete, "[test] done", "")
+	_ = n.Close()
+
+	if player.created != 0 || len(player.played) != 0 {
+		t.Errorf("player should not be used for silent notifications (created=%d, played=%v)", player.created, player.played)
+	}
+}
+
+func TestSendDesktop_MissingSoundFileSkipped(t *testing.T) {
+	cfg, _ := newSoundTestConfig(t)
+	cfg.Statuses["task_complete"] = config.StatusInfo{Title: "Done", Sound: "/nonexistent/sound.mp3"}
+	player := &fakePlayer{}
+	n := newNotifierWithFakePlayer(cfg, player)
+
+	_ = n.SendDesktop(analyzer.StatusTaskComplete, "[test] done", "")
+	_ = n.Close()
+
+	if len(player.played) != 0 {
+		t.Errorf("missing sound file should be skipped, played = %v", player.played)
+	}
+}
+
+func TestNotifier_CloseWaitsForPlayback(t *testing.T) {
+	cfg, soundPath := newSoundTestConfig(t)
+	player := &fakePlayer{delay: 100 * time.Millisecond}
+	n := newNotifierWithFakePlayer(cfg, player)
+
+	_ = n.SendDesktop(analyzer.StatusTaskComplete, "[test] done", "")
+	_ = n.Close()
+
+	if len(player.played) != 1 || player.played[0] != soundPath {
+		t.Errorf("Close() returned before playback finished, played = %v", player.played)
+	}
+}
+
+func TestNotifier_PlayerReusedAcrossSounds(t *testing.T) {
+	cfg, _ := newSoundTestConfig(t)
+	player := &fakePlayer{}
+	n := newNotifierWithFakePlayer(cfg, player)
+
+	_ = n.SendDesktop(analyzer.StatusTaskComplete, "[test] first", "")
+	_ = n.SendDesktop(analyzer.StatusTaskComplete, "[test] second", "")
+	_ = n.Close()
+
+	if player.created != 1 {
+		t.Errorf("player created %d times, want 1", player.created)
+	}
+	if len(player.played) != 2 {
+		t.Errorf("played %d sounds, want 2", len(player.played))
+	}
+}
+
+func TestSendDesktop_PlayerErrorDoesNotFailNotification(t *testing.T) {
+	cfg, _ := newSoundTestConfig(t)
+	n := New(cfg)
+	n.newPlayer = func(deviceName string, volume float64) (soundPlayer, error) {
+		return nil, errors.New("no audio device")
+	}
+
+	// Sound errors are logged, not returned; the OSC9 result is what matters here
+	_ = n.SendDesktop(analyzer.StatusTaskComplete, "[test] done", "")
+	if err := n.Close(); err != nil {
+		t.Errorf("Close() returned error: %v", err)
+	}
+}
+
+func TestPlaySound_UsesConfiguredPlayer(t *testing.T) {
+	cfg, soundPath := newSoundTestConfig(t)
+	player := &fakePlayer{}
+	n := newNotifierWithFakePlayer(cfg, player)
+
+	if err := n.PlaySound(soundPath); err != nil {
+		t.Fatalf("PlaySound() returned error: %v", err)
+	}
+	_ = n.Close()
+
+	if len(player.played) != 1 || player.played[0] != soundPath {
+		t.Errorf("played = %v, want [%s]", player.played, soundPath)
+	}
+	if player.device != "Test Speakers" || player.volume != 0.4 {
+		t.Errorf("player created with device=%q volume=%v, want %q 0.4", player.device, player.volume, "Test Speakers")
+	}
+}
+
+func TestPlaySound_ReturnsPlayerError(t *testing.T) {
+	cfg, soundPath := newSoundTestConfig(t)
+	player := &fakePlayer{err: errors.New("decode failed")}
+	n := newNotifierWithFakePlayer(cfg, player)
+
+	if err := n.PlaySound(soundPath); err == nil {
+		t.Error("Expected PlaySound() to return the player error")
+	}
+}
@@ -0,0 +1,142 @@
+package notifier
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/logging"
+)
+
+const (
+	// oscMaxMessage is the longest message (in runes) put into an escape sequence
+	oscMaxMessage = 200
+
+	// st is the string terminator ending OSC and DCS sequences
+	st = "\033\\"
+)
+
+// openDevTTY opens the controlling terminal for escape sequence notifications
+func openDevTTY() (io.WriteCloser, error) {
+	return os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+}
+
+// detectOSCMethod picks the notification escape sequence the terminal understands from
+// TERM and TERM_PROGRAM. Inside tmux these describe tmux, so variables that the outer
+// terminal exports to its children are checked too. Unknown terminals get OSC 9.
+func detectOSCMethod() string {
+	term := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+
+	switch {
+	case term == "xterm-kitty" || os.Getenv("KITTY_WINDOW_ID") != "":
+		return "osc99"
+	case strings.HasPrefix(term, "foot"), strings.HasPrefix(term, "rxvt"), term == "xterm-ghostty",
+		program == "WezTerm", program == "ghostty",
+		os.Getenv("WEZTERM_PANE") != "", os.Getenv("GHOSTTY_RESOURCES_DIR") != "":
+		return "osc777"
+	default:
+		return "osc9"
+	}
+}
+
+// sendWithOSC99 sends a kitty notification (OSC 99) with a separate title and body.
+// The identifier is derived from the session, so a new status replaces the session's
+// previous notification.
+func (n *Notifier) sendWithOSC99(status analyzer.Status, title, message, sessionID string) error {
+	id := "claude-" + sanitizeID(sessionID)
+	if sessionID == "" {
+		id = fmt.Sprintf("claude-%d", time.Now().UnixNano())
+	}
+	meta := fmt.Sprintf("i=%s:u=%d:e=1", id, statusUrgency(status))
+
+	// d=0 marks the title as incomplete; the body chunk completes the notification
+	encode := base64.StdEncoding.EncodeToString
+	seqs := []string{
+		"\033]99;" + meta + ":d=0:p=title;" + encode([]byte(oscText(title))) + st,
+		"\033]99;" + meta + ":d=1:p=body;" + encode([]byte(truncateRunes(oscText(message), oscMaxMessage))) + st,
+	}
+	if err := n.writeTTY(seqs...); err != nil {
+		return err
+	}
+
+	logging.Debug("Desktop notification sent via OSC99: id=%s, title=%s", id, title)
+	return nil
+}
+
+// sendWithOSC777 sends an OSC 777 notification (rxvt-unicode, foot, WezTerm, Ghostty)
+// Format: ESC ] 777 ; notify ; title ; body ESC \
+func (n *Notifier) sendWithOSC777(title, message string) error {
+	// The title ends at the first ';'
+	title = strings.ReplaceAll(oscText(title), ";", ",")
+	seq := "\033]777;notify;" + title + ";" + truncateRunes(oscText(message), oscMaxMessage) + st
+	if err := n.writeTTY(seq); err != nil {
+		return err
+	}
+
+	logging.Debug("Desktop notification sent via OSC777: title=%s", title)
+	return nil
+}
+
+// writeTTY writes escape sequences to the terminal, wrapped for tmux or GNU screen
+func (n *Notifier) writeTTY(seqs ...string) error {
+	tty, err := n.openTTY()
+	if err != nil {
+		logging.Error("Failed to open /dev/tty: %v", err)
+		return fmt.Errorf("failed to open /dev/tty: %w", err)
+	}
+	defer tty.Close()
+
+	var b strings.Builder
+	for _, seq := range seqs {
+		b.WriteString(wrapPassthrough(seq))
+	}
+	if _, err := io.WriteString(tty, b.String()); err != nil {
+		logging.Error("Failed to write escape sequence: %v", err)
+		return fmt.Errorf("failed to write escape sequence: %w", err)
+	}
+	return nil
+}
+
+// wrapPassthrough wraps an escape sequence in DCS passthrough when running inside tmux
+// ($TMUX) or GNU screen ($STY), which otherwise swallow it. tmux 3.3+ also needs
+// "set -g allow-passthrough on".
+func wrapPassthrough(seq string) string {
+	switch {
+	case os.Getenv("TMUX") != "":
+		// tmux: ESC P tmux; <sequence with every ESC doubled> ESC \
+		return "\033Ptmux;" + strings.ReplaceAll(seq, "\033", "\033\033") + st
+	case os.Getenv("STY") != "":
+		// screen ends the passthrough at the first ST, so the inner sequence ends with BEL
+		return "\033P" + strings.TrimSuffix(seq, st) + "\a" + st
+	default:
+		return seq
+	}
+}
+
+// oscText removes control characters, which would end or corrupt an escape sequence
+func oscText(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return ' '
+		case r < 0x20 || (r >= 0x7f && r <= 0x9f):
+			return -1
+		default:
+			return r
+		}
+	}, s)
+}
+
+// truncateRunes shortens s to max runes, ending with "..." when cut
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
@@ -0,0 +1,182 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// newOSCNotifier returns a notifier for method whose terminal output goes to the returned buffer
+func newOSCNotifier(t *testing.T, method string) (*Notifier, *bytes.Buffer) {
+	t.Helper()
+	// Not inside a multiplexer unless the test says so
+	t.Setenv("TMUX", "")
+	t.Setenv("STY", "")
+
+	cfg := config.DefaultConfig()
+	cfg.Notifications.Desktop.Method = method
+	cfg.Notifications.Desktop.Sound = false
+	n := New(cfg)
+
+	var out bytes.Buffer
+	n.openTTY = func() (io.WriteCloser, error) { return nopWriteCloser{&out}, nil }
+	return n, &out
+}
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func TestSendDesktop_OSC9Bytes(t *testing.T) {
+	n, out := newOSCNotifier(t, "osc9")
+
+	if err := n.SendDesktop(analyzer.StatusTaskComplete, "[bold-cat] Done\nwith\x07bell", "session-1"); err != nil {
+		t.Fatalf("SendDesktop failed: %v", err)
+	}
+
+	want := "\033]9;✅ Completed [bold-cat]: Done withbell\033\\"
+	if out.String() != want {
+		t.Errorf("Unexpected OSC 9:\ngot:  %q\nwant: %q", out.String(), want)
+	}
+}
+
+func TestSendDesktop_OSC99Bytes(t *testing.T) {
+	n, out := newOSCNotifier(t, "osc99")
+
+	if err := n.SendDesktop(analyzer.StatusQuestion, "[bold-cat|main] Which database?", "73b5e210/ec1a"); err != nil {
+		t.Fatalf("SendDesktop failed: %v", err)
+	}
+
+	want := "\033]99;i=claude-73b5e210_ec1a:u=2:e=1:d=0:p=title;" + b64("❓ Question [bold-cat] main") + "\033\\" +
+		"\033]99;i=claude-73b5e210_ec1a:u=2:e=1:d=1:p=body;" + b64("Which database?") + "\033\\"
+	if out.String() != want {
+		t.Errorf("Unexpected OSC 99:\ngot:  %q\nwant: %q", out.String(), want)
+	}
+
+	// Same session, same identifier: kitty replaces the notification
+	out.Reset()
+	_ = n.SendDesktop(analyzer.StatusTaskComplete, "[bold-cat] Done", "73b5e210/ec1a")
+	if !strings.HasPrefix(out.String(), "\033]99;i=claude-73b5e210_ec1a:u=1:") {
+		t.Errorf("Expected the same identifier with normal urgency, got %q", out.String())
+	}
+}
+
+func TestSendDesktop_OSC777Bytes(t *testing.T) {
+	n, out := newOSCNotifier(t, "osc777")
+	n.cfg.Statuses["plan_ready"] = config.StatusInfo{Title: "Plan; ready"}
+
+	if err := n.SendDesktop(analyzer.StatusPlanReady, "Step 1; step 2", "session-1"); err != nil {
+		t.Fatalf("SendDesktop failed: %v", err)
+	}
+
+	want := "\033]777;notify;Plan, ready;Step 1; step 2\033\\"
+	if out.String() != want {
+		t.Errorf("Unexpected OSC 777:\ngot:  %q\nwant: %q", out.String(), want)
+	}
+}
+
+func TestWriteTTY_Passthrough(t *testing.T) {
+	seq := "\033]777;notify;Title;Body\033\\"
+
+	t.Run("tmux", func(t *testing.T) {
+		n, out := newOSCNotifier(t, "osc777")
+		t.Setenv("TMUX", "/tmp/tmux-1000/default,1234,0")
+
+		_ = n.sendWithOSC777("Title", "Body")
+		want := "\033Ptmux;\033\033]777;notify;Title;Body\033\033\\\033\\"
+		if out.String() != want {
+			t.Errorf("Unexpected tmux passthrough:\ngot:  %q\nwant: %q", out.String(), want)
+		}
+	})
+
+	t.Run("screen", func(t *testing.T) {
+		n, out := newOSCNotifier(t, "osc777")
+		t.Setenv("STY", "1234.pts-0.host")
+
+		_ = n.sendWithOSC777("Title", "Body")
+		want := "\033P\033]777;notify;Title;Body\a\033\\"
+		if out.String() != want {
+			t.Errorf("Unexpected screen passthrough:\ngot:  %q\nwant: %q", out.String(), want)
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		n, out := newOSCNotifier(t, "osc777")
+
+		_ = n.sendWithOSC777("Title", "Body")
+		if out.String() != seq {
+			t.Errorf("Expected the plain sequence, got %q", out.String())
+		}
+	})
+}
+
+func TestWriteTTY_OpenError(t *testing.T) {
+	n, _ := newOSCNotifier(t, "osc99")
+	n.openTTY = func() (io.WriteCloser, error) { return nil, errors.New("no tty") }
+
+	err := n.SendDesktop(analyzer.StatusTaskComplete, "Done", "session-1")
+	if err == nil || !strings.Contains(err.Error(), "no tty") {
+		t.Errorf("Expected the tty error, got %v", err)
+	}
+}
+
+func TestDetectOSCMethod(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"kitty", map[string]string{"TERM": "xterm-kitty"}, "osc99"},
+		{"kitty inside tmux", map[string]string{"TERM": "tmux-256color", "TERM_PROGRAM": "tmux", "KITTY_WINDOW_ID": "1"}, "osc99"},
+		{"foot", map[string]string{"TERM": "foot"}, "osc777"},
+		{"rxvt-unicode", map[string]string{"TERM": "rxvt-unicode-256color"}, "osc777"},
+		{"WezTerm", map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "WezTerm"}, "osc777"},
+		{"Ghostty", map[string]string{"TERM": "xterm-ghostty", "TERM_PROGRAM": "ghostty"}, "osc777"},
+		{"WezTerm inside tmux", map[string]string{"TERM": "tmux-256color", "TERM_PROGRAM": "tmux", "WEZTERM_PANE": "0"}, "osc777"},
+		{"iTerm2", map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "iTerm.app"}, "osc9"},
+		{"unknown", map[string]string{"TERM": "xterm"}, "osc9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"TERM", "TERM_PROGRAM", "KITTY_WINDOW_ID", "WEZTERM_PANE", "GHOSTTY_RESOURCES_DIR"} {
+				t.Setenv(key, tt.env[key])
+			}
+			if got := detectOSCMethod(); got != tt.want {
+				t.Errorf("detectOSCMethod() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendDesktop_OSCAutoMethod(t *testing.T) {
+	n, out := newOSCNotifier(t, "osc")
+	for _, key := range []string{"TERM_PROGRAM", "KITTY_WINDOW_ID", "WEZTERM_PANE", "GHOSTTY_RESOURCES_DIR"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("TERM", "foot")
+
+	_ = n.SendDesktop(analyzer.StatusTaskComplete, "Done", "session-1")
+	if !strings.HasPrefix(out.String(), "\033]777;notify;") {
+		t.Errorf("Expected OSC 777 for foot, got %q", out.String())
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	if got := truncateRunes("日本語テキスト", 5); got != "日本..." {
+		t.Errorf("truncateRunes = %q, want %q", got, "日本...")
+	}
+	if got := truncateRunes("short", 5); got != "short" {
+		t.Errorf("truncateRunes = %q, want %q", got, "short")
+	}
+}
@@ -0,0 +1,50 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// lockRetryInterval is how often LockFile retries while another process holds the lock
+const lockRetryInterval = 5 * time.Millisecond
+
+// FileLock is an exclusive lock on a file, held across processes
+type FileLock struct {
+	f *os.File
+}
+
+// LockFile acquires an exclusive lock on path, creating the file if needed.
+// Waits up to timeout for another process to release it.
+func LockFile(path string, timeout time.Duration) (*FileLock, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if locked {
+			return &FileLock{f: f}, nil
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("timed out after %v waiting for lock on %s", timeout, path)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// Unlock releases the lock
+func (l *FileLock) Unlock() error {
+	err := unlock(l.f)
+	if closeErr := l.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,52 @@
+package platform
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.lock")
+
+	lock, err := LockFile(path, time.Second)
+	require.NoError(t, err)
+	assert.True(t, FileExists(path))
+
+	// A second lock (separate file handle, as in another process) must wait and time out
+	_, err = LockFile(path, 50*time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+
+	require.NoError(t, lock.Unlock())
+
+	lock, err = LockFile(path, time.Second)
+	require.NoError(t, err)
+	require.NoError(t, lock.Unlock())
+}
+
+func TestLockFile_WaitsForRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.lock")
+
+	lock, err := LockFile(path, time.Second)
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = lock.Unlock()
+	}()
+
+	start := time.Now()
+	second, err := LockFile(path, 2*time.Second)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	require.NoError(t, second.Unlock())
+}
+
+func TestLockFile_InvalidPath(t *testing.T) {
+	_, err := LockFile(filepath.Join(t.TempDir(), "missing", "state.lock"), time.Second)
+	assert.Error(t, err)
+}
@@ -0,0 +1,42 @@
+package platform
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// maxProcessDepth bounds walks up the process tree in case the table has a cycle
+const maxProcessDepth = 64
+
+// ProcessParents returns the parent PID of every process, read from ps
+func ProcessParents() (map[int]int, error) {
+	out, err := exec.Command("ps", "-A", "-o", "pid=,ppid=").Output()
+	if err != nil {
+		return nil, err
+	}
+	parents := make(map[int]int)
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		if err1 == nil && err2 == nil {
+			parents[pid] = ppid
+		}
+	}
+	return parents, nil
+}
+
+// ProcessAncestors returns pid followed by its parent, grandparent and so on, up to
+// (excluding) init
+func ProcessAncestors(parents map[int]int, pid int) []int {
+	var chain []int
+	for i := 0; pid > 1 && i < maxProcessDepth; i++ {
+		chain = append(chain, pid)
+		pid = parents[pid]
+	}
+	return chain
+}
@@ -0,0 +1,42 @@
+package platform
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestProcessParents(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("ps is not available on Windows")
+	}
+	cmd := exec.Command("sleep", "5")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = cmd.Process.Kill(); _ = cmd.Wait() }()
+
+	parents, err := ProcessParents()
+	if err != nil {
+		t.Fatalf("ProcessParents failed: %v", err)
+	}
+	if got := parents[cmd.Process.Pid]; got != os.Getpid() {
+		t.Errorf("Expected parent %d for pid %d, got %d", os.Getpid(), cmd.Process.Pid, got)
+	}
+}
+
+func TestProcessAncestors(t *testing.T) {
+	parents := map[int]int{130: 120, 120: 110, 110: 1, 7: 8, 8: 7}
+
+	if got := ProcessAncestors(parents, 130); !reflect.DeepEqual(got, []int{130, 120, 110}) {
+		t.Errorf("Expected [130 120 110], got %v", got)
+	}
+	if got := ProcessAncestors(parents, 999); !reflect.DeepEqual(got, []int{999}) {
+		t.Errorf("Expected [999] for an unknown process, got %v", got)
+	}
+	if got := ProcessAncestors(parents, 7); len(got) != maxProcessDepth {
+		t.Errorf("Expected a cycle to stop after %d steps, got %d", maxProcessDepth, len(got))
+	}
+}
@@ -0,0 +1,154 @@
+// Package schedule evaluates the quiet hours schedule: which notifications are
+// muted, suppressed or deferred at a given time.
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+// Decision is what quiet hours do to one notification
+type Decision struct {
+	Quiet           bool      // Outside the active windows and the status doesn't break through
+	MuteDesktop     bool      // Show the desktop notification without sound
+	SuppressDesktop bool      // Skip the desktop notification
+	DeferWebhook    bool      // Keep webhooks in the outbox until the active hours
+	Until           time.Time // When the quiet hours end (zero if not quiet or no window follows)
+}
+
+// window is a parsed config.ScheduleWindow
+type window struct {
+	days       [7]bool // Indexed by time.Weekday
+	start, end time.Duration
+}
+
+// Schedule is a parsed quiet hours configuration
+type Schedule struct {
+	cfg     config.ScheduleConfig
+	loc     *time.Location
+	windows []window
+}
+
+// New parses a schedule. A disabled schedule is never quiet.
+func New(cfg config.ScheduleConfig) (*Schedule, error) {
+	s := &Schedule{cfg: cfg, loc: time.Local}
+	if !cfg.Enabled {
+		return s, nil
+	}
+
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone: %s", cfg.Timezone)
+		}
+		s.loc = loc
+	}
+
+	for _, w := range cfg.Windows {
+		var parsed window
+		if len(w.Days) == 0 {
+			for d := range parsed.days {
+				parsed.days[d] = true
+			}
+		}
+		for _, day := range w.Days {
+			d, err := config.ParseWeekday(day)
+			if err != nil {
+				return nil, err
+			}
+			parsed.days[d] = true
+		}
+		var err error
+		if parsed.start, err = config.ParseTimeOfDay(w.Start); err != nil {
+			return nil, err
+		}
+		if parsed.end, err = config.ParseTimeOfDay(w.End); err != nil {
+			return nil, err
+		}
+		s.windows = append(s.windows, parsed)
+	}
+	return s, nil
+}
+
+// Active reports whether t falls inside one of the windows. A disabled schedule is
+// always active.
+func (s *Schedule) Active(t time.Time) bool {
+	if !s.cfg.Enabled {
+		return true
+	}
+
+	t = t.In(s.loc)
+	day := t.Weekday()
+	yesterday := (day + 6) % 7
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+
+	for _, w := range s.windows {
+		if w.start < w.end {
+			if w.days[day] && clock >= w.start && clock < w.end {
+				return true
+			}
+			continue
+		}
+		// The window spans midnight: its evening belongs to day, its morning to the next day
+		if (w.days[day] && clock >= w.start) || (w.days[yesterday] && clock < w.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextActive returns when the next window starts, or t if t is inside one. The zero
+// time means the schedule has no windows.
+func (s *Schedule) NextActive(t time.Time) time.Time {
+	if s.Active(t) {
+		return t
+	}
+
+	local := t.In(s.loc)
+	var next time.Time
+	for i := 0; i <= 7; i++ {
+		date := time.Date(local.Year(), local.Month(), local.Day()+i, 0, 0, 0, 0, s.loc)
+		for _, w := range s.windows {
+			if !w.days[date.Weekday()] {
+				continue
+			}
+			// Built from the wall clock so DST changes don't shift the start
+			hour, minute := int(w.start/time.Hour), int(w.start%time.Hour/time.Minute)
+			start := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, s.loc)
+			if start.After(t) && (next.IsZero() || start.Before(next)) {
+				next = start
+			}
+		}
+		if !next.IsZero() {
+			return next
+		}
+	}
+	return next
+}
+
+// Decide returns what quiet hours do to a notification with status sent at t
+func (s *Schedule) Decide(status analyzer.Status, t time.Time) Decision {
+	if s.Active(t) || s.breaksThrough(status) {
+		return Decision{}
+	}
+	return Decision{
+		Quiet:           true,
+		MuteDesktop:     s.cfg.Desktop != "suppress",
+		SuppressDesktop: s.cfg.Desktop == "suppress",
+		DeferWebhook:    s.cfg.Webhook == "defer",
+		Until:           s.NextActive(t),
+	}
+}
+
+// breaksThrough reports whether status ignores quiet hours
+func (s *Schedule) breaksThrough(status analyzer.Status) bool {
+	for _, st := range s.cfg.BreakThrough {
+		if st == string(status) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+// workdays is 09:00-18:00 Monday to Friday plus a late window on Friday night, in Berlin
+func workdays() config.ScheduleConfig {
+	cfg := config.DefaultScheduleConfig()
+	cfg.Enabled = true
+	cfg.Timezone = "Europe/Berlin"
+	cfg.Windows = []config.ScheduleWindow{
+		{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "18:00"},
+		{Days: []string{"Friday"}, Start: "22:00", End: "02:00"},
+	}
+	return cfg
+}
+
+func mustNew(t *testing.T, cfg config.ScheduleConfig) *Schedule {
+	t.Helper()
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return s
+}
+
+func berlin(t *testing.T, value string) time.Time {
+	t.Helper()
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	tm, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tm
+}
+
+func TestActive(t *testing.T) {
+	s := mustNew(t, workdays())
+
+	// 2026-10-12 is a Monday
+	tests := []struct {
+		at   string
+		want bool
+	}{
+		{"2026-10-12 08:59", false},
+		{"2026-10-12 09:00", true},
+		{"2026-10-12 17:59", true},
+		{"2026-10-12 18:00", false},
+		{"2026-10-16 23:30", true},  // Friday night window
+		{"2026-10-17 01:59", true},  // ... continues into Saturday
+		{"2026-10-17 02:00", false}, // ... and ends
+		{"2026-10-17 12:00", false}, // Saturday
+		{"2026-10-13 01:00", false}, // Monday's window doesn't span midnight
+	}
+	for _, tt := range tests {
+		if got := s.Active(berlin(t, tt.at)); got != tt.want {
+			t.Errorf("Active(%s) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestActiveUsesTimezone(t *testing.T) {
+	s := mustNew(t, workdays())
+
+	// 07:30 UTC is 09:30 in Berlin (CEST)
+	at := time.Date(2026, 10, 12, 7, 30, 0, 0, time.UTC)
+	if !s.Active(at) {
+		t.Errorf("Expected %v to be inside Berlin working hours", at)
+	}
+}
+
+func TestActiveEveryDayUntilMidnight(t *testing.T) {
+	cfg := workdays()
+	cfg.Windows = []config.ScheduleWindow{{Start: "07:00", End: "24:00"}}
+	s := mustNew(t, cfg)
+
+	if !s.Active(berlin(t, "2026-10-18 23:59")) {
+		t.Error("Expected a window without days to cover Sunday until midnight")
+	}
+	if s.Active(berlin(t, "2026-10-18 06:00")) {
+		t.Error("Expected 06:00 to be quiet")
+	}
+}
+
+func TestDisabledIsAlwaysActive(t *testing.T) {
+	cfg := workdays()
+	cfg.Enabled = false
+	s := mustNew(t, cfg)
+
+	at := berlin(t, "2026-10-17 12:00")
+	if !s.Active(at) {
+		t.Error("Expected a disabled schedule to be active")
+	}
+	if d := s.Decide(analyzer.StatusQuestion, at); d.Quiet {
+		t.Errorf("Expected no quiet hours, got %+v", d)
+	}
+}
+
+func TestNextActive(t *testing.T) {
+	s := mustNew(t, workdays())
+
+	tests := []struct {
+		at, want string
+	}{
+		{"2026-10-12 10:00", "2026-10-12 10:00"}, // already active
+		{"2026-10-12 07:00", "2026-10-12 09:00"},
+		{"2026-10-12 19:00", "2026-10-13 09:00"},
+		{"2026-10-16 19:00", "2026-10-16 22:00"},
+		{"2026-10-17 03:00", "2026-10-19 09:00"}, // weekend
+	}
+	for _, tt := range tests {
+		got := s.NextActive(berlin(t, tt.at))
+		if want := berlin(t, tt.want); !got.Equal(want) {
+			t.Errorf("NextActive(%s) = %v, want %v", tt.at, got, want)
+		}
+	}
+}
+
+func TestNextActiveAcrossDST(t *testing.T) {
+	s := mustNew(t, workdays())
+
+	// Berlin leaves summer time on Sunday 2026-10-25
+	got := s.NextActive(berlin(t, "2026-10-24 12:00"))
+	if want := berlin(t, "2026-10-26 09:00"); !got.Equal(want) {
+		t.Errorf("NextActive = %v, want %v", got, want)
+	}
+}
+
+func TestDecide(t *testing.T) {
+	quiet := "2026-10-17 12:00"
+
+	tests := []struct {
+		name      string
+		configure func(c *config.ScheduleConfig)
+		status    analyzer.Status
+		at        string
+		want      Decision
+	}{
+		{"active hours", nil, analyzer.StatusQuestion, "2026-10-12 10:00", Decision{}},
+		{"quiet: mute and deliver", nil, analyzer.StatusQuestion, quiet, Decision{Quiet: true, MuteDesktop: true}},
+		{"quiet: suppress and defer", func(c *config.ScheduleConfig) {
+			c.Desktop = "suppress"
+			c.Webhook = "defer"
+		}, analyzer.StatusTaskComplete, quiet, Decision{Quiet: true, SuppressDesktop: true, DeferWebhook: true}},
+		{"api_error breaks through", nil, analyzer.StatusAPIError, quiet, Decision{}},
+		{"session_limit_reached breaks through", nil, analyzer.StatusSessionLimitReached, quiet, Decision{}},
+		{"custom break-through", func(c *config.ScheduleConfig) {
+			c.BreakThrough = []string{"question"}
+		}, analyzer.StatusAPIError, quiet, Decision{Quiet: true, MuteDesktop: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := workdays()
+			if tt.configure != nil {
+				tt.configure(&cfg)
+			}
+			s := mustNew(t, cfg)
+			got := s.Decide(tt.status, berlin(t, tt.at))
+			// Every quiet case is on Saturday, so quiet hours last until Monday morning
+			if got.Quiet && !got.Until.Equal(berlin(t, "2026-10-19 09:00")) {
+				t.Errorf("Decide().Until = %v, want Monday 09:00", got.Until)
+			}
+			if !got.Quiet && !got.Until.IsZero() {
+				t.Errorf("Decide().Until = %v, want zero", got.Until)
+			}
+			got.Until = time.Time{}
+			if got != tt.want {
+				t.Errorf("Decide() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(c *config.ScheduleConfig)
+	}{
+		{"timezone", func(c *config.ScheduleConfig) { c.Timezone = "Mars/Olympus" }},
+		{"day", func(c *config.ScheduleConfig) { c.Windows[0].Days = []string{"someday"} }},
+		{"start", func(c *config.ScheduleConfig) { c.Windows[0].Start = "9am" }},
+		{"end", func(c *config.ScheduleConfig) { c.Windows[0].End = "25:00" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := workdays()
+			tt.configure(&cfg)
+			if _, err := New(cfg); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/777genius/claude-notifications/internal/platform"
+)
+
+const (
+	// muteFileName is the file in the state directory holding the mute records
+	muteFileName = "claude-notifications-mutes.json"
+
+	// muteLockTimeout bounds how long a mute or unmute waits for another process
+	muteLockTimeout = 2 * time.Second
+)
+
+// Mute silences notifications until it expires or is removed by unmute.
+// Empty filters match every session, project or status.
+type Mute struct {
+	ID        string    `json:"id"`
+	Project   string    `json:"project,omitempty"`   // Project directory; sessions in subdirectories match too
+	SessionID string    `json:"sessionId,omitempty"` // Session ID
+	Status    string    `json:"status,omitempty"`    // Notification status, e.g. "question"
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt,omitempty"` // Zero = until unmuted
+}
+
+// Expired reports whether the mute has expired at now
+func (m *Mute) Expired(now time.Time) bool {
+	return !m.ExpiresAt.IsZero() && !now.Before(m.ExpiresAt)
+}
+
+// Matches reports whether the mute applies to a notification with status for the
+// session sessionID running in cwd
+func (m *Mute) Matches(sessionID, status, cwd string) bool {
+	if m.SessionID != "" && m.SessionID != sessionID {
+		return false
+	}
+	if m.Status != "" && m.Status != status {
+		return false
+	}
+	if m.Project != "" {
+		if cwd == "" {
+			return false
+		}
+		project, dir := filepath.Clean(m.Project), filepath.Clean(cwd)
+		if dir != project && !strings.HasPrefix(dir, project+string(filepath.Separator)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Describe returns what the mute silences, e.g. "question notifications in /src/app"
+func (m *Mute) Describe() string {
+	what := "all notifications"
+	if m.Status != "" {
+		what = m.Status + " notifications"
+	}
+	if m.SessionID != "" {
+		what += " of session " + m.SessionID
+	}
+	if m.Project != "" {
+		what += " in " + m.Project
+	}
+	return what
+}
+
+// getMutePath returns the path of the mute records file
+func (m *Manager) getMutePath() string {
+	return filepath.Join(m.tempDir, muteFileName)
+}
+
+// AddMute stores a mute and returns it with its ID and creation time set
+func (m *Manager) AddMute(mute Mute, now time.Time) (Mute, error) {
+	mute.ID = uuid.New().String()[:8]
+	mute.CreatedAt = now
+
+	err := m.updateMutes(now, func(mutes []Mute) []Mute {
+		return append(mutes, mute)
+	})
+	return mute, err
+}
+
+// RemoveMutes removes the mutes for which remove returns true and returns them
+func (m *Manager) RemoveMutes(now time.Time, remove func(Mute) bool) ([]Mute, error) {
+	var removed []Mute
+	err := m.updateMutes(now, func(mutes []Mute) []Mute {
+		kept := mutes[:0]
+		for _, mute := range mutes {
+			if remove(mute) {
+				removed = append(removed, mute)
+			} else {
+				kept = append(kept, mute)
+			}
+		}
+		return kept
+	})
+	return removed, err
+}
+
+// ActiveMutes returns the mutes that have not expired at now, oldest first
+func (m *Manager) ActiveMutes(now time.Time) ([]Mute, error) {
+	mutes, err := m.readMutes()
+	if err != nil {
+		return nil, err
+	}
+	active := mutes[:0]
+	for _, mute := range mutes {
+		if !mute.Expired(now) {
+			active = append(active, mute)
+		}
+	}
+	return active, nil
+}
+
+// MatchingMute returns the first active mute that applies to a notification, or nil
+func (m *Manager) MatchingMute(sessionID, status, cwd string, now time.Time) (*Mute, error) {
+	mutes, err := m.ActiveMutes(now)
+	if err != nil {
+		return nil, err
+	}
+	for i := range mutes {
+		if mutes[i].Matches(sessionID, status, cwd) {
+			return &mutes[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// updateMutes runs fn on the stored mutes under the lock and writes the result back,
+// dropping expired mutes
+func (m *Manager) updateMutes(now time.Time, fn func([]Mute) []Mute) error {
+	path := m.getMutePath()
+	lock, err := platform.LockFile(path+".lock", muteLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	mutes, err := m.readMutes()
+	if err != nil {
+		return err
+	}
+	mutes = fn(mutes)
+
+	active := make([]Mute, 0, len(mutes))
+	for _, mute := range mutes {
+		if !mute.Expired(now) {
+			active = append(active, mute)
+		}
+	}
+
+	data, err := json.MarshalIndent(active, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize mutes: %w", err)
+	}
+
+	// Write via a temp file and rename, so hooks never read a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), muteFileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write mutes: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write mutes: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write mutes: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write mutes: %w", err)
+	}
+	return nil
+}
+
+// readMutes loads the stored mutes; a missing file means no mutes
+func (m *Manager) readMutes() ([]Mute, error) {
+	data, err := os.ReadFile(m.getMutePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mutes: %w", err)
+	}
+
+	var mutes []Mute
+	if err := json.Unmarshal(data, &mutes); err != nil {
+		return nil, fmt.Errorf("failed to parse mutes: %w", err)
+	}
+	return mutes, nil
+}
@@ -0,0 +1,130 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMute_Matches(t *testing.T) {
+	tests := []struct {
+		name      string
+		mute      Mute
+		sessionID string
+		status    string
+		cwd       string
+		want      bool
+	}{
+		{"everything", Mute{}, "s1", "question", "/src/app", true},
+		{"status match", Mute{Status: "question"}, "s1", "question", "/src/app", true},
+		{"status mismatch", Mute{Status: "question"}, "s1", "task_complete", "/src/app", false},
+		{"session match", Mute{SessionID: "s1"}, "s1", "question", "", true},
+		{"session mismatch", Mute{SessionID: "s1"}, "s2", "question", "", false},
+		{"project", Mute{Project: "/src/app"}, "s1", "question", "/src/app", true},
+		{"project subdirectory", Mute{Project: "/src/app"}, "s1", "question", "/src/app/web", true},
+		{"project prefix only", Mute{Project: "/src/app"}, "s1", "question", "/src/application", false},
+		{"project without cwd", Mute{Project: "/src/app"}, "s1", "question", "", false},
+		{"all filters", Mute{Project: "/src/app", SessionID: "s1", Status: "plan_ready"}, "s1", "plan_ready", "/src/app", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.mute.Matches(tt.sessionID, tt.status, tt.cwd))
+		})
+	}
+}
+
+func TestMute_Describe(t *testing.T) {
+	assert.Equal(t, "all notifications", (&Mute{}).Describe())
+	assert.Equal(t, "question notifications of session s1 in /src/app",
+		(&Mute{Status: "question", SessionID: "s1", Project: "/src/app"}).Describe())
+}
+
+func TestManager_AddAndExpireMutes(t *testing.T) {
+	mgr := NewManagerWithDir(t.TempDir())
+	now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+
+	forever, err := mgr.AddMute(Mute{Status: "question"}, now)
+	require.NoError(t, err)
+	assert.Len(t, forever.ID, 8)
+	assert.Equal(t, now, forever.CreatedAt)
+
+	_, err = mgr.AddMute(Mute{Project: "/src/app", ExpiresAt: now.Add(30 * time.Minute)}, now)
+	require.NoError(t, err)
+
+	mutes, err := mgr.ActiveMutes(now.Add(10 * time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, mutes, 2)
+
+	mute, err := mgr.MatchingMute("s1", "task_complete", "/src/app", now.Add(10*time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, mute)
+	assert.Equal(t, "/src/app", mute.Project)
+
+	// After 30 minutes only the mute without expiry is left
+	later := now.Add(30 * time.Minute)
+	mute, err = mgr.MatchingMute("s1", "task_complete", "/src/app", later)
+	require.NoError(t, err)
+	assert.Nil(t, mute)
+	mutes, err = mgr.ActiveMutes(later)
+	require.NoError(t, err)
+	require.Len(t, mutes, 1)
+	assert.Equal(t, forever.ID, mutes[0].ID)
+}
+
+func TestManager_RemoveMutes(t *testing.T) {
+	mgr := NewManagerWithDir(t.TempDir())
+	now := time.Now()
+
+	_, _ = mgr.AddMute(Mute{Status: "question"}, now)
+	_, _ = mgr.AddMute(Mute{Project: "/src/app"}, now)
+	// Expired mutes are dropped on the next write
+	_, _ = mgr.AddMute(Mute{ExpiresAt: now.Add(time.Minute)}, now)
+
+	removed, err := mgr.RemoveMutes(now.Add(time.Hour), func(m Mute) bool { return m.Project == "/src/app" })
+	require.NoError(t, err)
+	require.Len(t, removed, 1)
+	assert.Equal(t, "/src/app", removed[0].Project)
+
+	mutes, err := mgr.readMutes()
+	require.NoError(t, err)
+	require.Len(t, mutes, 1)
+	assert.Equal(t, "question", mutes[0].Status)
+}
+
+func TestManager_MutesMissingAndCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	mgr := NewManagerWithDir(dir)
+
+	mutes, err := mgr.ActiveMutes(time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, mutes)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, muteFileName), []byte("{not json"), 0644))
+	_, err = mgr.MatchingMute("s1", "question", "", time.Now())
+	assert.Error(t, err)
+}
+
+func TestManager_ConcurrentAddMute(t *testing.T) {
+	mgr := NewManagerWithDir(t.TempDir())
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := mgr.AddMute(Mute{}, now)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	mutes, err := mgr.ActiveMutes(now)
+	require.NoError(t, err)
+	assert.Len(t, mutes, 10, "no mute should be lost to a concurrent write")
+}
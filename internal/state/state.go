@@ -35,6 +35,13 @@ func NewManager() *Manager {
 	}
 }
 
+// NewManagerWithDir creates a state manager that keeps its files in dir
+func NewManagerWithDir(dir string) *Manager {
+	return &Manager{
+		tempDir: dir,
+	}
+}
+
 // getStatePath returns the path to the state file for a session
 func (m *Manager) getStatePath(sessionID string) string {
 	return filepath.Join(m.tempDir, fmt.Sprintf("claude-session-state-%s.json", sessionID))
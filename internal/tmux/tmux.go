@@ -0,0 +1,372 @@
+package tmux
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/777genius/claude-notifications/internal/platform"
+)
+
+// commandTimeout bounds each tmux command
+const commandTimeout = 5 * time.Second
+
+// Window options holding the mark, so a later hook process can remove it
+const (
+	optStatus        = "@claude_status"
+	optOriginalName  = "@claude_original_name"
+	optMarkedName    = "@claude_marked_name"
+	optAutomaticName = "@claude_automatic_rename"
+)
+
+// Pane is a tmux pane
+type Pane struct {
+	ID       string // e.g. "%3"
+	PID      int    // PID of the process the pane runs
+	WindowID string // e.g. "@2"
+	TTY      string
+	Path     string // Current working directory
+}
+
+// Client runs tmux commands against one server
+type Client struct {
+	socket string // -S socket path (empty = the server of $TMUX)
+}
+
+// NewClient creates a client for the server listening on socket, or for the server of
+// $TMUX when socket is empty
+func NewClient(socket string) *Client {
+	return &Client{socket: socket}
+}
+
+// InTmux reports whether the process runs inside a tmux pane
+func InTmux() bool {
+	return os.Getenv("TMUX") != ""
+}
+
+// Run runs a tmux command and returns its trimmed output
+func (c *Client) Run(args ...string) (string, error) {
+	name := args[0]
+	if c.socket != "" {
+		args = append([]string{"-S", c.socket}, args...)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "tmux", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("tmux %s: %w: %s", name, err, msg)
+		}
+		return "", fmt.Errorf("tmux %s: %w", name, err)
+	}
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
+
+// ListPanes returns every pane of the server
+func (c *Client) ListPanes() ([]Pane, error) {
+	out, err := c.Run("list-panes", "-a", "-F", "#{pane_id}\t#{pane_pid}\t#{window_id}\t#{pane_tty}\t#{pane_current_path}")
+	if err != nil {
+		return nil, err
+	}
+
+	var panes []Pane
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 5)
+		if len(fields) != 5 {
+			continue
+		}
+		pid, _ := strconv.Atoi(fields[1])
+		panes = append(panes, Pane{ID: fields[0], PID: pid, WindowID: fields[2], TTY: fields[3], Path: fields[4]})
+	}
+	return panes, nil
+}
+
+// FindPane returns the pane a process runs in: the pane with ID paneID ($TMUX_PANE)
+// if given, else the pane whose process is pid or one of its ancestors, else the only
+// pane whose current directory is cwd
+func (c *Client) FindPane(paneID string, pid int, cwd string) (Pane, error) {
+	panes, err := c.ListPanes()
+	if err != nil {
+		return Pane{}, err
+	}
+	parents, err := platform.ProcessParents()
+	if err != nil {
+		logging.Debug("tmux: failed to read the process table: %v", err)
+	}
+	if pane, ok := findPane(panes, parents, paneID, pid, cwd); ok {
+		return pane, nil
+	}
+	return Pane{}, fmt.Errorf("no tmux pane found for pid %d in %s", pid, cwd)
+}
+
+// findPane implements FindPane; parents maps a PID to its parent PID
+func findPane(panes []Pane, parents map[int]int, paneID string, pid int, cwd string) (Pane, bool) {
+	if paneID != "" {
+		for _, p := range panes {
+			if p.ID == paneID {
+				return p, true
+			}
+		}
+	}
+
+	byPID := make(map[int]Pane, len(panes))
+	for _, p := range panes {
+		byPID[p.PID] = p
+	}
+	for _, ancestor := range platform.ProcessAncestors(parents, pid) {
+		if p, ok := byPID[ancestor]; ok {
+			return p, true
+		}
+	}
+
+	var match []Pane
+	for _, p := range panes {
+		if cwd != "" && p.Path == cwd {
+			match = append(match, p)
+		}
+	}
+	if len(match) == 1 {
+		return match[0], true
+	}
+	return Pane{}, false
+}
+
+// ClientPIDs returns the PIDs of the attached clients whose current pane is pane, i.e.
+// the terminals pane is visible in
+func (c *Client) ClientPIDs(pane Pane) ([]int, error) {
+	out, err := c.Run("list-clients", "-F", "#{client_pid}\t#{pane_id}")
+	if err != nil {
+		return nil, err
+	}
+	return parseClients(out, pane.ID), nil
+}
+
+// parseClients returns the client PIDs of list-clients lines showing paneID
+func parseClients(out, paneID string) []int {
+	var pids []int
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 2)
+		if len(fields) != 2 || fields[1] != paneID {
+			continue
+		}
+		if pid, err := strconv.Atoi(fields[0]); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+// windowOption returns a window option of window, or "" if it isn't set
+func (c *Client) windowOption(window, name string) string {
+	value, err := c.Run("show-options", "-w", "-v", "-q", "-t", window, name)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// setWindowOption sets a window option of window
+func (c *Client) setWindowOption(window, name, value string) error {
+	_, err := c.Run("set-option", "-w", "-t", window, name, value)
+	return err
+}
+
+// unsetWindowOption removes a window option of window
+func (c *Client) unsetWindowOption(window, name string) error {
+	_, err := c.Run("set-option", "-w", "-u", "-t", window, name)
+	return err
+}
+
+// MarkWindow marks the window of pane: it sets @claude_status to status and, if mark is
+// not empty, prefixes the window name with it. The original name is kept in window
+// options for ClearWindow.
+func (c *Client) MarkWindow(pane Pane, status, mark string) error {
+	window := pane.WindowID
+	if err := c.setWindowOption(window, optStatus, status); err != nil {
+		return err
+	}
+	if mark == "" {
+		return nil
+	}
+
+	original := c.windowOption(window, optOriginalName)
+	if original == "" {
+		name, err := c.Run("display-message", "-p", "-t", window, "#{window_name}")
+		if err != nil {
+			return err
+		}
+		automatic, _ := c.Run("display-message", "-p", "-t", window, "#{automatic-rename}")
+		original = name
+		if err := c.setWindowOption(window, optOriginalName, original); err != nil {
+			return err
+		}
+		if err := c.setWindowOption(window, optAutomaticName, automatic); err != nil {
+			return err
+		}
+	}
+
+	marked := mark + " " + original
+	if _, err := c.Run("rename-window", "-t", window, marked); err != nil {
+		return err
+	}
+	return c.setWindowOption(window, optMarkedName, marked)
+}
+
+// ClearWindow removes the mark of MarkWindow. The original name is restored unless the
+// window was renamed since it was marked.
+func (c *Client) ClearWindow(pane Pane) error {
+	window := pane.WindowID
+	if original := c.windowOption(window, optOriginalName); original != "" {
+		name, err := c.Run("display-message", "-p", "-t", window, "#{window_name}")
+		if err != nil {
+			return err
+		}
+		if name == c.windowOption(window, optMarkedName) {
+			if _, err := c.Run("rename-window", "-t", window, original); err != nil {
+				return err
+			}
+			// rename-window turns automatic renaming off
+			if c.windowOption(window, optAutomaticName) == "1" {
+				if _, err := c.Run("set-option", "-w", "-t", window, "automatic-rename", "on"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, name := range []string{optStatus, optOriginalName, optMarkedName, optAutomaticName} {
+		if err := c.unsetWindowOption(window, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Bell rings the bell in pane, which flags its window in the status line
+func (c *Client) Bell(pane Pane) error {
+	tty, err := os.OpenFile(pane.TTY, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open pane tty: %w", err)
+	}
+	defer tty.Close()
+	_, err = tty.WriteString("\a")
+	return err
+}
+
+// Marker marks the tmux window of the Claude session running this process while it
+// waits for the user
+type Marker struct {
+	cfg    *config.Config
+	client *Client
+	paneID string // $TMUX_PANE
+	pid    int    // Process whose pane is marked
+}
+
+// NewMarker creates a marker for the pane of the current process
+func NewMarker(cfg *config.Config) *Marker {
+	return &Marker{
+		cfg:    cfg,
+		client: NewClient(""),
+		paneID: os.Getenv("TMUX_PANE"),
+		pid:    os.Getpid(),
+	}
+}
+
+// Mark marks the session's window for the statuses in the tmux config and clears the
+// mark for any other status. It does nothing outside tmux or when tmux marks are disabled.
+func (m *Marker) Mark(status analyzer.Status, cwd string) error {
+	t := m.cfg.Notifications.Tmux
+	if !t.Enabled || !m.active() {
+		return nil
+	}
+	if !matchesStatus(t.Statuses, status) {
+		return m.Clear(cwd)
+	}
+
+	pane, err := m.client.FindPane(m.paneID, m.pid, cwd)
+	if err != nil {
+		return err
+	}
+
+	mark := ""
+	if t.Rename {
+		statusInfo, _ := m.cfg.GetStatusInfo(string(status))
+		mark = statusEmoji(statusInfo.Title)
+	}
+	if err := m.client.MarkWindow(pane, string(status), mark); err != nil {
+		return err
+	}
+	if t.Bell {
+		if err := m.client.Bell(pane); err != nil {
+			logging.Warn("tmux: failed to ring the bell: %v", err)
+		}
+	}
+
+	logging.Debug("tmux: marked window %s (pane %s, status=%s)", pane.WindowID, pane.ID, status)
+	return nil
+}
+
+// Clear removes the mark from the session's window
+func (m *Marker) Clear(cwd string) error {
+	if !m.cfg.Notifications.Tmux.Enabled || !m.active() {
+		return nil
+	}
+
+	pane, err := m.client.FindPane(m.paneID, m.pid, cwd)
+	if err != nil {
+		return err
+	}
+	if err := m.client.ClearWindow(pane); err != nil {
+		return err
+	}
+
+	logging.Debug("tmux: cleared window %s (pane %s)", pane.WindowID, pane.ID)
+	return nil
+}
+
+// active reports whether there is a tmux server to talk to
+func (m *Marker) active() bool {
+	return m.client.socket != "" || InTmux()
+}
+
+// statusEmoji returns the leading emoji of a status title ("❓ Question" → "❓"),
+// or "●" when the title doesn't start with one
+func statusEmoji(title string) string {
+	fields := strings.Fields(title)
+	if len(fields) == 0 {
+		return "●"
+	}
+	for _, r := range fields[0] {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return "●"
+		}
+	}
+	return fields[0]
+}
+
+// matchesStatus reports whether status passes a statuses filter (empty = all)
+func matchesStatus(statuses []string, status analyzer.Status) bool {
+	if len(statuses) == 0 {
+		return true
+	}
+	for _, s := range statuses {
+		if s == string(status) {
+			return true
+		}
+	}
+	return false
+}
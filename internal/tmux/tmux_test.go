@@ -0,0 +1,289 @@
+package tmux
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+// startServer starts a private tmux server with one window named "editor" running in dir
+func startServer(t *testing.T, dir string) *Client {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("tmux is not available on Windows")
+	}
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+
+	socket := filepath.Join(t.TempDir(), "tmux.sock")
+	cmd := exec.Command("tmux", "-S", socket, "-f", "/dev/null", "new-session", "-d", "-s", "test",
+		"-n", "editor", "-c", dir, "sleep", "600")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to start tmux server: %v: %s", err, out)
+	}
+	client := NewClient(socket)
+	t.Cleanup(func() { _, _ = client.Run("kill-server") })
+	waitForPanes(t, client)
+	return client
+}
+
+// waitForPanes polls until the server lists its panes; `new-session -d` can return
+// before the pane shows up in list-panes
+func waitForPanes(t *testing.T, client *Client) []Pane {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		panes, err := client.ListPanes()
+		if err == nil && len(panes) > 0 {
+			return panes
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected a pane, got %v (%v)", panes, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func newTestMarker(t *testing.T, client *Client, configure func(c *config.TmuxConfig)) *Marker {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Notifications.Tmux.Enabled = true
+	if configure != nil {
+		configure(&cfg.Notifications.Tmux)
+	}
+
+	panes := waitForPanes(t, client)
+	if len(panes) != 1 {
+		t.Fatalf("Expected one pane, got %v", panes)
+	}
+	// The pane's own process stands in for Claude
+	return &Marker{cfg: cfg, client: client, pid: panes[0].PID}
+}
+
+func windowName(t *testing.T, client *Client) string {
+	t.Helper()
+	name, err := client.Run("display-message", "-p", "-t", "test:0", "#{window_name}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func windowStatus(t *testing.T, client *Client) string {
+	t.Helper()
+	return client.windowOption("test:0", optStatus)
+}
+
+func TestMarkAndClear(t *testing.T) {
+	dir := t.TempDir()
+	client := startServer(t, dir)
+	_, _ = client.Run("set-option", "-w", "-t", "test:0", "automatic-rename", "off")
+	marker := newTestMarker(t, client, nil)
+
+	if err := marker.Mark(analyzer.StatusQuestion, dir); err != nil {
+		t.Fatalf("Mark failed: %v", err)
+	}
+	if got := windowName(t, client); got != "❓ editor" {
+		t.Errorf("Expected window name %q, got %q", "❓ editor", got)
+	}
+	if got := windowStatus(t, client); got != "question" {
+		t.Errorf("Expected @claude_status question, got %q", got)
+	}
+
+	// A second mark replaces the emoji instead of stacking
+	if err := marker.Mark(analyzer.StatusPlanReady, dir); err != nil {
+		t.Fatalf("Mark failed: %v", err)
+	}
+	if got := windowName(t, client); got != "📋 editor" {
+		t.Errorf("Expected window name %q, got %q", "📋 editor", got)
+	}
+
+	if err := marker.Clear(dir); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if got := windowName(t, client); got != "editor" {
+		t.Errorf("Expected the original window name, got %q", got)
+	}
+	if got := windowStatus(t, client); got != "" {
+		t.Errorf("Expected @claude_status to be unset, got %q", got)
+	}
+}
+
+func TestMarkOtherStatusClears(t *testing.T) {
+	dir := t.TempDir()
+	client := startServer(t, dir)
+	marker := newTestMarker(t, client, nil)
+
+	_ = marker.Mark(analyzer.StatusQuestion, dir)
+	if err := marker.Mark(analyzer.StatusTaskComplete, dir); err != nil {
+		t.Fatalf("Mark failed: %v", err)
+	}
+	if got := windowStatus(t, client); got != "" {
+		t.Errorf("Expected task_complete to clear the mark, got %q", got)
+	}
+	if got := windowName(t, client); got != "editor" {
+		t.Errorf("Expected the original window name, got %q", got)
+	}
+}
+
+func TestClearRestoresAutomaticRename(t *testing.T) {
+	dir := t.TempDir()
+	client := startServer(t, dir)
+	marker := newTestMarker(t, client, nil)
+	// new-session -n turns automatic-rename off for the window; start from the default
+	_, _ = client.Run("set-option", "-w", "-t", "test:0", "automatic-rename", "on")
+
+	_ = marker.Mark(analyzer.StatusQuestion, dir)
+	if got, _ := client.Run("display-message", "-p", "-t", "test:0", "#{automatic-rename}"); got != "0" {
+		t.Errorf("Expected automatic-rename off while marked, got %q", got)
+	}
+	_ = marker.Clear(dir)
+	if got, _ := client.Run("display-message", "-p", "-t", "test:0", "#{automatic-rename}"); got != "1" {
+		t.Errorf("Expected automatic-rename to be restored, got %q", got)
+	}
+}
+
+func TestClearKeepsUserRename(t *testing.T) {
+	dir := t.TempDir()
+	client := startServer(t, dir)
+	marker := newTestMarker(t, client, nil)
+
+	_ = marker.Mark(analyzer.StatusQuestion, dir)
+	_, _ = client.Run("rename-window", "-t", "test:0", "mine")
+	if err := marker.Clear(dir); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if got := windowName(t, client); got != "mine" {
+		t.Errorf("Expected the user's window name to be kept, got %q", got)
+	}
+}
+
+func TestMarkWithoutRename(t *testing.T) {
+	dir := t.TempDir()
+	client := startServer(t, dir)
+	marker := newTestMarker(t, client, func(c *config.TmuxConfig) {
+		c.Rename = false
+		c.Bell = true
+	})
+
+	if err := marker.Mark(analyzer.StatusQuestion, dir); err != nil {
+		t.Fatalf("Mark failed: %v", err)
+	}
+	if got := windowName(t, client); got != "editor" {
+		t.Errorf("Expected the window name to be unchanged, got %q", got)
+	}
+	if got := windowStatus(t, client); got != "question" {
+		t.Errorf("Expected @claude_status question, got %q", got)
+	}
+}
+
+func TestMarkDisabled(t *testing.T) {
+	dir := t.TempDir()
+	client := startServer(t, dir)
+	marker := newTestMarker(t, client, func(c *config.TmuxConfig) { c.Enabled = false })
+
+	_ = marker.Mark(analyzer.StatusQuestion, dir)
+	if got := windowStatus(t, client); got != "" {
+		t.Errorf("Expected no mark when disabled, got %q", got)
+	}
+}
+
+func TestFindPaneByPaneID(t *testing.T) {
+	dir := t.TempDir()
+	client := startServer(t, dir)
+	panes := waitForPanes(t, client)
+
+	pane, err := client.FindPane(panes[0].ID, 0, "")
+	if err != nil || pane.ID != panes[0].ID {
+		t.Errorf("Expected pane %s, got %+v (%v)", panes[0].ID, pane, err)
+	}
+	if panes[0].Path != dir {
+		resolved, _ := filepath.EvalSymlinks(dir)
+		if panes[0].Path != resolved {
+			t.Errorf("Expected pane path %s, got %s", dir, panes[0].Path)
+		}
+	}
+}
+
+func TestFindPane(t *testing.T) {
+	panes := []Pane{
+		{ID: "%1", PID: 100, WindowID: "@1", Path: "/src/app"},
+		{ID: "%2", PID: 200, WindowID: "@2", Path: "/src/api"},
+		{ID: "%3", PID: 300, WindowID: "@3", Path: "/src/api"},
+	}
+	// 100 (shell in %1) -> 110 (claude) -> 120 (sh) -> 130 (hook)
+	parents := map[int]int{130: 120, 120: 110, 110: 100, 100: 1, 200: 1, 300: 1}
+
+	tests := []struct {
+		name   string
+		paneID string
+		pid    int
+		cwd    string
+		want   string
+	}{
+		{"TMUX_PANE wins", "%2", 130, "/src/app", "%2"},
+		{"ancestor pane", "", 130, "/src/api", "%1"},
+		{"unique cwd", "", 999, "/src/app", "%1"},
+		{"ambiguous cwd", "", 999, "/src/api", ""},
+		{"unknown pane ID falls back", "%9", 130, "", "%1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pane, ok := findPane(panes, parents, tt.paneID, tt.pid, tt.cwd)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("Expected no pane, got %s", pane.ID)
+				}
+				return
+			}
+			if !ok || pane.ID != tt.want {
+				t.Errorf("Expected pane %s, got %s (found=%v)", tt.want, pane.ID, ok)
+			}
+		})
+	}
+}
+
+func TestStatusEmoji(t *testing.T) {
+	tests := map[string]string{
+		"❓ Question":               "❓",
+		"⏱️ Session Limit Reached": "⏱️",
+		"Question":                 "●",
+		"":                         "●",
+	}
+	for title, want := range tests {
+		if got := statusEmoji(title); got != want {
+			t.Errorf("statusEmoji(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestClientPIDs(t *testing.T) {
+	dir := t.TempDir()
+	client := startServer(t, dir)
+	panes := waitForPanes(t, client)
+
+	// The test server has no attached client
+	pids, err := client.ClientPIDs(panes[0])
+	if err != nil {
+		t.Fatalf("ClientPIDs failed: %v", err)
+	}
+	if len(pids) != 0 {
+		t.Errorf("Expected no clients, got %v", pids)
+	}
+}
+
+func TestParseClients(t *testing.T) {
+	out := "4100\t%1\n4200\t%2\n4300\t%1\nbad\t%1\n"
+	if got := parseClients(out, "%1"); len(got) != 2 || got[0] != 4100 || got[1] != 4300 {
+		t.Errorf("Expected [4100 4300], got %v", got)
+	}
+	if got := parseClients(out, "%9"); len(got) != 0 {
+		t.Errorf("Expected no clients, got %v", got)
+	}
+}
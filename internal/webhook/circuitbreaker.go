@@ -5,6 +5,8 @@ import (
 	"errors"
 	"sync"
 	"time"
+
+	"github.com/777genius/claude-notifications/internal/logging"
 )
 
 // CircuitBreakerState represents the current state of the circuit breaker
@@ -29,11 +31,15 @@ type CircuitBreaker struct {
 	successThreshold int
 	timeout          time.Duration
 
-	mu              sync.RWMutex
+	mu              sync.Mutex
 	state           CircuitBreakerState
 	failureCount    int
 	successCount    int
 	lastStateChange time.Time
+
+	// Optional shared state (see UseStore)
+	store *StateStore
+	key   string
 }
 
 // NewCircuitBreaker creates a new circuit breaker
@@ -47,6 +53,14 @@ func NewCircuitBreaker(failureThreshold, successThreshold int, timeout time.Dura
 	}
 }
 
+// UseStore shares the breaker state with other processes through store, under key
+func (cb *CircuitBreaker) UseStore(store *StateStore, key string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.store = store
+	cb.key = key
+}
+
 // Execute runs the function through the circuit breaker
 func (cb *CircuitBreaker) Execute(ctx context.Context, fn func() error) error {
 	// Check current state
@@ -72,84 +86,129 @@ func (cb *CircuitBreaker) Execute(ctx context.Context, fn func() error) error {
 
 // getState returns the current state, potentially transitioning from Open to HalfOpen
 func (cb *CircuitBreaker) getState() CircuitBreakerState {
-	cb.mu.RLock()
-	state := cb.state
-	lastChange := cb.lastStateChange
-	cb.mu.RUnlock()
-
-	// If we're in Open state and timeout has passed, transition to HalfOpen
-	if state == StateOpen && time.Since(lastChange) >= cb.timeout {
-		cb.mu.Lock()
-		// Double-check after acquiring write lock
+	var state CircuitBreakerState
+	cb.sync(func() {
+		// If we're in Open state and timeout has passed, transition to HalfOpen
 		if cb.state == StateOpen && time.Since(cb.lastStateChange) >= cb.timeout {
 			cb.state = StateHalfOpen
 			cb.successCount = 0
 			cb.failureCount = 0
 			cb.lastStateChange = time.Now()
-			state = StateHalfOpen
 		}
-		cb.mu.Unlock()
-	}
-
+		state = cb.state
+	})
 	return state
 }
 
 // recordSuccess records a successful call
 func (cb *CircuitBreaker) recordSuccess() {
-	cb.mu.Lock()
-	defer cb.mu.Unlock()
+	cb.sync(func() {
+		switch cb.state {
+		case StateHalfOpen:
+			cb.successCount++
+			if cb.successCount >= cb.successThreshold {
+				// Transition to Closed
+				cb.state = StateClosed
+				cb.failureCount = 0
+				cb.successCount = 0
+				cb.lastStateChange = time.Now()
+			}
+		case StateClosed:
+			// Reset failure count on success
+			cb.failureCount = 0
+		}
+	})
+}
 
-	switch cb.state {
-	case StateHalfOpen:
-		cb.successCount++
-		if cb.successCount >= cb.successThreshold {
-			// Transition to Closed
-			cb.state = StateClosed
+// recordFailure records a failed call
+func (cb *CircuitBreaker) recordFailure() {
+	cb.sync(func() {
+		switch cb.state {
+		case StateHalfOpen:
+			// Any failure in HalfOpen immediately goes back to Open
+			cb.state = StateOpen
 			cb.failureCount = 0
 			cb.successCount = 0
 			cb.lastStateChange = time.Now()
+
+		case StateClosed:
+			cb.failureCount++
+			if cb.failureCount >= cb.failureThreshold {
+				// Transition to Open
+				cb.state = StateOpen
+				cb.failureCount = 0
+				cb.lastStateChange = time.Now()
+			}
 		}
-	case StateClosed:
-		// Reset failure count on success
-		cb.failureCount = 0
-	}
+	})
 }
 
-// recordFailure records a failed call
-func (cb *CircuitBreaker) recordFailure() {
+// sync runs fn under the mutex. With a store, fn starts from the shared state
+// and its changes are written back while the file lock is held.
+func (cb *CircuitBreaker) sync(fn func()) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
-	switch cb.state {
-	case StateHalfOpen:
-		// Any failure in HalfOpen immediately goes back to Open
-		cb.state = StateOpen
-		cb.failureCount = 0
-		cb.successCount = 0
-		cb.lastStateChange = time.Now()
+	if cb.store == nil {
+		fn()
+		return
+	}
 
-	case StateClosed:
-		cb.failureCount++
-		if cb.failureCount >= cb.failureThreshold {
-			// Transition to Open
-			cb.state = StateOpen
-			cb.failureCount = 0
-			cb.lastStateChange = time.Now()
+	ran := false
+	err := cb.store.update(cb.key, func(st *storedState) {
+		cb.restore(st.Breaker)
+		fn()
+		ran = true
+		st.Breaker = &breakerState{
+			State:           cb.state,
+			Failures:        cb.failureCount,
+			Successes:       cb.successCount,
+			LastStateChange: cb.lastStateChange,
 		}
+	})
+	if err != nil {
+		// Fall back to this process's view rather than dropping the update
+		logging.Warn("Circuit breaker state not shared: %v", err)
+		if !ran {
+			fn()
+		}
+	}
+}
+
+// refresh loads the shared state, if any (caller holds the mutex)
+func (cb *CircuitBreaker) refresh() {
+	if cb.store == nil {
+		return
+	}
+	if st := cb.store.get(cb.key); st != nil {
+		cb.restore(st.Breaker)
+	}
+}
+
+// restore replaces the in-memory state with a stored one (caller holds the mutex)
+func (cb *CircuitBreaker) restore(st *breakerState) {
+	if st == nil {
+		return
 	}
+	cb.state = st.State
+	cb.failureCount = st.Failures
+	cb.successCount = st.Successes
+	cb.lastStateChange = st.LastStateChange
 }
 
 // GetState returns the current state (for monitoring/metrics)
 func (cb *CircuitBreaker) GetState() CircuitBreakerState {
-	cb.mu.RLock()
-	defer cb.mu.RUnlock()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.refresh()
 	return cb.state
 }
 
 // GetStats returns current statistics
 func (cb *CircuitBreaker) GetStats() (state CircuitBreakerState, failures, successes int) {
-	cb.mu.RLock()
-	defer cb.mu.RUnlock()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.refresh()
 	return cb.state, cb.failureCount, cb.successCount
 }
 
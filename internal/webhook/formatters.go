@@ -1,7 +1,17 @@
 package webhook
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"html"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/777genius/claude-notifications/internal/analyzer"
@@ -13,6 +23,32 @@ type Formatter interface {
 	Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo) (interface{}, error)
 }
 
+// HeaderFormatter is implemented by formatters of platforms that take the title and other
+// metadata in request headers. Headers depend only on the status and config, so they can
+// be rebuilt when an outbox entry is redelivered.
+type HeaderFormatter interface {
+	Headers(status analyzer.Status, statusInfo config.StatusInfo) map[string]string
+}
+
+// ContextFormatter is implemented by formatters that show the project context
+// (folder, branch, host) separately instead of relying on the message prefix
+type ContextFormatter interface {
+	FormatContext(data TemplateData) (interface{}, error)
+}
+
+// EndpointFormatter is implemented by formatters of APIs that are not called with a POST
+// to the configured URL. The request ID stays the same across retries and outbox
+// redeliveries, so APIs can use it for idempotency.
+type EndpointFormatter interface {
+	Endpoint(baseURL, requestID string) (method, url string)
+}
+
+// ResponseFormatter is implemented by formatters of APIs that report errors in the
+// body of a 2xx response instead of with an HTTP status
+type ResponseFormatter interface {
+	CheckResponse(body []byte) error
+}
+
 // SlackFormatter formats messages for Slack
 type SlackFormatter struct{}
 
@@ -177,3 +213,533 @@ func getLarkColorTemplate(status analyzer.Status) string {
 		return "grey"
 	}
 }
+
+// NtfyFormatter formats messages for ntfy: the message is the plain text body,
+// everything else goes into headers
+type NtfyFormatter struct {
+	Config config.NtfyConfig
+}
+
+func (f *NtfyFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo) (interface{}, error) {
+	return message, nil
+}
+
+func (f *NtfyFormatter) Headers(status analyzer.Status, statusInfo config.StatusInfo) map[string]string {
+	headers := map[string]string{
+		// Header values must be ASCII; ntfy decodes RFC 2047 encoded words
+		"X-Title":    mime.BEncoding.Encode("utf-8", statusInfo.Title),
+		"X-Priority": getNtfyPriority(status),
+		"X-Tags":     getNtfyTag(status),
+	}
+	if f.Config.Click != "" {
+		headers["X-Click"] = f.Config.Click
+	}
+	if f.Config.Actions != "" {
+		headers["X-Actions"] = mime.BEncoding.Encode("utf-8", f.Config.Actions)
+	}
+
+	switch {
+	case f.Config.Token != "":
+		headers["Authorization"] = "Bearer " + f.Config.Token
+	case f.Config.Username != "":
+		credentials := f.Config.Username + ":" + f.Config.Password
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	}
+	return headers
+}
+
+// getNtfyPriority returns the ntfy priority for status: high when Claude is waiting or failed
+func getNtfyPriority(status analyzer.Status) string {
+	switch status {
+	case analyzer.StatusQuestion, analyzer.StatusAPIError:
+		return "high"
+	default:
+		return "default"
+	}
+}
+
+// getNtfyTag returns the ntfy tag for status; ntfy shows emoji shortcodes as icons
+func getNtfyTag(status analyzer.Status) string {
+	switch status {
+	case analyzer.StatusTaskComplete:
+		return "white_check_mark"
+	case analyzer.StatusReviewComplete:
+		return "mag"
+	case analyzer.StatusQuestion:
+		return "question"
+	case analyzer.StatusPlanReady:
+		return "clipboard"
+	case analyzer.StatusSessionLimitReached:
+		return "hourglass"
+	case analyzer.StatusAPIError:
+		return "rotating_light"
+	default:
+		return "information_source"
+	}
+}
+
+// GotifyFormatter formats messages for Gotify; the app token goes in the X-Gotify-Key header
+type GotifyFormatter struct {
+	Config config.GotifyConfig
+}
+
+func (f *GotifyFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo) (interface{}, error) {
+	return map[string]interface{}{
+		"title":    statusInfo.Title,
+		"message":  message,
+		"priority": getGotifyPriority(status),
+		"extras": map[string]interface{}{
+			"client::display": map[string]interface{}{
+				"contentType": "text/plain",
+			},
+		},
+	}, nil
+}
+
+func (f *GotifyFormatter) Headers(status analyzer.Status, statusInfo config.StatusInfo) map[string]string {
+	return map[string]string{
+		"X-Gotify-Key": f.Config.Token,
+	}
+}
+
+// getGotifyPriority returns the Gotify priority (0-10) for status.
+// The Android app pops up notifications from 8, and plays a sound from 4.
+func getGotifyPriority(status analyzer.Status) int {
+	switch status {
+	case analyzer.StatusQuestion, analyzer.StatusAPIError, analyzer.StatusSessionLimitReached:
+		return 8
+	default:
+		return 5
+	}
+}
+
+// PushoverFormatter formats messages for the Pushover message API
+type PushoverFormatter struct {
+	Config config.PushoverConfig
+}
+
+func (f *PushoverFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo) (interface{}, error) {
+	priority := getPushoverPriority(status)
+
+	payload := map[string]interface{}{
+		"token":    f.Config.Token,
+		"user":     f.Config.User,
+		"title":    statusInfo.Title,
+		"message":  message,
+		"priority": priority,
+	}
+	// Emergency priority repeats until acknowledged or expired
+	if priority == pushoverEmergency {
+		payload["retry"] = f.Config.Retry
+		payload["expire"] = f.Config.Expire
+	}
+	if f.Config.Device != "" {
+		payload["device"] = f.Config.Device
+	}
+	if f.Config.Sound != "" {
+		payload["sound"] = f.Config.Sound
+	}
+	return payload, nil
+}
+
+// pushoverEmergency is Pushover's emergency priority, which needs retry and expire
+const pushoverEmergency = 2
+
+// getPushoverPriority returns the Pushover priority (-2 to 2) for status
+func getPushoverPriority(status analyzer.Status) int {
+	switch status {
+	case analyzer.StatusSessionLimitReached:
+		return pushoverEmergency
+	case analyzer.StatusQuestion, analyzer.StatusAPIError:
+		return 1 // High: bypasses quiet hours
+	default:
+		return 0
+	}
+}
+
+// TeamsFormatter formats messages for Microsoft Teams incoming webhooks and
+// Workflows as an Adaptive Card
+type TeamsFormatter struct{}
+
+func (f *TeamsFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo) (interface{}, error) {
+	return f.FormatContext(TemplateData{
+		Status:    string(status),
+		Title:     statusInfo.Title,
+		Message:   message,
+		SessionID: sessionID,
+	})
+}
+
+func (f *TeamsFormatter) FormatContext(data TemplateData) (interface{}, error) {
+	var facts []map[string]string
+	for _, fact := range [][2]string{
+		{"Project", data.Folder},
+		{"Branch", data.GitBranch},
+		{"Session", data.SessionName},
+		{"Host", data.Hostname},
+	} {
+		if fact[1] != "" {
+			facts = append(facts, map[string]string{"title": fact[0], "value": escapeTeamsMarkdown(fact[1])})
+		}
+	}
+
+	body := []map[string]interface{}{
+		{
+			// Colored header
+			"type":  "Container",
+			"style": getTeamsContainerStyle(analyzer.Status(data.Status)),
+			"bleed": true,
+			"items": []map[string]interface{}{
+				{
+					"type":   "TextBlock",
+					"text":   escapeTeamsMarkdown(data.Title),
+					"weight": "Bolder",
+					"size":   "Medium",
+					"wrap":   true,
+				},
+			},
+		},
+		{
+			"type": "TextBlock",
+			"text": escapeTeamsMarkdown(data.Message),
+			"wrap": true,
+		},
+	}
+	if len(facts) > 0 {
+		body = append(body, map[string]interface{}{
+			"type":  "FactSet",
+			"facts": facts,
+		})
+	}
+
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"contentUrl":  nil,
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"msteams": map[string]interface{}{"width": "Full"},
+					"body":    body,
+				},
+			},
+		},
+	}, nil
+}
+
+// getTeamsContainerStyle returns the Adaptive Card container style (header color) for status
+func getTeamsContainerStyle(status analyzer.Status) string {
+	switch status {
+	case analyzer.StatusTaskComplete:
+		return "good" // Green
+	case analyzer.StatusReviewComplete, analyzer.StatusPlanReady:
+		return "accent" // Blue
+	case analyzer.StatusQuestion:
+		return "warning" // Yellow
+	case analyzer.StatusSessionLimitReached, analyzer.StatusAPIError:
+		return "attention" // Red
+	default:
+		return "emphasis" // Gray
+	}
+}
+
+// teamsMarkdownEscaper escapes characters that Adaptive Card TextBlocks in Teams
+// would otherwise render as markdown
+var teamsMarkdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"~", `\~`,
+	"[", `\[`,
+	"]", `\]`,
+	"#", `\#`,
+	"|", `\|`,
+)
+
+// escapeTeamsMarkdown makes text render literally in a Teams TextBlock. Lines that would start
+// a list are escaped too, and single newlines become paragraph breaks because TextBlock
+// markdown joins lines otherwise.
+func escapeTeamsMarkdown(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		line = teamsMarkdownEscaper.Replace(line)
+
+		trimmed := strings.TrimLeft(line, " ")
+		indent := line[:len(line)-len(trimmed)]
+		switch {
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "+ "):
+			line = indent + `\` + trimmed
+		case orderedListPrefix(trimmed) > 0:
+			n := orderedListPrefix(trimmed)
+			line = indent + trimmed[:n] + `\` + trimmed[n:]
+		}
+		lines[i] = line
+	}
+
+	var out []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n\n")
+}
+
+// orderedListPrefix returns the number of digits if line starts like an ordered
+// list item ("1. "), or 0
+func orderedListPrefix(line string) int {
+	n := 0
+	for n < len(line) && line[n] >= '0' && line[n] <= '9' {
+		n++
+	}
+	if n > 0 && strings.HasPrefix(line[n:], ". ") {
+		return n
+	}
+	return 0
+}
+
+// MattermostFormatter formats messages for Mattermost incoming webhooks with
+// Slack-compatible attachments
+type MattermostFormatter struct {
+	Config config.MattermostConfig
+}
+
+func (f *MattermostFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo) (interface{}, error) {
+	username := f.Config.Username
+	if username == "" {
+		username = "Claude Code"
+	}
+
+	payload := map[string]interface{}{
+		"username": username,
+		"attachments": []map[string]interface{}{
+			{
+				"fallback": fmt.Sprintf("%s: %s", statusInfo.Title, message),
+				"color":    getColorForStatus(status),
+				"title":    statusInfo.Title,
+				"text":     message,
+				"footer":   fmt.Sprintf("Session: %s | Claude Notifications", sessionID),
+			},
+		},
+	}
+	if f.Config.Channel != "" {
+		payload["channel"] = f.Config.Channel
+	}
+	if f.Config.IconURL != "" {
+		payload["icon_url"] = f.Config.IconURL
+	}
+	return payload, nil
+}
+
+// RocketChatFormatter formats messages for Rocket.Chat incoming webhooks with attachments
+type RocketChatFormatter struct {
+	Config config.RocketChatConfig
+}
+
+func (f *RocketChatFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo) (interface{}, error) {
+	alias := f.Config.Alias
+	if alias == "" {
+		alias = "Claude Code"
+	}
+
+	payload := map[string]interface{}{
+		"alias": alias,
+		"attachments": []map[string]interface{}{
+			{
+				"title": statusInfo.Title,
+				"text":  message,
+				"color": getColorForStatus(status),
+				"ts":    time.Now().Format(time.RFC3339),
+				"fields": []map[string]interface{}{
+					{"short": true, "title": "Session", "value": sessionID},
+				},
+			},
+		},
+	}
+	if f.Config.Channel != "" {
+		payload["channel"] = f.Config.Channel
+	}
+	if f.Config.Emoji != "" {
+		payload["emoji"] = f.Config.Emoji
+	}
+	return payload, nil
+}
+
+// GoogleChatFormatter formats messages for Google Chat space webhooks as a card.
+// Cards have no accent color, so the status line is colored with a font tag.
+type GoogleChatFormatter struct{}
+
+func (f *GoogleChatFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo) (interface{}, error) {
+	statusLine := fmt.Sprintf(`<font color="%s"><b>%s</b></font>`,
+		getColorForStatus(status), html.EscapeString(statusInfo.Title))
+
+	return map[string]interface{}{
+		"cardsV2": []map[string]interface{}{
+			{
+				"cardId": "claude-notification",
+				"card": map[string]interface{}{
+					"header": map[string]interface{}{
+						"title":    "Claude Code",
+						"subtitle": fmt.Sprintf("Session: %s", sessionID),
+					},
+					"sections": []map[string]interface{}{
+						{
+							"widgets": []map[string]interface{}{
+								{"textParagraph": map[string]string{"text": statusLine}},
+								{"textParagraph": map[string]string{"text": escapeHTMLText(message)}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// escapeHTMLText escapes text for platforms that render a subset of HTML
+// (Google Chat card text, Matrix formatted_body) and keeps its line breaks
+func escapeHTMLText(text string) string {
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
+
+// MatrixFormatter formats messages as m.room.message events for the Matrix
+// client-server API. The configured URL is the homeserver.
+type MatrixFormatter struct {
+	Config config.MatrixConfig
+}
+
+func (f *MatrixFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo) (interface{}, error) {
+	formatted := fmt.Sprintf(`<font data-mx-color="%s"><b>%s</b></font><br><br>%s<br><br><i>Session: %s</i>`,
+		getColorForStatus(status), html.EscapeString(statusInfo.Title), escapeHTMLText(message), html.EscapeString(sessionID))
+
+	// m.notice marks the message as sent by a bot, so other bots don't reply to it
+	return map[string]interface{}{
+		"msgtype":        "m.notice",
+		"body":           fmt.Sprintf("%s\n\n%s\n\nSession: %s", statusInfo.Title, message, sessionID),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": formatted,
+	}, nil
+}
+
+func (f *MatrixFormatter) Headers(status analyzer.Status, statusInfo config.StatusInfo) map[string]string {
+	return map[string]string{"Authorization": "Bearer " + f.Config.AccessToken}
+}
+
+// Endpoint returns the send URL of the room. The request ID is the transaction ID,
+// so the homeserver ignores retries of an event it has already stored.
+func (f *MatrixFormatter) Endpoint(baseURL, requestID string) (string, string) {
+	return http.MethodPut, fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimRight(baseURL, "/"), url.PathEscape(f.Config.RoomID), url.PathEscape(requestID))
+}
+
+// DingTalkFormatter formats messages for DingTalk custom robots as markdown.
+// With a secret, requests are signed with timestamp and sign query parameters.
+type DingTalkFormatter struct {
+	Config config.DingTalkConfig
+}
+
+func (f *DingTalkFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo) (interface{}, error) {
+	// Robots with keyword security drop messages that don't contain a keyword
+	title := statusInfo.Title
+	if f.Config.Keyword != "" {
+		title = f.Config.Keyword + " " + title
+	}
+
+	return map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": title,
+			"text":  fmt.Sprintf("#### %s\n\n%s\n\n> Session: %s", title, message, sessionID),
+		},
+	}, nil
+}
+
+// Endpoint adds a fresh signature to the webhook URL; DingTalk rejects timestamps older than an hour
+func (f *DingTalkFormatter) Endpoint(baseURL, requestID string) (string, string) {
+	if f.Config.Secret == "" {
+		return http.MethodPost, baseURL
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return http.MethodPost, baseURL
+	}
+
+	timestamp := time.Now().UnixMilli()
+	query := u.Query()
+	query.Set("timestamp", strconv.FormatInt(timestamp, 10))
+	query.Set("sign", dingTalkSign(f.Config.Secret, timestamp))
+	u.RawQuery = query.Encode()
+	return http.MethodPost, u.String()
+}
+
+func (f *DingTalkFormatter) CheckResponse(body []byte) error {
+	return checkErrcode(body, 130101) // send too fast
+}
+
+// dingTalkSign returns base64(HMAC-SHA256("<timestamp>\n<secret>")) keyed with the secret
+func dingTalkSign(secret string, timestamp int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d\n%s", timestamp, secret)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// wecomMaxContent is the maximum size of WeCom markdown content in bytes
+const wecomMaxContent = 4096
+
+// WeComFormatter formats messages for WeCom (WeChat Work) group robots as markdown
+type WeComFormatter struct{}
+
+func (f *WeComFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo) (interface{}, error) {
+	format := "<font color=\"" + getWeComColor(status) + "\">**%s**</font>\n%s\n> Session: <font color=\"comment\">%s</font>"
+
+	// Shorten the message, not the title and session, when the content is too long
+	overhead := len(fmt.Sprintf(format, statusInfo.Title, "", sessionID))
+	if len(message)+overhead > wecomMaxContent {
+		limit := wecomMaxContent - overhead - len("...")
+		if limit < 0 {
+			limit = 0
+		}
+		message = strings.ToValidUTF8(message[:limit], "") + "..."
+	}
+
+	return map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"content": fmt.Sprintf(format, statusInfo.Title, message, sessionID),
+		},
+	}, nil
+}
+
+func (f *WeComFormatter) CheckResponse(body []byte) error {
+	return checkErrcode(body, 45009) // API frequency limit
+}
+
+// getWeComColor returns the WeCom markdown font color for status;
+// WeCom only has info (green), comment (gray) and warning (orange)
+func getWeComColor(status analyzer.Status) string {
+	switch status {
+	case analyzer.StatusTaskComplete, analyzer.StatusReviewComplete, analyzer.StatusPlanReady:
+		return "info"
+	case analyzer.StatusQuestion, analyzer.StatusSessionLimitReached, analyzer.StatusAPIError:
+		return "warning"
+	default:
+		return "comment"
+	}
+}
+
+// checkErrcode returns the error in an {"errcode": ..., "errmsg": ...} response body,
+// as returned by DingTalk and WeCom with HTTP 200
+func checkErrcode(body []byte, rateLimitCode int) error {
+	var resp struct {
+		Errcode int    `json:"errcode"`
+		Errmsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Errcode == 0 {
+		return nil
+	}
+	return &APIError{Code: resp.Errcode, Message: resp.Errmsg, RateLimited: resp.Errcode == rateLimitCode}
+}
@@ -2,8 +2,11 @@ package webhook
 
 import (
 	"encoding/json"
+	"net/url"
+	"strconv"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/777genius/claude-notifications/internal/analyzer"
 	"github.com/777genius/claude-notifications/internal/config"
@@ -568,3 +571,433 @@ func TestGetLarkColorTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestNtfyFormatterFormat(t *testing.T) {
+	formatter := &NtfyFormatter{}
+
+	result, err := formatter.Format(analyzer.StatusTaskComplete, "Build finished", "session-123", config.StatusInfo{Title: "Done"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != "Build finished" {
+		t.Errorf("Expected the message as plain text body, got %v", result)
+	}
+}
+
+func TestNtfyFormatterHeaders(t *testing.T) {
+	formatter := &NtfyFormatter{Config: config.NtfyConfig{
+		Click:   "https://github.com/acme/app",
+		Actions: "view, Open repo, https://github.com/acme/app",
+	}}
+
+	headers := formatter.Headers(analyzer.StatusQuestion, config.StatusInfo{Title: "Question"})
+	if headers["X-Title"] != "Question" {
+		t.Errorf("Expected ASCII title unchanged, got %q", headers["X-Title"])
+	}
+	if headers["X-Priority"] != "high" || headers["X-Tags"] != "question" {
+		t.Errorf("Unexpected priority/tags for question: %v", headers)
+	}
+	if headers["X-Click"] != "https://github.com/acme/app" || headers["X-Actions"] == "" {
+		t.Errorf("Expected click and actions headers, got %v", headers)
+	}
+	if _, ok := headers["Authorization"]; ok {
+		t.Error("Expected no Authorization header without credentials")
+	}
+
+	headers = formatter.Headers(analyzer.StatusTaskComplete, config.StatusInfo{Title: "✅ Completed"})
+	if headers["X-Priority"] != "default" || headers["X-Tags"] != "white_check_mark" {
+		t.Errorf("Unexpected priority/tags for task_complete: %v", headers)
+	}
+	if !strings.HasPrefix(headers["X-Title"], "=?utf-8?b?") {
+		t.Errorf("Expected non-ASCII title to be RFC 2047 encoded, got %q", headers["X-Title"])
+	}
+
+	if p := getNtfyPriority(analyzer.StatusAPIError); p != "high" {
+		t.Errorf("Expected high priority for api_error, got %s", p)
+	}
+}
+
+func TestNtfyFormatterAuth(t *testing.T) {
+	bearer := &NtfyFormatter{Config: config.NtfyConfig{Token: "tk_abc"}}
+	if got := bearer.Headers(analyzer.StatusTaskComplete, config.StatusInfo{})["Authorization"]; got != "Bearer tk_abc" {
+		t.Errorf("Expected bearer auth, got %q", got)
+	}
+
+	basic := &NtfyFormatter{Config: config.NtfyConfig{Username: "phil", Password: "mypass"}}
+	if got := basic.Headers(analyzer.StatusTaskComplete, config.StatusInfo{})["Authorization"]; got != "Basic cGhpbDpteXBhc3M=" {
+		t.Errorf("Expected basic auth, got %q", got)
+	}
+}
+
+func TestGotifyFormatter(t *testing.T) {
+	formatter := &GotifyFormatter{Config: config.GotifyConfig{Token: "AbCd"}}
+
+	result, err := formatter.Format(analyzer.StatusQuestion, "Which database?", "session-123", config.StatusInfo{Title: "Question"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	payload := result.(map[string]interface{})
+	if payload["title"] != "Question" || payload["message"] != "Which database?" {
+		t.Errorf("Unexpected payload: %v", payload)
+	}
+	if payload["priority"] != 8 {
+		t.Errorf("Expected priority 8 for question, got %v", payload["priority"])
+	}
+	if _, ok := payload["token"]; ok {
+		t.Error("Expected the token to stay out of the body")
+	}
+
+	if headers := formatter.Headers(analyzer.StatusQuestion, config.StatusInfo{}); headers["X-Gotify-Key"] != "AbCd" {
+		t.Errorf("Expected token in X-Gotify-Key, got %v", headers)
+	}
+	if p := getGotifyPriority(analyzer.StatusTaskComplete); p != 5 {
+		t.Errorf("Expected priority 5 for task_complete, got %d", p)
+	}
+}
+
+func TestPushoverFormatter(t *testing.T) {
+	formatter := &PushoverFormatter{Config: config.PushoverConfig{
+		Token:  "app-token",
+		User:   "user-key",
+		Device: "phone",
+		Retry:  60,
+		Expire: 3600,
+	}}
+
+	tests := []struct {
+		status    analyzer.Status
+		priority  int
+		emergency bool
+	}{
+		{analyzer.StatusTaskComplete, 0, false},
+		{analyzer.StatusPlanReady, 0, false},
+		{analyzer.StatusQuestion, 1, false},
+		{analyzer.StatusAPIError, 1, false},
+		{analyzer.StatusSessionLimitReached, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			result, err := formatter.Format(tt.status, "msg", "session-123", config.StatusInfo{Title: "Title"})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			payload := result.(map[string]interface{})
+
+			if payload["priority"] != tt.priority {
+				t.Errorf("Expected priority %d, got %v", tt.priority, payload["priority"])
+			}
+			if payload["token"] != "app-token" || payload["user"] != "user-key" || payload["device"] != "phone" {
+				t.Errorf("Expected token, user and device in payload, got %v", payload)
+			}
+
+			_, hasRetry := payload["retry"]
+			_, hasExpire := payload["expire"]
+			if hasRetry != tt.emergency || hasExpire != tt.emergency {
+				t.Errorf("Expected retry/expire only for emergency priority, got %v", payload)
+			}
+			if tt.emergency && (payload["retry"] != 60 || payload["expire"] != 3600) {
+				t.Errorf("Expected retry 60 and expire 3600, got %v", payload)
+			}
+		})
+	}
+}
+
+func TestTeamsFormatterCard(t *testing.T) {
+	formatter := &TeamsFormatter{}
+
+	result, err := formatter.FormatContext(TemplateData{
+		Status:      "question",
+		Title:       "❓ Question",
+		Message:     "Which database?",
+		SessionName: "bold-cat",
+		Folder:      "my_app",
+		Hostname:    "dev-laptop",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Round-trip through JSON to inspect the card as Teams receives it
+	data, _ := json.Marshal(result)
+	var msg struct {
+		Type        string `json:"type"`
+		Attachments []struct {
+			ContentType string `json:"contentType"`
+			Content     struct {
+				Type    string                   `json:"type"`
+				Version string                   `json:"version"`
+				Body    []map[string]interface{} `json:"body"`
+			} `json:"content"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+
+	if msg.Type != "message" || len(msg.Attachments) != 1 {
+		t.Fatalf("Expected a message with one attachment, got %s", data)
+	}
+	card := msg.Attachments[0]
+	if card.ContentType != "application/vnd.microsoft.card.adaptive" || card.Content.Type != "AdaptiveCard" {
+		t.Errorf("Expected an Adaptive Card attachment, got %s", data)
+	}
+
+	body := card.Content.Body
+	if len(body) != 3 {
+		t.Fatalf("Expected header, message and facts, got %d elements", len(body))
+	}
+	if body[0]["type"] != "Container" || body[0]["style"] != "warning" {
+		t.Errorf("Expected a warning-styled header for question, got %v", body[0])
+	}
+	if body[1]["text"] != "Which database?" {
+		t.Errorf("Unexpected message block: %v", body[1])
+	}
+
+	facts, _ := body[2]["facts"].([]interface{})
+	if len(facts) != 3 {
+		t.Fatalf("Expected facts for project, session and host (no branch), got %v", body[2])
+	}
+	project := facts[0].(map[string]interface{})
+	if project["title"] != "Project" || project["value"] != `my\_app` {
+		t.Errorf("Expected escaped project fact, got %v", project)
+	}
+}
+
+func TestTeamsContainerStyle(t *testing.T) {
+	tests := map[analyzer.Status]string{
+		analyzer.StatusTaskComplete:        "good",
+		analyzer.StatusPlanReady:           "accent",
+		analyzer.StatusQuestion:            "warning",
+		analyzer.StatusSessionLimitReached: "attention",
+		analyzer.StatusAPIError:            "attention",
+	}
+	for status, want := range tests {
+		if got := getTeamsContainerStyle(status); got != want {
+			t.Errorf("getTeamsContainerStyle(%s) = %s, want %s", status, got, want)
+		}
+	}
+}
+
+func TestEscapeTeamsMarkdown(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain text", "plain text"},
+		{"fix **bold** and _snake_case_", `fix \*\*bold\*\* and \_snake\_case\_`},
+		{"see [docs](url) and `code`", "see \\[docs\\](url) and \\`code\\`"},
+		{`C:\path`, `C:\\path`},
+		{"# not a heading", `\# not a heading`},
+		{"Changes:\n- one\n2. two", "Changes:\n\n\\- one\n\n2\\. two"},
+		{"first\r\n\r\nsecond", "first\n\nsecond"},
+	}
+	for _, tt := range tests {
+		if got := escapeTeamsMarkdown(tt.in); got != tt.want {
+			t.Errorf("escapeTeamsMarkdown(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMattermostFormatter(t *testing.T) {
+	formatter := &MattermostFormatter{Config: config.MattermostConfig{Channel: "town-square"}}
+
+	result, err := formatter.Format(analyzer.StatusQuestion, "Which database?", "session-123", config.StatusInfo{Title: "Question"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	payload := result.(map[string]interface{})
+
+	if payload["username"] != "Claude Code" || payload["channel"] != "town-square" {
+		t.Errorf("Expected default username and channel override, got %v", payload)
+	}
+	if _, ok := payload["icon_url"]; ok {
+		t.Error("icon_url should be omitted when not configured")
+	}
+
+	attachment := payload["attachments"].([]map[string]interface{})[0]
+	if attachment["color"] != getColorForStatus(analyzer.StatusQuestion) {
+		t.Errorf("Expected status color, got %v", attachment["color"])
+	}
+	if attachment["title"] != "Question" || attachment["text"] != "Which database?" {
+		t.Errorf("Unexpected attachment: %v", attachment)
+	}
+	if attachment["fallback"] != "Question: Which database?" {
+		t.Errorf("Expected fallback with title and message, got %v", attachment["fallback"])
+	}
+}
+
+func TestRocketChatFormatter(t *testing.T) {
+	formatter := &RocketChatFormatter{Config: config.RocketChatConfig{Channel: "#dev", Emoji: ":robot:"}}
+
+	result, err := formatter.Format(analyzer.StatusTaskComplete, "Done", "session-123", config.StatusInfo{Title: "Completed"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	payload := result.(map[string]interface{})
+
+	if payload["alias"] != "Claude Code" || payload["channel"] != "#dev" || payload["emoji"] != ":robot:" {
+		t.Errorf("Expected alias, channel and emoji, got %v", payload)
+	}
+
+	attachment := payload["attachments"].([]map[string]interface{})[0]
+	if attachment["color"] != "#28a745" || attachment["title"] != "Completed" || attachment["text"] != "Done" {
+		t.Errorf("Unexpected attachment: %v", attachment)
+	}
+	fields := attachment["fields"].([]map[string]interface{})
+	if len(fields) != 1 || fields[0]["value"] != "session-123" {
+		t.Errorf("Expected session field, got %v", fields)
+	}
+}
+
+func TestGoogleChatFormatter(t *testing.T) {
+	formatter := &GoogleChatFormatter{}
+
+	result, err := formatter.Format(analyzer.StatusPlanReady, "Plan for <main.go>\nStep 1", "session-123", config.StatusInfo{Title: "Plan Ready"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	card := result.(map[string]interface{})["cardsV2"].([]map[string]interface{})[0]["card"].(map[string]interface{})
+
+	header := card["header"].(map[string]interface{})
+	if header["subtitle"] != "Session: session-123" {
+		t.Errorf("Expected the session in the header, got %v", header)
+	}
+
+	widgets := card["sections"].([]map[string]interface{})[0]["widgets"].([]map[string]interface{})
+	statusLine := widgets[0]["textParagraph"].(map[string]string)["text"]
+	if statusLine != `<font color="#007bff"><b>Plan Ready</b></font>` {
+		t.Errorf("Expected a status line in the status color, got %s", statusLine)
+	}
+	text := widgets[1]["textParagraph"].(map[string]string)["text"]
+	if text != "Plan for &lt;main.go&gt;<br>Step 1" {
+		t.Errorf("Expected escaped message with line breaks, got %s", text)
+	}
+}
+
+func TestMatrixFormatter(t *testing.T) {
+	formatter := &MatrixFormatter{Config: config.MatrixConfig{RoomID: "!abc/123:example.com", AccessToken: "syt_token"}}
+
+	result, err := formatter.Format(analyzer.StatusQuestion, "Use <b>Postgres</b>?\nOr SQLite", "session-123", config.StatusInfo{Title: "Question"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	payload := result.(map[string]interface{})
+
+	if payload["msgtype"] != "m.notice" || payload["format"] != "org.matrix.custom.html" {
+		t.Errorf("Expected an HTML m.notice, got %v", payload)
+	}
+	if payload["body"] != "Question\n\nUse <b>Postgres</b>?\nOr SQLite\n\nSession: session-123" {
+		t.Errorf("Unexpected plain body: %q", payload["body"])
+	}
+	formatted := payload["formatted_body"].(string)
+	if !strings.Contains(formatted, `<font data-mx-color="#ffc107"><b>Question</b></font>`) {
+		t.Errorf("Expected colored title, got %s", formatted)
+	}
+	if !strings.Contains(formatted, "Use &lt;b&gt;Postgres&lt;/b&gt;?<br>Or SQLite") {
+		t.Errorf("Expected escaped message with line breaks, got %s", formatted)
+	}
+
+	if auth := formatter.Headers(analyzer.StatusQuestion, config.StatusInfo{})["Authorization"]; auth != "Bearer syt_token" {
+		t.Errorf("Expected bearer token, got %q", auth)
+	}
+
+	method, url := formatter.Endpoint("https://matrix.example.com/", "req-1")
+	if method != "PUT" {
+		t.Errorf("Expected PUT, got %s", method)
+	}
+	if url != "https://matrix.example.com/_matrix/client/v3/rooms/%21abc%2F123:example.com/send/m.room.message/req-1" {
+		t.Errorf("Unexpected endpoint: %s", url)
+	}
+}
+
+func TestDingTalkFormatter(t *testing.T) {
+	formatter := &DingTalkFormatter{Config: config.DingTalkConfig{Keyword: "Claude"}}
+
+	result, err := formatter.Format(analyzer.StatusTaskComplete, "Done", "session-123", config.StatusInfo{Title: "Completed"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	payload := result.(map[string]interface{})
+	markdown := payload["markdown"].(map[string]string)
+
+	if payload["msgtype"] != "markdown" {
+		t.Errorf("Expected markdown message, got %v", payload["msgtype"])
+	}
+	if markdown["title"] != "Claude Completed" {
+		t.Errorf("Expected keyword prefix in title, got %q", markdown["title"])
+	}
+	if markdown["text"] != "#### Claude Completed\n\nDone\n\n> Session: session-123" {
+		t.Errorf("Unexpected text: %q", markdown["text"])
+	}
+
+	// Without a secret the URL is used as is
+	if method, url := formatter.Endpoint("https://oapi.dingtalk.com/robot/send?access_token=abc", "req-1"); method != "POST" || url != "https://oapi.dingtalk.com/robot/send?access_token=abc" {
+		t.Errorf("Unexpected endpoint: %s %s", method, url)
+	}
+}
+
+func TestDingTalkSign(t *testing.T) {
+	// Reference value from DingTalk's documented algorithm
+	if got := dingTalkSign("SECabc", 1700000000000); got != "jcUpW0QmtKduN03n4JqQ0PBosVjqnM8gU7fIIvsDmCM=" {
+		t.Errorf("Unexpected signature: %s", got)
+	}
+
+	formatter := &DingTalkFormatter{Config: config.DingTalkConfig{Secret: "SECabc"}}
+	_, endpoint := formatter.Endpoint("https://oapi.dingtalk.com/robot/send?access_token=abc", "req-1")
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		t.Fatalf("Invalid endpoint: %v", err)
+	}
+	query := u.Query()
+	timestamp, err := strconv.ParseInt(query.Get("timestamp"), 10, 64)
+	if err != nil {
+		t.Fatalf("Expected millisecond timestamp, got %q", query.Get("timestamp"))
+	}
+	if query.Get("access_token") != "abc" || query.Get("sign") != dingTalkSign("SECabc", timestamp) {
+		t.Errorf("Expected access token and matching signature, got %s", endpoint)
+	}
+}
+
+func TestWeComFormatter(t *testing.T) {
+	formatter := &WeComFormatter{}
+
+	result, err := formatter.Format(analyzer.StatusQuestion, "Which database?", "session-123", config.StatusInfo{Title: "Question"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content := result.(map[string]interface{})["markdown"].(map[string]string)["content"]
+	want := "<font color=\"warning\">**Question**</font>\nWhich database?\n> Session: <font color=\"comment\">session-123</font>"
+	if content != want {
+		t.Errorf("Expected %q, got %q", want, content)
+	}
+
+	long := strings.Repeat("数据库", 1000)
+	result, _ = formatter.Format(analyzer.StatusTaskComplete, long, "session-123", config.StatusInfo{Title: "Completed"})
+	content = result.(map[string]interface{})["markdown"].(map[string]string)["content"]
+	if len(content) > wecomMaxContent || !utf8.ValidString(content) {
+		t.Errorf("Expected valid content of at most %d bytes, got %d", wecomMaxContent, len(content))
+	}
+	if !strings.HasSuffix(content, "session-123</font>") {
+		t.Errorf("Expected the session line to be kept when truncating")
+	}
+}
+
+func TestCheckErrcode(t *testing.T) {
+	if err := checkErrcode([]byte(`{"errcode":0,"errmsg":"ok"}`), 130101); err != nil {
+		t.Errorf("Expected success, got %v", err)
+	}
+	if err := checkErrcode([]byte(`ok`), 130101); err != nil {
+		t.Errorf("Expected unexpected bodies to be ignored, got %v", err)
+	}
+
+	err := checkErrcode([]byte(`{"errcode":310000,"errmsg":"sign not match"}`), 130101)
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.Code != 310000 || apiErr.RateLimited {
+		t.Errorf("Expected permanent API error, got %v", err)
+	}
+	err = checkErrcode([]byte(`{"errcode":130101,"errmsg":"send too fast"}`), 130101)
+	if apiErr, ok := err.(*APIError); !ok || !apiErr.RateLimited {
+		t.Errorf("Expected rate limited API error, got %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/platform"
+)
+
+const (
+	// outboxLockTimeout is how long a flush waits for another process that is flushing
+	outboxLockTimeout = 100 * time.Millisecond
+
+	// outboxMaxAge is how long an entry is kept; a flush drops older entries undelivered
+	outboxMaxAge = 7 * 24 * time.Hour
+)
+
+// Outbox stores webhook payloads that could not be delivered, one JSON file per
+// notification, so they can be redelivered by a later hook or `outbox flush`.
+type Outbox struct {
+	dir string
+}
+
+// OutboxEntry is an undelivered webhook notification
+type OutboxEntry struct {
+	RequestID   string    `json:"requestId"` // Original X-Request-ID, reused as idempotency key
+	Target      string    `json:"target"`
+	TargetKey   string    `json:"targetKey"` // Name and URL hash of the target; only a target with the same key redelivers
+	Status      string    `json:"status"`
+	ContentType string    `json:"contentType"`
+	Payload     []byte    `json:"payload"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Attempts    int       `json:"attempts"` // Delivery attempts, including the original send
+	LastError   string    `json:"lastError,omitempty"`
+	Deferred    bool      `json:"deferred,omitempty"` // Queued during quiet hours without a delivery attempt
+	// DeferredUntil is when the quiet hours of a deferred entry end; it isn't flushed before
+	DeferredUntil time.Time `json:"deferredUntil,omitempty"`
+
+	path string
+}
+
+// Expired reports whether the entry is older than outboxMaxAge at now
+func (e *OutboxEntry) Expired(now time.Time) bool {
+	return now.Sub(e.CreatedAt) > outboxMaxAge
+}
+
+// DefaultOutboxDir returns the outbox directory in the user cache dir
+// (e.g. ~/.cache/claude-notifications/outbox), falling back to the temp dir
+func DefaultOutboxDir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = platform.TempDir()
+	}
+	return filepath.Join(base, "claude-notifications", "outbox")
+}
+
+// NewOutbox creates an outbox backed by dir (created on first write)
+func NewOutbox(dir string) *Outbox {
+	return &Outbox{dir: dir}
+}
+
+// Dir returns the outbox directory
+func (o *Outbox) Dir() string {
+	return o.dir
+}
+
+// Add stores an entry; the file is written atomically so readers never see partial entries
+func (o *Outbox) Add(entry *OutboxEntry) error {
+	if err := os.MkdirAll(o.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create outbox: %w", err)
+	}
+	entry.path = filepath.Join(o.dir, fmt.Sprintf("%d-%s.json", entry.CreatedAt.UnixNano(), entry.RequestID))
+	return o.write(entry)
+}
+
+// Update rewrites a stored entry (e.g. after a failed redelivery)
+func (o *Outbox) Update(entry *OutboxEntry) error {
+	if entry.path == "" {
+		return fmt.Errorf("outbox entry %s has not been stored", entry.RequestID)
+	}
+	return o.write(entry)
+}
+
+// Remove deletes a stored entry
+func (o *Outbox) Remove(entry *OutboxEntry) error {
+	if err := os.Remove(entry.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove outbox entry: %w", err)
+	}
+	return nil
+}
+
+// List returns the stored entries, oldest first. Unreadable files are skipped.
+func (o *Outbox) List() ([]*OutboxEntry, error) {
+	files, err := os.ReadDir(o.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read outbox: %w", err)
+	}
+
+	var entries []*OutboxEntry
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		path := filepath.Join(o.dir, file.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var entry OutboxEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			continue
+		}
+		entry.path = path
+		entries = append(entries, &entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
+
+// Purge deletes all stored entries and returns how many were removed
+func (o *Outbox) Purge() (int, error) {
+	entries, err := o.List()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if err := o.Remove(entry); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// lock takes the outbox flush lock so concurrent hooks don't deliver the same entry twice
+func (o *Outbox) lock() (*platform.FileLock, error) {
+	if err := os.MkdirAll(o.dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create outbox: %w", err)
+	}
+	return platform.LockFile(filepath.Join(o.dir, ".flush.lock"), outboxLockTimeout)
+}
+
+// write stores an entry via a temp file and rename
+func (o *Outbox) write(entry *OutboxEntry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox entry: %w", err)
+	}
+
+	tmp := entry.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write outbox entry: %w", err)
+	}
+	if err := os.Rename(tmp, entry.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write outbox entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,438 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+func newTestSenderWithOutbox(t *testing.T, url string) *Sender {
+	t.Helper()
+	cfg := newTestConfig(url)
+	cfg.Notifications.Webhook.Retry.MaxAttempts = 1
+	sender := New(cfg)
+	sender.outbox = NewOutbox(t.TempDir())
+	return sender
+}
+
+func TestOutboxAddListRemove(t *testing.T) {
+	outbox := NewOutbox(filepath.Join(t.TempDir(), "outbox"))
+
+	entries, err := outbox.List()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Expected empty outbox before first write, got %v, %v", entries, err)
+	}
+
+	now := time.Now()
+	second := &OutboxEntry{RequestID: "req-2", Target: "slack", Payload: []byte(`{"text":"b"}`), CreatedAt: now}
+	first := &OutboxEntry{RequestID: "req-1", Target: "slack", Payload: []byte(`{"text":"a"}`), CreatedAt: now.Add(-time.Minute)}
+	if err := outbox.Add(second); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := outbox.Add(first); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	entries, err = outbox.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].RequestID != "req-1" || entries[1].RequestID != "req-2" {
+		t.Fatalf("Expected entries oldest first, got %+v", entries)
+	}
+	if string(entries[0].Payload) != `{"text":"a"}` {
+		t.Errorf("Expected payload to round-trip, got %s", entries[0].Payload)
+	}
+
+	info, err := os.Stat(entries[0].path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if os.PathSeparator == '/' && info.Mode().Perm() != 0600 {
+		t.Errorf("Expected outbox entries to be private (0600), got %v", info.Mode().Perm())
+	}
+
+	if err := outbox.Remove(entries[0]); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	entries, _ = outbox.List()
+	if len(entries) != 1 || entries[0].RequestID != "req-2" {
+		t.Errorf("Expected only req-2 to remain, got %+v", entries)
+	}
+
+	removed, err := outbox.Purge()
+	if err != nil || removed != 1 {
+		t.Errorf("Expected purge to remove 1 entry, got %d, %v", removed, err)
+	}
+	entries, _ = outbox.List()
+	if len(entries) != 0 {
+		t.Errorf("Expected empty outbox after purge, got %d entries", len(entries))
+	}
+}
+
+func TestOutboxListSkipsCorruptEntries(t *testing.T) {
+	dir := t.TempDir()
+	outbox := NewOutbox(dir)
+
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.Add(&OutboxEntry{RequestID: "req-1", CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := outbox.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected corrupt entry to be skipped, got %d entries", len(entries))
+	}
+}
+
+func TestSenderQueuesFailedWebhook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sender := newTestSenderWithOutbox(t, server.URL)
+
+	results, err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123", "")
+	if err == nil {
+		t.Fatal("Expected error for 503 response")
+	}
+	if len(results) != 1 || !results[0].Queued {
+		t.Fatalf("Expected the failed webhook to be queued, got %+v", results)
+	}
+
+	entries, _ := sender.Outbox().List()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 outbox entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.RequestID != results[0].RequestID {
+		t.Errorf("Expected outbox entry to keep request ID %s, got %s", results[0].RequestID, entry.RequestID)
+	}
+	if entry.Target != config.DefaultWebhookName || entry.Status != "task_complete" || entry.Attempts != 1 {
+		t.Errorf("Unexpected outbox entry: %+v", entry)
+	}
+	if entry.LastError == "" || len(entry.Payload) == 0 {
+		t.Errorf("Expected payload and last error to be stored, got %+v", entry)
+	}
+}
+
+func TestSenderQueuesWhenCircuitOpen(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sender := newTestSenderWithOutbox(t, server.URL)
+
+	// Threshold is 3 in the test config; the 4th send finds the circuit open
+	for i := 0; i < 4; i++ {
+		_, _ = sender.Send(analyzer.StatusTaskComplete, "Done", "session-123", "")
+	}
+
+	entries, _ := sender.Outbox().List()
+	if len(entries) != 4 {
+		t.Errorf("Expected failures and circuit-open skips to be queued, got %d entries", len(entries))
+	}
+}
+
+func TestSenderDoesNotQueuePermanentFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	sender := newTestSenderWithOutbox(t, server.URL)
+
+	results, err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123", "")
+	if err == nil {
+		t.Fatal("Expected error for 400 response")
+	}
+	if results[0].Queued {
+		t.Error("Expected 4xx failure not to be queued")
+	}
+	if entries, _ := sender.Outbox().List(); len(entries) != 0 {
+		t.Errorf("Expected empty outbox, got %d entries", len(entries))
+	}
+}
+
+func TestSenderSendTestDoesNotQueue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	sender := newTestSenderWithOutbox(t, server.URL)
+
+	results, err := sender.SendTest(analyzer.StatusTaskComplete, "Done", "session-123", "")
+	if err == nil {
+		t.Fatal("Expected error for 502 response")
+	}
+	if results[0].Queued {
+		t.Error("Expected a test send not to be queued")
+	}
+	if entries, _ := sender.Outbox().List(); len(entries) != 0 {
+		t.Errorf("Expected empty outbox after a failed test send, got %d entries", len(entries))
+	}
+}
+
+func TestSenderDoesNotQueueRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.RateLimit.Enabled = true
+	cfg.Notifications.Webhook.RateLimit.RequestsPerMinute = 1
+	sender := New(cfg)
+	sender.outbox = NewOutbox(t.TempDir())
+
+	_, _ = sender.Send(analyzer.StatusTaskComplete, "Done", "session-123", "")
+	_, err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123", "")
+	if err == nil {
+		t.Fatal("Expected rate limit error")
+	}
+	if entries, _ := sender.Outbox().List(); len(entries) != 0 {
+		t.Errorf("Expected rate-limited webhook not to be queued, got %d entries", len(entries))
+	}
+}
+
+func TestSenderFlushRedeliversWithOriginalRequestID(t *testing.T) {
+	var healthy atomic.Bool
+	var receivedID atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		receivedID.Store(r.Header.Get("X-Request-ID"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := newTestSenderWithOutbox(t, server.URL)
+
+	results, _ := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123", "")
+	originalID := results[0].RequestID
+
+	// Still failing: the entry stays with an updated attempt count
+	res, err := sender.Flush()
+	if err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if res.Failed != 1 || res.Remaining != 1 {
+		t.Errorf("Expected 1 failed and 1 remaining, got %+v", res)
+	}
+	entries, _ := sender.Outbox().List()
+	if len(entries) != 1 || entries[0].Attempts != 2 {
+		t.Fatalf("Expected entry with 2 attempts, got %+v", entries)
+	}
+
+	healthy.Store(true)
+	res, err = sender.Flush()
+	if err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if res.Delivered != 1 || res.Remaining != 0 {
+		t.Errorf("Expected 1 delivered and none remaining, got %+v", res)
+	}
+	if receivedID.Load() != originalID {
+		t.Errorf("Expected redelivery with original request ID %s, got %v", originalID, receivedID.Load())
+	}
+	if entries, _ := sender.Outbox().List(); len(entries) != 0 {
+		t.Errorf("Expected outbox to be empty after delivery, got %d entries", len(entries))
+	}
+}
+
+func TestSenderDeferQueuesWithoutSending(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := newTestSenderWithOutbox(t, server.URL)
+
+	queued, err := sender.Defer(analyzer.StatusTaskComplete, "Done", "session-123", "", time.Now().Add(-time.Second))
+	if err != nil || queued != 1 {
+		t.Fatalf("Expected 1 deferred entry, got %d (%v)", queued, err)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("Expected no request while deferred, got %d", requests.Load())
+	}
+	entries, _ := sender.Outbox().List()
+	if len(entries) != 1 || !entries[0].Deferred || entries[0].Attempts != 0 || entries[0].RequestID == "" {
+		t.Fatalf("Expected one deferred entry without attempts, got %+v", entries)
+	}
+
+	res, err := sender.Flush()
+	if err != nil || res.Delivered != 1 {
+		t.Errorf("Expected the deferred entry to be delivered by a flush, got %+v (%v)", res, err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 request after the flush, got %d", requests.Load())
+	}
+}
+
+func TestSenderFlushHoldsDeferredEntriesUntilQuietHoursEnd(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := newTestSenderWithOutbox(t, server.URL)
+
+	if _, err := sender.Defer(analyzer.StatusTaskComplete, "Done", "session-123", "", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Defer failed: %v", err)
+	}
+
+	// e.g. the flush after a break-through notification
+	res, err := sender.Flush()
+	if err != nil || res.Delivered != 0 || res.Remaining != 1 {
+		t.Errorf("Expected the deferred entry to stay queued, got %+v (%v)", res, err)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("Expected no request during quiet hours, got %d", requests.Load())
+	}
+
+	entries, _ := sender.Outbox().List()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	entries[0].DeferredUntil = time.Now().Add(-time.Minute)
+	if err := sender.Outbox().Update(entries[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err = sender.Flush()
+	if err != nil || res.Delivered != 1 {
+		t.Errorf("Expected the entry to be delivered after quiet hours, got %+v (%v)", res, err)
+	}
+}
+
+func TestSenderFlushDropsRejectedEntries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	sender := newTestSenderWithOutbox(t, server.URL)
+	_ = sender.Outbox().Add(&OutboxEntry{RequestID: "req-1", Target: config.DefaultWebhookName, TargetKey: stateKey(config.DefaultWebhookName, server.URL),
+		Payload: []byte("{}"), ContentType: "application/json", CreatedAt: time.Now()})
+
+	res, err := sender.Flush()
+	if err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if res.Dropped != 1 || res.Remaining != 0 {
+		t.Errorf("Expected the rejected entry to be dropped, got %+v", res)
+	}
+}
+
+func TestSenderFlushKeepsEntriesForUnknownTargets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := newTestSenderWithOutbox(t, server.URL)
+	_ = sender.Outbox().Add(&OutboxEntry{RequestID: "req-1", Target: "removed", TargetKey: stateKey("removed", server.URL), Payload: []byte("{}"), CreatedAt: time.Now()})
+	// Same name as the configured target, but queued for another URL (e.g. by another project)
+	_ = sender.Outbox().Add(&OutboxEntry{RequestID: "req-2", Target: config.DefaultWebhookName,
+		TargetKey: stateKey(config.DefaultWebhookName, "https://other.example.com/hook"), Payload: []byte("{}"), CreatedAt: time.Now()})
+	// Queued without a target key
+	_ = sender.Outbox().Add(&OutboxEntry{RequestID: "req-3", Target: config.DefaultWebhookName, Payload: []byte("{}"), CreatedAt: time.Now()})
+
+	res, err := sender.Flush()
+	if err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if res.Delivered != 0 || res.Remaining != 3 {
+		t.Errorf("Expected entries for other targets to stay queued, got %+v", res)
+	}
+}
+
+func TestSenderFlushExpiresOldEntries(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := newTestSenderWithOutbox(t, server.URL)
+	key := stateKey(config.DefaultWebhookName, server.URL)
+	_ = sender.Outbox().Add(&OutboxEntry{RequestID: "old", Target: config.DefaultWebhookName, TargetKey: key,
+		Payload: []byte("{}"), CreatedAt: time.Now().Add(-outboxMaxAge - time.Hour)})
+	_ = sender.Outbox().Add(&OutboxEntry{RequestID: "orphan", Target: "removed", TargetKey: stateKey("removed", server.URL),
+		Payload: []byte("{}"), CreatedAt: time.Now().Add(-outboxMaxAge - time.Hour)})
+
+	res, err := sender.Flush()
+	if err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if res.Expired != 2 || res.Delivered != 0 || res.Remaining != 0 {
+		t.Errorf("Expected both old entries to expire, got %+v", res)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("Expected expired entries not to be sent, got %d requests", requests.Load())
+	}
+	if entries, _ := sender.Outbox().List(); len(entries) != 0 {
+		t.Errorf("Expected an empty outbox, got %d entries", len(entries))
+	}
+}
+
+func TestSenderFlushBusy(t *testing.T) {
+	sender := newTestSenderWithOutbox(t, "http://127.0.0.1")
+
+	lock, err := sender.Outbox().lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Unlock()
+
+	if _, err := sender.Flush(); err == nil {
+		t.Error("Expected flush to fail while another process holds the outbox lock")
+	}
+}
+
+func TestSenderShutdownQueuesCancelledRequests(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	sender := newTestSenderWithOutbox(t, server.URL)
+	sender.SendAsync(analyzer.StatusQuestion, "Need input", "session-123", "")
+	time.Sleep(50 * time.Millisecond)
+
+	if err := sender.Shutdown(100 * time.Millisecond); err == nil {
+		t.Error("Expected shutdown timeout")
+	}
+
+	entries, _ := sender.Outbox().List()
+	if len(entries) != 1 || entries[0].Status != "question" {
+		t.Errorf("Expected the cancelled request to be saved to the outbox, got %+v", entries)
+	}
+}
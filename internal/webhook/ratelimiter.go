@@ -5,6 +5,8 @@ import (
 	"errors"
 	"sync"
 	"time"
+
+	"github.com/777genius/claude-notifications/internal/logging"
 )
 
 var (
@@ -18,6 +20,10 @@ type RateLimiter struct {
 	tokens     float64 // current tokens
 	lastRefill time.Time
 	mu         sync.Mutex
+
+	// Optional shared state (see UseStore)
+	store *StateStore
+	key   string
 }
 
 // NewRateLimiter creates a new rate limiter
@@ -34,31 +40,89 @@ func NewRateLimiter(requestsPerMinute int) *RateLimiter {
 	}
 }
 
+// UseStore shares the token bucket with other processes through store, under key
+func (rl *RateLimiter) UseStore(store *StateStore, key string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.store = store
+	rl.key = key
+}
+
 // Allow checks if a request is allowed under the rate limit
 // Returns true if allowed, false if rate limit exceeded
 func (rl *RateLimiter) Allow() bool {
+	allowed := false
+	rl.sync(func() {
+		// Refill tokens based on time elapsed
+		now := time.Now()
+		elapsed := now.Sub(rl.lastRefill).Seconds()
+		rl.tokens += elapsed * rl.rate
+
+		// Cap at capacity
+		if rl.tokens > float64(rl.capacity) {
+			rl.tokens = float64(rl.capacity)
+		}
+
+		rl.lastRefill = now
+
+		// Try to consume a token
+		if rl.tokens >= 1.0 {
+			rl.tokens -= 1.0
+			allowed = true
+		}
+	})
+	return allowed
+}
+
+// sync runs fn under the mutex. With a store, fn starts from the shared bucket
+// and its changes are written back while the file lock is held.
+func (rl *RateLimiter) sync(fn func()) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	// Refill tokens based on time elapsed
-	now := time.Now()
-	elapsed := now.Sub(rl.lastRefill).Seconds()
-	rl.tokens += elapsed * rl.rate
+	if rl.store == nil {
+		fn()
+		return
+	}
 
-	// Cap at capacity
-	if rl.tokens > float64(rl.capacity) {
-		rl.tokens = float64(rl.capacity)
+	ran := false
+	err := rl.store.update(rl.key, func(st *storedState) {
+		rl.restore(st.Limiter)
+		fn()
+		ran = true
+		st.Limiter = &limiterState{Tokens: rl.tokens, LastRefill: rl.lastRefill}
+	})
+	if err != nil {
+		// Fall back to this process's bucket rather than blocking notifications
+		logging.Warn("Rate limiter state not shared: %v", err)
+		if !ran {
+			fn()
+		}
 	}
+}
 
-	rl.lastRefill = now
+// refresh loads the shared bucket, if any (caller holds the mutex)
+func (rl *RateLimiter) refresh() {
+	if rl.store == nil {
+		return
+	}
+	if st := rl.store.get(rl.key); st != nil {
+		rl.restore(st.Limiter)
+	}
+}
 
-	// Try to consume a token
-	if rl.tokens >= 1.0 {
-		rl.tokens -= 1.0
-		return true
+// restore replaces the in-memory bucket with a stored one (caller holds the mutex)
+func (rl *RateLimiter) restore(st *limiterState) {
+	if st == nil {
+		return
 	}
+	rl.tokens = st.Tokens
+	rl.lastRefill = st.LastRefill
 
-	return false
+	// The limit may have been lowered since the state was saved
+	if rl.tokens > float64(rl.capacity) {
+		rl.tokens = float64(rl.capacity)
+	}
 }
 
 // Wait blocks until a request is allowed (with context support)
@@ -85,6 +149,7 @@ func (rl *RateLimiter) Wait(ctx context.Context) error {
 func (rl *RateLimiter) timeUntilNextToken() time.Duration {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
+	rl.refresh()
 
 	// If we have tokens, no need to wait
 	if rl.tokens >= 1.0 {
@@ -102,5 +167,6 @@ func (rl *RateLimiter) timeUntilNextToken() time.Duration {
 func (rl *RateLimiter) GetStats() (tokens float64, capacity int, rate float64) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
+	rl.refresh()
 	return rl.tokens, rl.capacity, rl.rate
 }
@@ -2,6 +2,7 @@ package webhook
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -134,6 +135,17 @@ func (r *Retryer) isRetryable(err error) bool {
 		}
 	}
 
+	// Callers mark errors that retrying cannot fix (e.g. SMTP 5xx replies)
+	var permErr *PermanentError
+	if errors.As(err, &permErr) {
+		return false
+	}
+
+	// Errors reported in a 2xx response body are permanent unless the API is throttling
+	if apiErr, ok := err.(*APIError); ok {
+		return apiErr.RateLimited
+	}
+
 	// Network errors, timeouts are retryable
 	// (context.Canceled is handled separately above)
 	return true
@@ -166,3 +178,27 @@ func NewHTTPError(resp *http.Response, body string) *HTTPError {
 		Body:       body,
 	}
 }
+
+// APIError represents an error that an API reported in the body of a 2xx response
+type APIError struct {
+	Code        int
+	Message     string
+	RateLimited bool // The API asked to slow down; retryable
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.Code, e.Message)
+}
+
+// PermanentError wraps an error that retrying cannot fix
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
@@ -3,6 +3,7 @@ package webhook
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -203,6 +204,9 @@ func TestIsRetryable(t *testing.T) {
 		{"400 error", &HTTPError{StatusCode: 400, Body: "Bad Request"}, false},
 		{"401 error", &HTTPError{StatusCode: 401, Body: "Unauthorized"}, false},
 		{"404 error", &HTTPError{StatusCode: 404, Body: "Not Found"}, false},
+		{"API error", &APIError{Code: 310000, Message: "sign not match"}, false},
+		{"API rate limit", &APIError{Code: 130101, Message: "send too fast", RateLimited: true}, true},
+		{"Permanent error", fmt.Errorf("send: %w", &PermanentError{Err: errors.New("550 mailbox unavailable")}), false},
 		{"Network error", errors.New("connection refused"), true},
 		{"Context timeout", context.DeadlineExceeded, true},
 	}
@@ -0,0 +1,174 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+// DefaultSignatureTolerance is the maximum age of a signature accepted by receivers
+const DefaultSignatureTolerance = 5 * time.Minute
+
+var (
+	// ErrSignatureMissing is returned when the signature header is absent or malformed
+	ErrSignatureMissing = errors.New("signature missing or malformed")
+
+	// ErrSignatureMismatch is returned when no signature in the header matches the body
+	ErrSignatureMismatch = errors.New("signature mismatch")
+
+	// ErrSignatureExpired is returned when the signature timestamp is outside the tolerance
+	ErrSignatureExpired = errors.New("signature timestamp outside tolerance")
+
+	// ErrSigningUnavailable is returned by sends to a target whose signing secret could not
+	// be loaded. It is a configuration error, so the notification is not kept for redelivery.
+	ErrSigningUnavailable = errors.New("cannot sign requests")
+)
+
+// Sign returns the signature header value for a request body:
+//
+//	t=<unix seconds>,<algorithm>=<hex HMAC of "<unix seconds>.<body>">
+//
+// Including the timestamp in the signed content lets receivers reject replayed requests.
+func Sign(secret []byte, algorithm string, timestamp time.Time, body []byte) (string, error) {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	mac, err := computeSignature(secret, algorithm, ts, body)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("t=%s,%s=%s", ts, algorithm, hex.EncodeToString(mac)), nil
+}
+
+// Verify checks a signature header value produced by Sign against the request body.
+// The header may carry several signatures (e.g. during secret rotation); one match is enough.
+// A tolerance of 0 disables the timestamp check.
+func Verify(secret []byte, header string, body []byte, tolerance time.Duration) error {
+	var ts string
+	var signatures [][2]string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ErrSignatureMissing
+		}
+		if key == "t" {
+			ts = value
+		} else {
+			signatures = append(signatures, [2]string{key, value})
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrSignatureMissing
+	}
+
+	if tolerance > 0 {
+		age := time.Since(time.Unix(unix, 0))
+		if age > tolerance || age < -tolerance {
+			return ErrSignatureExpired
+		}
+	}
+
+	for _, sig := range signatures {
+		expected, err := computeSignature(secret, sig[0], ts, body)
+		if err != nil {
+			continue // Unknown algorithm
+		}
+		got, err := hex.DecodeString(sig[1])
+		if err == nil && hmac.Equal(got, expected) {
+			return nil
+		}
+	}
+	return ErrSignatureMismatch
+}
+
+// VerifyRequest verifies the signature header of an incoming request and leaves
+// the body readable for the handler
+func VerifyRequest(r *http.Request, secret []byte, header string, tolerance time.Duration) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	value := r.Header.Get(header)
+	if value == "" {
+		return ErrSignatureMissing
+	}
+	return Verify(secret, value, body, tolerance)
+}
+
+// computeSignature returns the HMAC of "<timestamp>.<body>"
+func computeSignature(secret []byte, algorithm, timestamp string, body []byte) ([]byte, error) {
+	var newHash func() hash.Hash
+	switch algorithm {
+	case "sha256":
+		newHash = sha256.New
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return nil, fmt.Errorf("unsupported signature algorithm: %s", algorithm)
+	}
+
+	mac := hmac.New(newHash, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil), nil
+}
+
+// signer adds signature headers to the requests of one webhook target
+type signer struct {
+	secret    []byte
+	algorithm string
+	header    string
+}
+
+// newSigner loads the signing secret of a target from its environment variable or file
+func newSigner(cfg config.SigningConfig) (*signer, error) {
+	var secret string
+	if cfg.SecretEnv != "" {
+		secret = os.Getenv(cfg.SecretEnv)
+		if secret == "" {
+			return nil, fmt.Errorf("signing secret environment variable %s is not set", cfg.SecretEnv)
+		}
+	} else {
+		data, err := os.ReadFile(expandHome(cfg.SecretFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing secret: %w", err)
+		}
+		secret = strings.TrimSpace(string(data))
+		if secret == "" {
+			return nil, fmt.Errorf("signing secret file %s is empty", cfg.SecretFile)
+		}
+	}
+
+	return &signer{
+		secret:    []byte(secret),
+		algorithm: cfg.Algorithm,
+		header:    cfg.Header,
+	}, nil
+}
+
+// sign sets the signature header for body; each attempt gets a fresh timestamp
+func (s *signer) sign(req *http.Request, body []byte) error {
+	value, err := Sign(s.secret, s.algorithm, time.Now(), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(s.header, value)
+	return nil
+}
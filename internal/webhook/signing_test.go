@@ -0,0 +1,179 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret := []byte("s3cret")
+	body := []byte(`{"status":"task_complete"}`)
+
+	for _, algorithm := range []string{"sha256", "sha512"} {
+		t.Run(algorithm, func(t *testing.T) {
+			header, err := Sign(secret, algorithm, time.Now(), body)
+			if err != nil {
+				t.Fatalf("Sign failed: %v", err)
+			}
+			if !strings.HasPrefix(header, "t=") || !strings.Contains(header, ","+algorithm+"=") {
+				t.Errorf("Unexpected header format: %s", header)
+			}
+
+			if err := Verify(secret, header, body, DefaultSignatureTolerance); err != nil {
+				t.Errorf("Expected valid signature, got %v", err)
+			}
+			if err := Verify([]byte("other"), header, body, DefaultSignatureTolerance); !errors.Is(err, ErrSignatureMismatch) {
+				t.Errorf("Expected mismatch for wrong secret, got %v", err)
+			}
+			if err := Verify(secret, header, []byte(`{"status":"question"}`), DefaultSignatureTolerance); !errors.Is(err, ErrSignatureMismatch) {
+				t.Errorf("Expected mismatch for modified body, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSignKnownValue(t *testing.T) {
+	// HMAC-SHA256("secret", "1700000000.hello"), so receivers in other languages can check their implementation
+	header, err := Sign([]byte("secret"), "sha256", time.Unix(1700000000, 0), []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "t=1700000000,sha256=47b1df0ab12338b2685470b0d2b37033add7c3b2bc8172f313e77413f1bb78c8"
+	if header != want {
+		t.Errorf("Expected %s, got %s", want, header)
+	}
+}
+
+func TestVerifyTimestamp(t *testing.T) {
+	secret := []byte("s3cret")
+	body := []byte("{}")
+
+	old, _ := Sign(secret, "sha256", time.Now().Add(-10*time.Minute), body)
+	if err := Verify(secret, old, body, DefaultSignatureTolerance); !errors.Is(err, ErrSignatureExpired) {
+		t.Errorf("Expected expired signature, got %v", err)
+	}
+	if err := Verify(secret, old, body, 0); err != nil {
+		t.Errorf("Expected tolerance 0 to skip the timestamp check, got %v", err)
+	}
+
+	// Changing the timestamp invalidates the signature
+	tampered := strings.Replace(old, "t=", "t=1", 1)
+	if err := Verify(secret, tampered, body, 0); !errors.Is(err, ErrSignatureMismatch) {
+		t.Errorf("Expected mismatch for tampered timestamp, got %v", err)
+	}
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	for _, header := range []string{"", "garbage", "t=abc,sha256=00", "t=1700000000", "sha256=00"} {
+		if err := Verify([]byte("s"), header, nil, 0); !errors.Is(err, ErrSignatureMissing) {
+			t.Errorf("Verify(%q): expected ErrSignatureMissing, got %v", header, err)
+		}
+	}
+}
+
+func TestVerifyMultipleSignatures(t *testing.T) {
+	body := []byte("{}")
+	now := time.Now()
+	oldSig, _ := Sign([]byte("old"), "sha256", now, body)
+	newSig, _ := Sign([]byte("new"), "sha256", now, body)
+
+	// Receivers rotating secrets may see both
+	header := newSig + "," + strings.SplitN(oldSig, ",", 2)[1]
+	if err := Verify([]byte("old"), header, body, DefaultSignatureTolerance); err != nil {
+		t.Errorf("Expected any matching signature to be accepted, got %v", err)
+	}
+}
+
+func TestSenderSignsRequests(t *testing.T) {
+	t.Setenv("TEST_WEBHOOK_SECRET", "s3cret")
+
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifyErr = VerifyRequest(r, []byte("s3cret"), "X-Signature", DefaultSignatureTolerance)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Headers = map[string]string{"X-Signature": "forged"}
+	cfg.Notifications.Webhook.Signing = config.SigningConfig{
+		Enabled:   true,
+		SecretEnv: "TEST_WEBHOOK_SECRET",
+		Algorithm: "sha512",
+		Header:    "X-Signature",
+	}
+	sender := New(cfg)
+
+	if _, err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123", ""); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if verifyErr != nil {
+		t.Errorf("Expected the receiver to verify the signature, got %v", verifyErr)
+	}
+}
+
+func TestSenderSecretFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := newSigner(config.SigningConfig{SecretFile: path, Algorithm: "sha256", Header: config.DefaultSignatureHeader})
+	if err != nil {
+		t.Fatalf("newSigner failed: %v", err)
+	}
+	if string(s.secret) != "from-file" {
+		t.Errorf("Expected trimmed secret from file, got %q", s.secret)
+	}
+}
+
+func TestSenderMissingSecretSendsNothing(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Signing = config.SigningConfig{
+		Enabled:   true,
+		SecretEnv: "TEST_WEBHOOK_SECRET_UNSET",
+		Algorithm: "sha256",
+		Header:    config.DefaultSignatureHeader,
+	}
+	sender := newTestSenderWithOutbox(t, server.URL)
+	sender.targets = []*target{newTarget(cfg.Notifications.Webhook, nil)}
+
+	results, err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123", "")
+	if err == nil || !strings.Contains(err.Error(), "TEST_WEBHOOK_SECRET_UNSET") {
+		t.Errorf("Expected missing secret error, got %v", err)
+	}
+	if !errors.Is(err, ErrSigningUnavailable) {
+		t.Errorf("Expected ErrSigningUnavailable, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("Expected no unsigned requests, got %d", requests)
+	}
+
+	// A missing secret is a configuration error; nothing is kept for redelivery
+	if len(results) != 1 || results[0].Queued {
+		t.Errorf("Expected one unqueued result, got %+v", results)
+	}
+	if _, err := sender.Defer(analyzer.StatusTaskComplete, "Done", "session-123", "", time.Now()); !errors.Is(err, ErrSigningUnavailable) {
+		t.Errorf("Expected Defer to fail with ErrSigningUnavailable, got %v", err)
+	}
+	entries, _ := sender.outbox.List()
+	if len(entries) != 0 {
+		t.Errorf("Expected empty outbox, got %d entries", len(entries))
+	}
+}
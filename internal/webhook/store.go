@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/777genius/claude-notifications/internal/platform"
+)
+
+const (
+	// stateFileName is the shared state file in the temp dir
+	stateFileName = "claude-notifications-webhook-state.json"
+
+	// stateMaxAge is how long an untouched target entry is kept
+	stateMaxAge = 24 * time.Hour
+
+	// stateLockTimeout bounds how long a hook waits for another process to release the state
+	stateLockTimeout = 2 * time.Second
+)
+
+// StateStore keeps circuit breaker and rate limiter state in a JSON file shared by
+// every hook process on the machine. Each hook runs as a fresh process, so without it
+// the limits would reset on every invocation.
+//
+// Updates are read-modify-write cycles under an exclusive file lock; the file itself is
+// replaced atomically, so reads need no lock.
+type StateStore struct {
+	path        string
+	lockTimeout time.Duration
+}
+
+// storedState is the persisted state of one webhook target
+type storedState struct {
+	Breaker   *breakerState `json:"breaker,omitempty"`
+	Limiter   *limiterState `json:"limiter,omitempty"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+type breakerState struct {
+	State           CircuitBreakerState `json:"state"`
+	Failures        int                 `json:"failures"`
+	Successes       int                 `json:"successes"`
+	LastStateChange time.Time           `json:"lastStateChange"`
+}
+
+type limiterState struct {
+	Tokens     float64   `json:"tokens"`
+	LastRefill time.Time `json:"lastRefill"`
+}
+
+// DefaultStatePath returns the path of the shared state file
+func DefaultStatePath() string {
+	return filepath.Join(platform.TempDir(), stateFileName)
+}
+
+// NewStateStore creates a store backed by the file at path
+func NewStateStore(path string) *StateStore {
+	return &StateStore{
+		path:        path,
+		lockTimeout: stateLockTimeout,
+	}
+}
+
+// stateKey identifies a target in the store; a changed URL starts with fresh state
+func stateKey(name, url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return name + "-" + hex.EncodeToString(sum[:4])
+}
+
+// update runs fn on the state of key while holding the lock, then writes it back
+func (s *StateStore) update(key string, fn func(*storedState)) error {
+	lock, err := platform.LockFile(s.path+".lock", s.lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	states := s.read()
+	state := states[key]
+	if state == nil {
+		state = &storedState{}
+		states[key] = state
+	}
+
+	fn(state)
+
+	now := time.Now()
+	state.UpdatedAt = now
+	for k, st := range states {
+		if now.Sub(st.UpdatedAt) > stateMaxAge {
+			delete(states, k)
+		}
+	}
+
+	return s.write(states)
+}
+
+// get returns the state of key, or nil if none is stored
+func (s *StateStore) get(key string) *storedState {
+	return s.read()[key]
+}
+
+// read loads all target states; a missing or corrupt file yields an empty set
+func (s *StateStore) read() map[string]*storedState {
+	states := make(map[string]*storedState)
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logging.Warn("Failed to read webhook state %s: %v", s.path, err)
+		}
+		return states
+	}
+
+	if err := json.Unmarshal(data, &states); err != nil {
+		logging.Warn("Ignoring corrupt webhook state %s: %v", s.path, err)
+		return make(map[string]*storedState)
+	}
+	return states
+}
+
+// write replaces the state file atomically
+func (s *StateStore) write(states map[string]*storedState) error {
+	data, err := json.Marshal(states)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write webhook state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write webhook state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write webhook state: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write webhook state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,327 @@
+package webhook
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// Senders share breaker and limiter state through the temp dir and queue failures
+	// in the user cache dir; keep test runs away from the real files and each other
+	dir, err := os.MkdirTemp("", "webhook-test-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, name := range []string{"TMPDIR", "TMP", "TEMP", "HOME", "XDG_CACHE_HOME", "LOCALAPPDATA"} {
+		os.Setenv(name, dir)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func newTestStore(t *testing.T) *StateStore {
+	t.Helper()
+	return NewStateStore(filepath.Join(t.TempDir(), stateFileName))
+}
+
+func TestStateStoreUpdateAndGet(t *testing.T) {
+	store := newTestStore(t)
+
+	if st := store.get("slack"); st != nil {
+		t.Fatalf("Expected no state before first update, got %+v", st)
+	}
+
+	err := store.update("slack", func(st *storedState) {
+		st.Limiter = &limiterState{Tokens: 3, LastRefill: time.Now()}
+	})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	st := store.get("slack")
+	if st == nil || st.Limiter == nil || st.Limiter.Tokens != 3 {
+		t.Fatalf("Expected stored limiter with 3 tokens, got %+v", st)
+	}
+	if st.UpdatedAt.IsZero() {
+		t.Error("Expected UpdatedAt to be set")
+	}
+	if store.get("discord") != nil {
+		t.Error("Expected other keys to be unaffected")
+	}
+}
+
+func TestStateStorePrunesStaleEntries(t *testing.T) {
+	store := newTestStore(t)
+
+	stale := fmt.Sprintf(`{"old": {"updatedAt": %q}}`, time.Now().Add(-2*stateMaxAge).Format(time.RFC3339))
+	if err := os.WriteFile(store.path, []byte(stale), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.update("new", func(st *storedState) {}); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	if store.get("old") != nil {
+		t.Error("Expected stale entry to be pruned")
+	}
+	if store.get("new") == nil {
+		t.Error("Expected new entry to be kept")
+	}
+}
+
+func TestStateStoreCorruptFile(t *testing.T) {
+	store := newTestStore(t)
+	if err := os.WriteFile(store.path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if store.get("slack") != nil {
+		t.Error("Expected corrupt state to read as empty")
+	}
+	if err := store.update("slack", func(st *storedState) {}); err != nil {
+		t.Fatalf("Expected update to replace corrupt state, got: %v", err)
+	}
+	if store.get("slack") == nil {
+		t.Error("Expected state after update")
+	}
+}
+
+func TestStateKey(t *testing.T) {
+	a := stateKey("slack", "https://hooks.slack.com/a")
+	b := stateKey("slack", "https://hooks.slack.com/b")
+
+	if a == b {
+		t.Error("Expected different URLs to get different keys")
+	}
+	if !strings.HasPrefix(a, "slack-") {
+		t.Errorf("Expected key to start with the target name, got %q", a)
+	}
+	if a != stateKey("slack", "https://hooks.slack.com/a") {
+		t.Error("Expected key to be stable")
+	}
+}
+
+func TestCircuitBreakerSharedState(t *testing.T) {
+	store := newTestStore(t)
+
+	// Two breakers stand in for two hook processes
+	first := NewCircuitBreaker(2, 1, time.Minute)
+	first.UseStore(store, "target")
+	second := NewCircuitBreaker(2, 1, time.Minute)
+	second.UseStore(store, "target")
+
+	failing := func() error { return errors.New("down") }
+	_ = first.Execute(context.Background(), failing)
+	_ = second.Execute(context.Background(), failing)
+
+	if state := first.GetState(); state != StateOpen {
+		t.Errorf("Expected failures from both breakers to open the circuit, got %v", state)
+	}
+
+	called := false
+	err := second.Execute(context.Background(), func() error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, ErrCircuitOpen) || called {
+		t.Errorf("Expected shared open circuit to fail fast, got err=%v called=%v", err, called)
+	}
+}
+
+func TestRateLimiterSharedState(t *testing.T) {
+	store := newTestStore(t)
+
+	first := NewRateLimiter(3)
+	first.UseStore(store, "target")
+	second := NewRateLimiter(3)
+	second.UseStore(store, "target")
+
+	allowed := 0
+	for i := 0; i < 3; i++ {
+		if first.Allow() {
+			allowed++
+		}
+		if second.Allow() {
+			allowed++
+		}
+	}
+
+	if allowed != 3 {
+		t.Errorf("Expected the shared bucket to allow 3 requests, got %d", allowed)
+	}
+	if tokens, _, _ := second.GetStats(); tokens >= 1 {
+		t.Errorf("Expected the shared bucket to be empty, got %.2f tokens", tokens)
+	}
+}
+
+func TestRateLimiterSharedStateLowerLimit(t *testing.T) {
+	store := newTestStore(t)
+
+	generous := NewRateLimiter(60)
+	generous.UseStore(store, "target")
+	generous.Allow()
+
+	// A process with a lower limit must not inherit the bigger bucket
+	strict := NewRateLimiter(2)
+	strict.UseStore(store, "target")
+
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		if strict.Allow() {
+			allowed++
+		}
+	}
+	if allowed != 2 {
+		t.Errorf("Expected 2 requests under the lower limit, got %d", allowed)
+	}
+}
+
+func TestSharedStateFallsBackWhenStoreUnavailable(t *testing.T) {
+	store := NewStateStore(filepath.Join(t.TempDir(), "missing", stateFileName))
+
+	rl := NewRateLimiter(1)
+	rl.UseStore(store, "target")
+
+	if !rl.Allow() {
+		t.Error("Expected first request to be allowed by the in-memory bucket")
+	}
+	if rl.Allow() {
+		t.Error("Expected in-memory bucket to still enforce the limit")
+	}
+}
+
+// helperEnv marks a test binary run as a helper process for the cross-process tests
+const helperEnv = "WEBHOOK_STATE_HELPER"
+
+// TestStateHelperProcess is not a real test: the cross-process tests run the test
+// binary with this test selected to act as one hook process
+func TestStateHelperProcess(t *testing.T) {
+	mode := os.Getenv(helperEnv)
+	if mode == "" {
+		return
+	}
+
+	store := NewStateStore(os.Getenv("WEBHOOK_STATE_PATH"))
+	count := 0
+
+	switch mode {
+	case "ratelimit":
+		rl := NewRateLimiter(10)
+		rl.UseStore(store, "target")
+		for i := 0; i < 5; i++ {
+			if rl.Allow() {
+				count++
+			}
+		}
+	case "breaker":
+		cb := NewCircuitBreaker(5, 2, time.Minute)
+		cb.UseStore(store, "target")
+		for i := 0; i < 3; i++ {
+			_ = cb.Execute(context.Background(), func() error {
+				count++
+				return errors.New("down")
+			})
+		}
+	}
+
+	fmt.Printf("RESULT=%d\n", count)
+	os.Exit(0)
+}
+
+// runHelperProcesses starts n helper processes at once and returns the sum of their results
+func runHelperProcesses(t *testing.T, mode, statePath string, n int) int {
+	t.Helper()
+
+	cmds := make([]*exec.Cmd, n)
+	outputs := make([]*bytes.Buffer, n)
+	for i := range cmds {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestStateHelperProcess$")
+		cmd.Env = append(os.Environ(), helperEnv+"="+mode, "WEBHOOK_STATE_PATH="+statePath)
+		outputs[i] = &bytes.Buffer{}
+		cmd.Stdout = outputs[i]
+		cmd.Stderr = os.Stderr
+		cmds[i] = cmd
+	}
+
+	for _, cmd := range cmds {
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("Failed to start helper process: %v", err)
+		}
+	}
+
+	total := 0
+	for i, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("Helper process failed: %v\n%s", err, outputs[i])
+		}
+
+		found := false
+		scanner := bufio.NewScanner(outputs[i])
+		for scanner.Scan() {
+			if value, ok := strings.CutPrefix(scanner.Text(), "RESULT="); ok {
+				count, err := strconv.Atoi(value)
+				if err != nil {
+					t.Fatalf("Invalid helper result %q", value)
+				}
+				total += count
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("Helper process printed no result:\n%s", outputs[i])
+		}
+	}
+
+	return total
+}
+
+func TestRateLimiterSharedAcrossProcesses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("spawns processes")
+	}
+	statePath := filepath.Join(t.TempDir(), stateFileName)
+
+	// 10 processes try 5 requests each against a shared limit of 10 per minute
+	allowed := runHelperProcesses(t, "ratelimit", statePath, 10)
+
+	// One extra token may refill while the processes run
+	if allowed < 10 || allowed > 11 {
+		t.Errorf("Expected the shared limit of 10 to hold across processes, %d of 50 requests were allowed", allowed)
+	}
+}
+
+func TestCircuitBreakerSharedAcrossProcesses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("spawns processes")
+	}
+	statePath := filepath.Join(t.TempDir(), stateFileName)
+
+	// 10 processes make 3 failing calls each; the breaker opens after 5 failures
+	calls := runHelperProcesses(t, "breaker", statePath, 10)
+
+	// Calls already past the state check when the circuit opens still run,
+	// at most one per other process
+	if calls < 5 || calls > 5+9 {
+		t.Errorf("Expected the shared breaker to stop calls after 5 failures, got %d of 30 calls", calls)
+	}
+
+	cb := NewCircuitBreaker(5, 2, time.Minute)
+	cb.UseStore(NewStateStore(statePath), "target")
+	if state := cb.GetState(); state != StateOpen {
+		t.Errorf("Expected a new process to see the open circuit, got %v", state)
+	}
+}
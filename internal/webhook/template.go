@@ -0,0 +1,120 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/platform"
+	"github.com/777genius/claude-notifications/internal/sessionname"
+)
+
+// TemplateData is the context a custom webhook template is rendered with
+type TemplateData struct {
+	Status      string    // Status key, e.g. "task_complete"
+	Title       string    // Status title from config, e.g. "✅ Completed"
+	Message     string    // Notification summary without the "[folder|branch]" prefix
+	SessionID   string    // Claude session ID
+	SessionName string    // Friendly session name, e.g. "bold-cat"
+	Folder      string    // Project folder name
+	Project     string    // Full project directory
+	GitBranch   string    // Current git branch (empty outside a repository)
+	Hostname    string    // Machine hostname
+	Timestamp   time.Time // Time the notification was built
+}
+
+// templateFuncs are the helpers available in custom webhook templates
+var templateFuncs = template.FuncMap{
+	// json encodes a value as a JSON literal: {"text": {{json .Message}}}
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	// jsonEscape escapes a string for use inside JSON quotes: {"text": "{{jsonEscape .Message}}"}
+	"jsonEscape": func(s string) string {
+		data, _ := json.Marshal(s)
+		return string(data[1 : len(data)-1])
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+// parseTemplate parses the template or templateFile of a webhook target.
+// Returns nil if the target has no template.
+func parseTemplate(cfg config.WebhookConfig) (*template.Template, error) {
+	text := cfg.Template
+	if cfg.TemplateFile != "" {
+		data, err := os.ReadFile(expandHome(cfg.TemplateFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template file: %w", err)
+		}
+		text = string(data)
+	}
+	if text == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New(cfg.Name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// newTemplateData builds the template context for a notification
+func newTemplateData(status analyzer.Status, message, sessionID, cwd string, statusInfo config.StatusInfo) TemplateData {
+	data := TemplateData{
+		Status:      string(status),
+		Title:       statusInfo.Title,
+		Message:     message,
+		SessionID:   sessionID,
+		SessionName: sessionname.GenerateSessionName(sessionID),
+		Project:     cwd,
+		Timestamp:   time.Now(),
+	}
+	data.Hostname, _ = os.Hostname()
+
+	if cwd != "" {
+		data.Folder = filepath.Base(cwd)
+		data.GitBranch = platform.GetGitBranch(cwd)
+	}
+
+	// The hook prefixes messages with "[folder|branch] " for desktop notifications;
+	// templates get the parts separately
+	prefix := "[" + data.Folder + "] "
+	if data.GitBranch != "" {
+		prefix = "[" + data.Folder + "|" + data.GitBranch + "] "
+	}
+	data.Message = strings.TrimPrefix(message, prefix)
+
+	return data
+}
+
+// renderTemplate renders a custom webhook payload; JSON output is checked before sending
+func renderTemplate(tmpl *template.Template, data TemplateData, contentType string) ([]byte, error) {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+
+	payload := []byte(buf.String())
+	if strings.Contains(contentType, "json") && !json.Valid(payload) {
+		return nil, fmt.Errorf("template output is not valid JSON (use the json or jsonEscape helpers for values, or format \"text\"): %s", truncate(buf.String(), 200))
+	}
+	return payload, nil
+}
+
+// truncate shortens s to at most n bytes for error messages
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
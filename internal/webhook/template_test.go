@@ -0,0 +1,187 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+func TestTemplatePayload(t *testing.T) {
+	var body []byte
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		contentType = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Template = `{"event": "{{.Status}}", "title": {{json .Title}}, "text": "{{jsonEscape .Message}}", "folder": {{json .Folder}}, "session": {{json .SessionID}}, "name": {{json .SessionName}}, "host": {{json .Hostname}}, "at": {{.Timestamp.Unix}}}`
+	sender := New(cfg)
+
+	cwd := filepath.Join(t.TempDir(), "acme")
+	if err := os.Mkdir(cwd, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := sender.Send(analyzer.StatusTaskComplete, `[acme] Fixed "quotes" in parser`, "session-123", cwd)
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if contentType != "application/json" {
+		t.Errorf("Expected application/json, got %s", contentType)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("Expected valid JSON, got %s: %v", body, err)
+	}
+	if payload["event"] != "task_complete" || payload["title"] != "Task Complete" {
+		t.Errorf("Unexpected status fields: %v", payload)
+	}
+	if payload["text"] != `Fixed "quotes" in parser` {
+		t.Errorf("Expected message without folder prefix, got %q", payload["text"])
+	}
+	if payload["folder"] != "acme" || payload["session"] != "session-123" {
+		t.Errorf("Unexpected project/session fields: %v", payload)
+	}
+	if name, _ := payload["name"].(string); name == "" {
+		t.Error("Expected a session name")
+	}
+	if host, _ := os.Hostname(); payload["host"] != host {
+		t.Errorf("Expected hostname %q, got %v", host, payload["host"])
+	}
+}
+
+func TestTemplateTextFormat(t *testing.T) {
+	var body, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		contentType = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Format = "text"
+	cfg.Notifications.Webhook.Template = `{{upper .Status}}: {{.Message}}`
+	sender := New(cfg)
+
+	if _, err := sender.Send(analyzer.StatusQuestion, "Which database?", "session-123", ""); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if body != "QUESTION: Which database?" {
+		t.Errorf("Unexpected body %q", body)
+	}
+	if contentType != "text/plain" {
+		t.Errorf("Expected text/plain, got %s", contentType)
+	}
+}
+
+func TestTemplateFile(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "hook.tmpl")
+	if err := os.WriteFile(path, []byte(`{"msg": {{json .Message}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.TemplateFile = path
+	sender := New(cfg)
+
+	if _, err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123", ""); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if body != `{"msg": "Done"}` {
+		t.Errorf("Unexpected body %q", body)
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		template string
+		file     string
+		wantErr  string
+	}{
+		{name: "syntax error", template: `{"text": {{.Message}`, wantErr: "invalid template"},
+		{name: "unknown field", template: `{"text": {{json .Body}}}`, wantErr: "failed to render template"},
+		{name: "invalid JSON", template: `{"text": {{.Message}}}`, wantErr: "not valid JSON"},
+		{name: "missing file", file: filepath.Join(t.TempDir(), "missing.tmpl"), wantErr: "failed to read template file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(server.URL)
+			cfg.Notifications.Webhook.Template = tt.template
+			cfg.Notifications.Webhook.TemplateFile = tt.file
+			sender := newTestSenderWithOutbox(t, server.URL)
+			sender.targets = []*target{newTarget(cfg.Notifications.Webhook, nil)}
+
+			results, err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123", "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if len(results) == 1 && results[0].Queued {
+				t.Error("Expected template errors not to be queued")
+			}
+		})
+	}
+
+	if requests != 0 {
+		t.Errorf("Expected no requests for broken templates, got %d", requests)
+	}
+}
+
+func TestTemplateIgnoredForBuiltinPresets(t *testing.T) {
+	cfg := newTestConfig("http://127.0.0.1")
+	cfg.Notifications.Webhook.Preset = "slack"
+	cfg.Notifications.Webhook.Template = `{{.Broken`
+
+	tgt := newTarget(cfg.Notifications.Webhook, nil)
+	if tgt.template != nil || tgt.templateErr != nil {
+		t.Error("Expected the template to be ignored for the slack preset")
+	}
+}
+
+func TestCustomContentType(t *testing.T) {
+	w := config.WebhookConfig{Format: "json"}
+	if got := customContentType(w); got != "application/json" {
+		t.Errorf("Expected application/json, got %s", got)
+	}
+
+	w.Format = "text"
+	if got := customContentType(w); got != "text/plain" {
+		t.Errorf("Expected text/plain, got %s", got)
+	}
+
+	w.Headers = map[string]string{"content-type": "application/xml"}
+	if got := customContentType(w); got != "application/xml" {
+		t.Errorf("Expected header to override content type, got %s", got)
+	}
+}
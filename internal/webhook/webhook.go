@@ -4,29 +4,36 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/777genius/claude-notifications/internal/analyzer"
 	"github.com/777genius/claude-notifications/internal/config"
 	"github.com/777genius/claude-notifications/internal/errorhandler"
 	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/777genius/claude-notifications/internal/platform"
 	"github.com/google/uuid"
 )
 
-// Sender sends webhook notifications with professional patterns
+// outboxSaveGrace is how long Shutdown waits for cancelled requests to reach the outbox
+const outboxSaveGrace = time.Second
+
+// Sender sends webhook notifications with professional patterns.
+// Each configured target has its own retry, circuit breaker, rate limiter and metrics.
 type Sender struct {
-	cfg            *config.Config
-	client         *http.Client
-	retry          *Retryer
-	circuitBreaker *CircuitBreaker
-	rateLimiter    *RateLimiter
-	metrics        *Metrics
-	formatters     map[string]Formatter
+	cfg     *config.Config
+	client  *http.Client
+	targets []*target
+	outbox  *Outbox
 
 	// Graceful shutdown
 	wg     sync.WaitGroup
@@ -34,6 +41,22 @@ type Sender struct {
 	cancel context.CancelFunc
 }
 
+// target is a single webhook destination with independent resilience state
+type target struct {
+	name           string
+	key            string // stateKey of name and URL; identifies the target's outbox entries
+	cfg            config.WebhookConfig
+	retry          *Retryer
+	circuitBreaker *CircuitBreaker
+	rateLimiter    *RateLimiter
+	metrics        *Metrics
+	formatters     map[string]Formatter
+	template       *template.Template // Custom payload template (nil = built-in payload)
+	templateErr    error              // Set if the template could not be loaded; sends fail with it
+	signer         *signer            // HMAC request signing (nil = unsigned)
+	signingErr     error              // Set if signing is enabled but the secret could not be loaded
+}
+
 // New creates a new professional webhook sender
 func New(cfg *config.Config) *Sender {
 	// Create base HTTP client with timeout
@@ -41,153 +64,605 @@ func New(cfg *config.Config) *Sender {
 		Timeout: 10 * time.Second,
 	}
 
+	// Breaker and limiter state is shared by all hook processes on the machine
+	store := NewStateStore(DefaultStatePath())
+
+	var targets []*target
+	for _, targetCfg := range cfg.WebhookTargets() {
+		targets = append(targets, newTarget(targetCfg, store))
+	}
+
+	// Create context for graceful shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Sender{
+		cfg:     cfg,
+		client:  client,
+		targets: targets,
+		outbox:  NewOutbox(DefaultOutboxDir()),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// newTarget creates the resilience stack for a webhook target
+func newTarget(cfg config.WebhookConfig, store *StateStore) *target {
+	key := stateKey(cfg.Name, cfg.URL)
+
 	// Parse retry config
-	retryConfig := parseRetryConfig(cfg.Notifications.Webhook.Retry)
-	retry := NewRetryer(retryConfig)
+	retry := NewRetryer(ParseRetryConfig(cfg.Retry))
 
 	// Parse circuit breaker config
-	cbCfg := cfg.Notifications.Webhook.CircuitBreaker
-	var circuitBreaker *CircuitBreaker
-	if cbCfg.Enabled {
-		timeout, _ := time.ParseDuration(cbCfg.Timeout)
-		if timeout == 0 {
-			timeout = 30 * time.Second
-		}
-		circuitBreaker = NewCircuitBreaker(cbCfg.FailureThreshold, cbCfg.SuccessThreshold, timeout)
+	circuitBreaker := NewCircuitBreakerFromConfig(cfg.CircuitBreaker)
+	if circuitBreaker != nil {
+		circuitBreaker.UseStore(store, key)
 	}
 
 	// Create rate limiter
 	var rateLimiter *RateLimiter
-	if cfg.Notifications.Webhook.RateLimit.Enabled {
-		rateLimiter = NewRateLimiter(cfg.Notifications.Webhook.RateLimit.RequestsPerMinute)
+	if cfg.RateLimit.Enabled {
+		rateLimiter = NewRateLimiter(cfg.RateLimit.RequestsPerMinute)
+		rateLimiter.UseStore(store, key)
 	}
 
 	// Create formatters
 	formatters := map[string]Formatter{
-		"slack":    &SlackFormatter{},
-		"discord":  &DiscordFormatter{},
-		"telegram": &TelegramFormatter{ChatID: cfg.Notifications.Webhook.ChatID},
-		"lark":     &LarkFormatter{},
+		"slack":      &SlackFormatter{},
+		"discord":    &DiscordFormatter{},
+		"telegram":   &TelegramFormatter{ChatID: cfg.ChatID},
+		"lark":       &LarkFormatter{},
+		"ntfy":       &NtfyFormatter{Config: cfg.Ntfy},
+		"gotify":     &GotifyFormatter{Config: cfg.Gotify},
+		"pushover":   &PushoverFormatter{Config: cfg.Pushover},
+		"teams":      &TeamsFormatter{},
+		"mattermost": &MattermostFormatter{Config: cfg.Mattermost},
+		"rocketchat": &RocketChatFormatter{Config: cfg.RocketChat},
+		"googlechat": &GoogleChatFormatter{},
+		"matrix":     &MatrixFormatter{Config: cfg.Matrix},
+		"dingtalk":   &DingTalkFormatter{Config: cfg.DingTalk},
+		"wecom":      &WeComFormatter{},
 	}
 
-	// Create context for graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
+	// Templates only apply to the custom preset, which has no formatter
+	var tmpl *template.Template
+	var tmplErr error
+	if _, ok := formatters[cfg.Preset]; !ok {
+		tmpl, tmplErr = parseTemplate(cfg)
+		if tmplErr != nil {
+			logging.Error("Webhook %s: %v", cfg.Name, tmplErr)
+		}
+	}
 
-	return &Sender{
+	var sign *signer
+	var signErr error
+	if cfg.Signing.Enabled {
+		sign, signErr = newSigner(cfg.Signing)
+		if signErr != nil {
+			signErr = fmt.Errorf("%w: %w", ErrSigningUnavailable, signErr)
+			logging.Error("Webhook %s: %v", cfg.Name, signErr)
+		}
+	}
+
+	return &target{
+		name:           cfg.Name,
+		key:            key,
 		cfg:            cfg,
-		client:         client,
 		retry:          retry,
 		circuitBreaker: circuitBreaker,
 		rateLimiter:    rateLimiter,
 		metrics:        NewMetrics(),
 		formatters:     formatters,
-		ctx:            ctx,
-		cancel:         cancel,
+		template:       tmpl,
+		templateErr:    tmplErr,
+		signer:         sign,
+		signingErr:     signErr,
 	}
 }
 
-// Send sends a webhook notification with full professional stack
-func (s *Sender) Send(status analyzer.Status, message, sessionID string) error {
+// Result describes the outcome of a webhook delivery to one target
+type Result struct {
+	Target     string        // Name of the webhook target
+	RequestID  string        // X-Request-ID sent with the request
+	StatusCode int           // HTTP status of the last attempt (0 if no response was received)
+	Latency    time.Duration // Total time spent, including retries
+	Attempts   int           // Number of HTTP requests made
+	Queued     bool          // Saved to the outbox for redelivery after a failure
+	Err        error         // Delivery error for this target (nil on success)
+}
+
+// Send sends a webhook notification to every target whose status and project filters
+// match, in parallel. Returns one result per matching target; the error joins the
+// failures of all targets.
+func (s *Sender) Send(status analyzer.Status, message, sessionID, cwd string) ([]Result, error) {
+	return s.send(status, message, sessionID, cwd, true)
+}
+
+// SendTest sends like Send, but never saves failures to the outbox: a synthetic test
+// notification must not be redelivered by a later hook
+func (s *Sender) SendTest(status analyzer.Status, message, sessionID, cwd string) ([]Result, error) {
+	return s.send(status, message, sessionID, cwd, false)
+}
+
+// send implements Send and SendTest; queue saves retryable failures to the outbox
+func (s *Sender) send(status analyzer.Status, message, sessionID, cwd string, queue bool) ([]Result, error) {
 	if !s.cfg.IsWebhookEnabled() {
 		logging.Debug("Webhooks disabled, skipping")
-		return nil
+		return nil, nil
 	}
 
-	// Check rate limit (non-blocking check)
-	if s.rateLimiter != nil && !s.rateLimiter.Allow() {
-		s.metrics.RecordRateLimited()
-		logging.Warn("Rate limit exceeded, dropping webhook")
-		return ErrRateLimitExceeded
+	var matched []*target
+	for _, t := range s.targets {
+		if t.matches(status, cwd) {
+			matched = append(matched, t)
+		} else {
+			logging.Debug("Webhook %s: filtered out (status=%s, cwd=%s)", t.name, status, cwd)
+		}
 	}
 
-	// Check circuit breaker
-	if s.circuitBreaker != nil && s.circuitBreaker.GetState() == StateOpen {
-		s.metrics.RecordCircuitOpen()
-		logging.Warn("Circuit breaker is open, skipping webhook")
-		return ErrCircuitOpen
+	results := make([]Result, len(matched))
+	errs := make([]error, len(matched))
+
+	var wg sync.WaitGroup
+	for i, t := range matched {
+		wg.Add(1)
+		go func(i int, t *target) {
+			defer wg.Done()
+			result, err := s.sendToTarget(t, status, message, sessionID, cwd, queue)
+			result.Err = err
+			results[i] = result
+			if err != nil {
+				errs[i] = fmt.Errorf("webhook %s: %w", t.name, err)
+			}
+		}(i, t)
+	}
+	wg.Wait()
+
+	return results, errors.Join(errs...)
+}
+
+// sendToTarget sends a notification to a single target with its full resilience stack.
+// With queue, failures that may succeed later are saved to the outbox.
+func (s *Sender) sendToTarget(t *target, status analyzer.Status, message, sessionID, cwd string, queue bool) (Result, error) {
+	// Build payload
+	payload, contentType, err := s.buildPayload(t, status, message, sessionID, cwd)
+	if err != nil {
+		return Result{Target: t.name}, fmt.Errorf("failed to build payload: %w", err)
+	}
+
+	// Validate URL
+	if err := validateURL(t.cfg.URL); err != nil {
+		return Result{Target: t.name}, fmt.Errorf("invalid webhook URL: %w", err)
 	}
 
 	// Generate request ID for tracing
-	requestID := uuid.New().String()
+	result, err := s.attempt(t, status, uuid.New().String(), payload, contentType)
+
+	// Rate limiting drops notifications on purpose; anything else transient is kept
+	if queue && err != nil && !errors.Is(err, ErrRateLimitExceeded) && !isPermanent(err) {
+		entry := &OutboxEntry{
+			RequestID:   result.RequestID,
+			Target:      t.name,
+			TargetKey:   t.key,
+			Status:      string(status),
+			ContentType: contentType,
+			Payload:     payload,
+			CreatedAt:   time.Now(),
+			Attempts:    result.Attempts,
+			LastError:   err.Error(),
+		}
+		if qErr := s.outbox.Add(entry); qErr != nil {
+			logging.Error("[%s] Failed to save webhook to outbox: %v", result.RequestID, qErr)
+		} else {
+			result.Queued = true
+			logging.Info("[%s] Webhook %s saved to outbox for redelivery", result.RequestID, t.name)
+		}
+	}
+
+	return result, err
+}
+
+// Defer queues a notification in the outbox of every matching target without sending
+// it, for delivery by the first flush after until (the end of quiet hours). Returns the
+// number of entries queued.
+func (s *Sender) Defer(status analyzer.Status, message, sessionID, cwd string, until time.Time) (int, error) {
+	if !s.cfg.IsWebhookEnabled() {
+		return 0, nil
+	}
+
+	queued := 0
+	var errs []error
+	for _, t := range s.targets {
+		if !t.matches(status, cwd) {
+			continue
+		}
+		// Nothing queued now could be delivered later
+		if t.signingErr != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", t.name, t.signingErr))
+			continue
+		}
+		payload, contentType, err := s.buildPayload(t, status, message, sessionID, cwd)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: failed to build payload: %w", t.name, err))
+			continue
+		}
+		entry := &OutboxEntry{
+			RequestID:     uuid.New().String(),
+			Target:        t.name,
+			TargetKey:     t.key,
+			Status:        string(status),
+			ContentType:   contentType,
+			Payload:       payload,
+			CreatedAt:     time.Now(),
+			Deferred:      true,
+			DeferredUntil: until,
+		}
+		if err := s.outbox.Add(entry); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: failed to save to outbox: %w", t.name, err))
+			continue
+		}
+		logging.Info("[%s] Webhook %s deferred to the outbox (quiet hours)", entry.RequestID, t.name)
+		queued++
+	}
+	return queued, errors.Join(errs...)
+}
+
+// attempt delivers a built payload to a target through its rate limiter, circuit breaker and retryer
+func (s *Sender) attempt(t *target, status analyzer.Status, requestID string, payload []byte, contentType string) (Result, error) {
+	result := Result{Target: t.name, RequestID: requestID}
+
+	// Never send unsigned requests to a target that expects signatures
+	if t.signingErr != nil {
+		return result, t.signingErr
+	}
+
+	// Check rate limit (non-blocking check)
+	if t.rateLimiter != nil && !t.rateLimiter.Allow() {
+		t.metrics.RecordRateLimited()
+		logging.Warn("Webhook %s: rate limit exceeded, dropping webhook", t.name)
+		return result, ErrRateLimitExceeded
+	}
+
+	// Check circuit breaker
+	if t.circuitBreaker != nil && t.circuitBreaker.GetState() == StateOpen {
+		t.metrics.RecordCircuitOpen()
+		logging.Warn("Webhook %s: circuit breaker is open, skipping webhook", t.name)
+		return result, ErrCircuitOpen
+	}
 
 	// Record metrics
-	s.metrics.RecordRequest()
+	t.metrics.RecordRequest()
 	start := time.Now()
 
 	// Execute with retry and circuit breaker
-	err := s.sendWithRetryAndCircuitBreaker(requestID, status, message, sessionID)
+	err := s.sendWithRetryAndCircuitBreaker(t, &result, payload, contentType, s.requestHeaders(t, status))
 
 	// Record result
-	latency := time.Since(start)
+	result.Latency = time.Since(start)
 	if err != nil {
-		s.metrics.RecordFailure()
-		logging.Error("[%s] Webhook failed after retries: %v (latency: %v)", requestID, err, latency)
+		t.metrics.RecordFailure()
+		logging.Error("[%s] Webhook %s failed after retries: %v (latency: %v)", result.RequestID, t.name, err, result.Latency)
 	} else {
-		s.metrics.RecordSuccess(status, latency)
-		logging.Info("[%s] Webhook sent successfully (latency: %v)", requestID, latency)
+		t.metrics.RecordSuccess(status, result.Latency)
+		logging.Info("[%s] Webhook %s sent successfully (latency: %v)", result.RequestID, t.name, result.Latency)
 	}
 
 	// Update circuit breaker state in metrics
-	if s.circuitBreaker != nil {
-		s.metrics.UpdateCircuitBreakerState(s.circuitBreaker.GetState())
+	if t.circuitBreaker != nil {
+		t.metrics.UpdateCircuitBreakerState(t.circuitBreaker.GetState())
 	}
 
-	return err
+	return result, err
 }
 
 // sendWithRetryAndCircuitBreaker executes the webhook with retry and circuit breaker
-func (s *Sender) sendWithRetryAndCircuitBreaker(requestID string, status analyzer.Status, message, sessionID string) error {
-	webhookCfg := s.cfg.Notifications.Webhook
-
-	// Build payload
-	payload, contentType, err := s.buildPayload(status, message, sessionID)
-	if err != nil {
-		return fmt.Errorf("failed to build payload: %w", err)
-	}
-
-	// Validate URL
-	if err := validateURL(webhookCfg.URL); err != nil {
-		return fmt.Errorf("invalid webhook URL: %w", err)
-	}
-
+func (s *Sender) sendWithRetryAndCircuitBreaker(t *target, result *Result, payload []byte, contentType string, headers map[string]string) error {
 	// Create request function for retry
 	sendFn := func(ctx context.Context) error {
-		return s.sendHTTPRequest(ctx, requestID, webhookCfg.URL, payload, contentType, webhookCfg.Headers)
+		result.Attempts++
+		method, endpoint := t.endpoint(result.RequestID)
+		statusCode, body, err := s.sendHTTPRequest(ctx, result.RequestID, method, endpoint, payload, contentType, headers, t.signer)
+		result.StatusCode = statusCode
+		if err == nil {
+			err = t.checkResponse(body)
+		}
+		return err
 	}
 
 	// Execute with circuit breaker and retry
 	var executeErr error
-	if s.circuitBreaker != nil {
+	if t.circuitBreaker != nil {
 		// Wrap with circuit breaker
-		executeErr = s.circuitBreaker.Execute(s.ctx, func() error {
+		executeErr = t.circuitBreaker.Execute(s.ctx, func() error {
 			// Execute with retry
-			return s.retry.Do(s.ctx, sendFn)
+			return t.retry.Do(s.ctx, sendFn)
 		})
 	} else {
 		// Just retry without circuit breaker
-		executeErr = s.retry.Do(s.ctx, sendFn)
+		executeErr = t.retry.Do(s.ctx, sendFn)
 	}
 
 	return executeErr
 }
 
-// buildPayload builds the webhook payload based on preset
-func (s *Sender) buildPayload(status analyzer.Status, message, sessionID string) ([]byte, string, error) {
-	webhookCfg := s.cfg.Notifications.Webhook
+// endpoint returns the method and URL of a request to t: a POST to the configured URL
+// unless the preset builds its own endpoint. It is called for every attempt.
+func (t *target) endpoint(requestID string) (string, string) {
+	if formatter, ok := t.formatters[t.cfg.Preset].(EndpointFormatter); ok {
+		return formatter.Endpoint(t.cfg.URL, requestID)
+	}
+	return http.MethodPost, t.cfg.URL
+}
+
+// checkResponse returns the error reported in a 2xx response body, for presets
+// whose API signals failures that way
+func (t *target) checkResponse(body []byte) error {
+	if formatter, ok := t.formatters[t.cfg.Preset].(ResponseFormatter); ok {
+		return formatter.CheckResponse(body)
+	}
+	return nil
+}
+
+// requestHeaders returns the headers for a request to t: the preset's headers
+// (if it uses any), overridden by the configured custom headers
+func (s *Sender) requestHeaders(t *target, status analyzer.Status) map[string]string {
+	formatter, ok := t.formatters[t.cfg.Preset].(HeaderFormatter)
+	if !ok {
+		return t.cfg.Headers
+	}
+
+	statusInfo, _ := s.cfg.GetStatusInfo(string(status))
+	headers := formatter.Headers(status, statusInfo)
+	for key, value := range t.cfg.Headers {
+		headers[key] = value
+	}
+	return headers
+}
+
+// FlushResult summarizes an outbox flush
+type FlushResult struct {
+	Delivered int // Redelivered and removed from the outbox
+	Failed    int // Redelivery failed; kept for a later flush
+	Dropped   int // Rejected permanently by the endpoint (4xx); removed
+	Expired   int // Older than the outbox's maximum age; removed undelivered
+	Remaining int // Entries still in the outbox
+}
+
+// Flush redelivers outbox entries, oldest first, with their original request IDs.
+// Entries are only sent to the target with the same name and URL they were queued
+// for; entries of other targets stay queued until they expire.
+// A target's entries are left queued while its circuit is open or its rate limit is reached,
+// and deferred entries until their quiet hours end.
+// Returns an error if another process is already flushing.
+func (s *Sender) Flush() (FlushResult, error) {
+	var res FlushResult
+
+	lock, err := s.outbox.lock()
+	if err != nil {
+		return res, fmt.Errorf("outbox is busy: %w", err)
+	}
+	defer lock.Unlock()
+
+	entries, err := s.outbox.List()
+	if err != nil {
+		return res, err
+	}
+
+	now := time.Now()
+	blocked := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Expired(now) {
+			logging.Warn("[%s] Dropping outbox entry for webhook %s queued at %s: too old",
+				entry.RequestID, entry.Target, entry.CreatedAt.Format(time.RFC3339))
+			if rmErr := s.outbox.Remove(entry); rmErr != nil {
+				logging.Error("[%s] Failed to remove expired outbox entry: %v", entry.RequestID, rmErr)
+			}
+			res.Expired++
+			continue
+		}
+
+		// A break-through notification during quiet hours flushes too
+		if entry.Deferred && now.Before(entry.DeferredUntil) {
+			res.Remaining++
+			continue
+		}
+
+		// Another project's config may use the same name for another destination
+		t := s.findTarget(entry.TargetKey)
+		if t == nil || blocked[entry.TargetKey] || s.ctx.Err() != nil {
+			res.Remaining++
+			continue
+		}
+
+		result, err := s.attempt(t, analyzer.Status(entry.Status), entry.RequestID, entry.Payload, entry.ContentType)
+		switch {
+		case err == nil:
+			if rmErr := s.outbox.Remove(entry); rmErr != nil {
+				logging.Error("[%s] Delivered but could not remove outbox entry: %v", entry.RequestID, rmErr)
+			}
+			res.Delivered++
+		case errors.Is(err, ErrRateLimitExceeded), errors.Is(err, ErrCircuitOpen):
+			blocked[entry.TargetKey] = true
+			res.Remaining++
+		case isPermanent(err):
+			logging.Error("[%s] Webhook %s rejected queued notification, dropping it: %v", entry.RequestID, entry.Target, err)
+			_ = s.outbox.Remove(entry)
+			res.Dropped++
+		case s.ctx.Err() != nil:
+			// Cancelled by Shutdown; not the endpoint's fault
+			res.Remaining++
+		default:
+			entry.Attempts += result.Attempts
+			entry.LastError = err.Error()
+			if upErr := s.outbox.Update(entry); upErr != nil {
+				logging.Error("[%s] Failed to update outbox entry: %v", entry.RequestID, upErr)
+			}
+			res.Failed++
+			res.Remaining++
+		}
+	}
+
+	return res, nil
+}
+
+// FlushAsync flushes the outbox in the background; Shutdown waits for it
+func (s *Sender) FlushAsync() {
+	s.wg.Add(1)
+	errorhandler.SafeGo(func() {
+		defer s.wg.Done()
+
+		res, err := s.Flush()
+		if err != nil {
+			logging.Debug("Outbox flush skipped: %v", err)
+			return
+		}
+		if res.Delivered+res.Failed+res.Dropped+res.Expired > 0 {
+			logging.Info("Outbox flush: %d delivered, %d failed, %d dropped, %d expired, %d remaining",
+				res.Delivered, res.Failed, res.Dropped, res.Expired, res.Remaining)
+		}
+	})
+}
+
+// Outbox returns the sender's outbox
+func (s *Sender) Outbox() *Outbox {
+	return s.outbox
+}
+
+// findTarget returns the enabled target with the given stateKey
+func (s *Sender) findTarget(key string) *target {
+	for _, t := range s.targets {
+		if key != "" && t.key == key {
+			return t
+		}
+	}
+	return nil
+}
+
+// isPermanent reports whether a delivery error can never succeed on redelivery
+func isPermanent(err error) bool {
+	if errors.Is(err, ErrSigningUnavailable) {
+		return true
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 400 && httpErr.StatusCode < 500 && httpErr.StatusCode != http.StatusTooManyRequests
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return !apiErr.RateLimited
+	}
+	return false
+}
+
+// matches reports whether the target's status and project filters accept a notification
+func (t *target) matches(status analyzer.Status, cwd string) bool {
+	if len(t.cfg.Statuses) > 0 {
+		found := false
+		for _, s := range t.cfg.Statuses {
+			if s == string(status) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(t.cfg.Projects) > 0 {
+		for _, pattern := range t.cfg.Projects {
+			if matchProject(pattern, cwd) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// expandHome replaces a leading "~/" with the user's home directory
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+// matchProject reports whether cwd matches a project filter: a glob against the full
+// path or the folder name, or a directory that contains cwd
+func matchProject(pattern, cwd string) bool {
+	if cwd == "" {
+		return false
+	}
+	pattern = expandHome(platform.ExpandEnv(pattern))
+
+	if ok, _ := filepath.Match(pattern, cwd); ok {
+		return true
+	}
+	if ok, _ := filepath.Match(pattern, filepath.Base(cwd)); ok {
+		return true
+	}
+
+	// A plain directory also matches everything below it
+	if filepath.IsAbs(pattern) {
+		rel, err := filepath.Rel(filepath.Clean(pattern), cwd)
+		return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+	}
+	return false
+}
+
+// buildPayload builds the webhook payload based on the target's preset
+func (s *Sender) buildPayload(t *target, status analyzer.Status, message, sessionID, cwd string) ([]byte, string, error) {
 	statusInfo, _ := s.cfg.GetStatusInfo(string(status))
 
+	// Custom template, if configured
+	if t.templateErr != nil {
+		return nil, "", t.templateErr
+	}
+	if t.template != nil {
+		contentType := customContentType(t.cfg)
+		data := newTemplateData(status, message, sessionID, cwd, statusInfo)
+		payload, err := renderTemplate(t.template, data, contentType)
+		return payload, contentType, err
+	}
+
 	// Use formatter if available
-	if formatter, ok := s.formatters[webhookCfg.Preset]; ok {
-		payload, err := formatter.Format(status, message, sessionID, statusInfo)
+	if formatter, ok := t.formatters[t.cfg.Preset]; ok {
+		var payload interface{}
+		var err error
+		if cf, ok := formatter.(ContextFormatter); ok {
+			payload, err = cf.FormatContext(newTemplateData(status, message, sessionID, cwd, statusInfo))
+		} else {
+			payload, err = formatter.Format(status, message, sessionID, statusInfo)
+		}
 		if err != nil {
 			return nil, "", err
 		}
+		if text, ok := payload.(string); ok {
+			return []byte(text), "text/plain; charset=utf-8", nil
+		}
 		data, err := json.Marshal(payload)
 		return data, "application/json", err
 	}
 
 	// Fallback to custom format
-	return s.buildCustomPayload(status, message, sessionID, webhookCfg.Format, statusInfo)
+	return s.buildCustomPayload(status, message, sessionID, t.cfg.Format, statusInfo)
+}
+
+// customContentType returns the content type of a custom payload: a Content-Type
+// header if one is configured, otherwise the one matching the format
+func customContentType(cfg config.WebhookConfig) string {
+	for key, value := range cfg.Headers {
+		if strings.EqualFold(key, "Content-Type") {
+			return value
+		}
+	}
+	if cfg.Format == "text" {
+		return "text/plain"
+	}
+	return "application/json"
 }
 
 // buildCustomPayload builds a custom webhook payload
@@ -211,11 +686,11 @@ func (s *Sender) buildCustomPayload(status analyzer.Status, message, sessionID,
 	return data, "application/json", err
 }
 
-// sendHTTPRequest sends the actual HTTP request
-func (s *Sender) sendHTTPRequest(ctx context.Context, requestID, url string, payload []byte, contentType string, headers map[string]string) error {
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+// sendHTTPRequest sends the actual HTTP request and returns the response status code and body
+func (s *Sender) sendHTTPRequest(ctx context.Context, requestID, method, url string, payload []byte, contentType string, headers map[string]string, sign *signer) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
@@ -228,10 +703,17 @@ func (s *Sender) sendHTTPRequest(ctx context.Context, requestID, url string, pay
 		req.Header.Set(key, value)
 	}
 
+	// Sign last so custom headers can't replace the signature
+	if sign != nil {
+		if err := sign.sign(req, payload); err != nil {
+			return 0, nil, fmt.Errorf("failed to sign request: %w", err)
+		}
+	}
+
 	// Send request
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("HTTP request failed: %w", err)
+		return 0, nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -240,20 +722,20 @@ func (s *Sender) sendHTTPRequest(ctx context.Context, requestID, url string, pay
 
 	// Check status code
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return NewHTTPError(resp, string(body))
+		return resp.StatusCode, body, NewHTTPError(resp, string(body))
 	}
 
-	return nil
+	return resp.StatusCode, body, nil
 }
 
 // SendAsync sends a webhook asynchronously with graceful shutdown support
-func (s *Sender) SendAsync(status analyzer.Status, message, sessionID string) {
+func (s *Sender) SendAsync(status analyzer.Status, message, sessionID, cwd string) {
 	s.wg.Add(1)
 	// Use SafeGo to protect against panics in async webhook sending
 	errorhandler.SafeGo(func() {
 		defer s.wg.Done()
 
-		if err := s.Send(status, message, sessionID); err != nil {
+		if _, err := s.Send(status, message, sessionID, cwd); err != nil {
 			errorhandler.HandleError(err, "Async webhook send failed")
 		}
 	})
@@ -280,22 +762,62 @@ func (s *Sender) Shutdown(timeout time.Duration) error {
 		logging.Info("All webhook requests completed")
 		return nil
 	case <-time.After(timeout):
-		// Timeout reached - force cancel remaining requests
+		// Timeout reached - force cancel remaining requests, then give them
+		// a moment to save their payloads to the outbox
 		s.cancel()
-		logging.Warn("Webhook shutdown timeout, some requests may be incomplete")
+		select {
+		case <-done:
+		case <-time.After(outboxSaveGrace):
+		}
+		logging.Warn("Webhook shutdown timeout, incomplete requests were saved to the outbox")
 		return fmt.Errorf("shutdown timeout after %v", timeout)
 	}
 }
 
-// GetMetrics returns current metrics
+// GetMetrics returns metrics combined across all targets
 func (s *Sender) GetMetrics() Stats {
-	return s.metrics.GetStats()
+	combined := Stats{StatusCounts: make(map[analyzer.Status]int64)}
+	var totalLatencyMs int64
+
+	for _, t := range s.targets {
+		stats := t.metrics.GetStats()
+		combined.TotalRequests += stats.TotalRequests
+		combined.SuccessfulRequests += stats.SuccessfulRequests
+		combined.FailedRequests += stats.FailedRequests
+		combined.RetriedRequests += stats.RetriedRequests
+		combined.RateLimitedRequests += stats.RateLimitedRequests
+		combined.CircuitOpenRequests += stats.CircuitOpenRequests
+		for status, count := range stats.StatusCounts {
+			combined.StatusCounts[status] += count
+		}
+		totalLatencyMs += stats.AverageLatencyMs * stats.SuccessfulRequests
+
+		// Report the worst breaker state: open, then half-open, then closed
+		if stats.CircuitBreakerState == StateOpen ||
+			(stats.CircuitBreakerState == StateHalfOpen && combined.CircuitBreakerState != StateOpen) {
+			combined.CircuitBreakerState = stats.CircuitBreakerState
+		}
+	}
+
+	if combined.SuccessfulRequests > 0 {
+		combined.AverageLatencyMs = totalLatencyMs / combined.SuccessfulRequests
+	}
+	return combined
+}
+
+// GetTargetMetrics returns the metrics of each target, keyed by target name
+func (s *Sender) GetTargetMetrics() map[string]Stats {
+	stats := make(map[string]Stats, len(s.targets))
+	for _, t := range s.targets {
+		stats[t.name] = t.metrics.GetStats()
+	}
+	return stats
 }
 
 // Helper functions
 
-// parseRetryConfig converts config.RetryConfig to webhook.RetryConfig
-func parseRetryConfig(cfg config.RetryConfig) RetryConfig {
+// ParseRetryConfig converts config.RetryConfig to webhook.RetryConfig
+func ParseRetryConfig(cfg config.RetryConfig) RetryConfig {
 	initialBackoff, _ := time.ParseDuration(cfg.InitialBackoff)
 	if initialBackoff == 0 {
 		initialBackoff = 1 * time.Second
@@ -315,6 +837,19 @@ func parseRetryConfig(cfg config.RetryConfig) RetryConfig {
 	}
 }
 
+// NewCircuitBreakerFromConfig creates a circuit breaker from config.CircuitBreakerConfig,
+// or returns nil if it is disabled
+func NewCircuitBreakerFromConfig(cfg config.CircuitBreakerConfig) *CircuitBreaker {
+	if !cfg.Enabled {
+		return nil
+	}
+	timeout, _ := time.ParseDuration(cfg.Timeout)
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	return NewCircuitBreaker(cfg.FailureThreshold, cfg.SuccessThreshold, timeout)
+}
+
 // validateURL validates the webhook URL
 func validateURL(rawURL string) error {
 	if rawURL == "" {
@@ -2,9 +2,12 @@ package webhook
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"path/filepath"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -58,7 +61,7 @@ func TestSenderSendSuccess(t *testing.T) {
 	cfg := newTestConfig(server.URL)
 	sender := New(cfg)
 
-	err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123")
+	_, err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123", "")
 	if err != nil {
 		t.Errorf("Expected success, got error: %v", err)
 	}
@@ -89,7 +92,7 @@ func TestSenderSendWithRetry(t *testing.T) {
 	cfg := newTestConfig(server.URL)
 	sender := New(cfg)
 
-	err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123")
+	_, err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123", "")
 	if err != nil {
 		t.Errorf("Expected success after retry, got error: %v", err)
 	}
@@ -104,6 +107,69 @@ func TestSenderSendWithRetry(t *testing.T) {
 	}
 }
 
+func TestSenderSendResult(t *testing.T) {
+	attempts := atomic.Int32{}
+	var requestID atomic.Value
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID.Store(r.Header.Get("X-Request-ID"))
+		if attempts.Add(1) < 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sender := New(newTestConfig(server.URL))
+
+	results, err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123", "")
+	if err != nil {
+		t.Fatalf("Expected success after retry, got error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	result := results[0]
+
+	if result.RequestID == "" || result.RequestID != requestID.Load() {
+		t.Errorf("Expected request ID %v, got %q", requestID.Load(), result.RequestID)
+	}
+	if result.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected status code %d, got %d", http.StatusAccepted, result.StatusCode)
+	}
+	if result.Attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", result.Attempts)
+	}
+	if result.Latency <= 0 {
+		t.Errorf("Expected positive latency, got %v", result.Latency)
+	}
+}
+
+func TestSenderSendResultOnFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	sender := New(newTestConfig(server.URL))
+
+	results, err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123", "")
+	if err == nil {
+		t.Fatal("Expected error for 403 response")
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	result := results[0]
+	if result.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, result.StatusCode)
+	}
+	if result.Attempts != 1 {
+		t.Errorf("Expected 1 attempt (4xx is not retried), got %d", result.Attempts)
+	}
+}
+
 func TestSenderSendMaxRetriesExceeded(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusServiceUnavailable)
@@ -113,7 +179,7 @@ func TestSenderSendMaxRetriesExceeded(t *testing.T) {
 	cfg := newTestConfig(server.URL)
 	sender := New(cfg)
 
-	err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123")
+	_, err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123", "")
 	if err == nil {
 		t.Error("Expected error after max retries, got nil")
 	}
@@ -135,12 +201,12 @@ func TestSenderSendCircuitBreaker(t *testing.T) {
 
 	// Trigger circuit breaker by failing threshold times
 	for i := 0; i < 3; i++ {
-		_ = sender.Send(analyzer.StatusTaskComplete, "Test", "session-123")
+		_, _ = sender.Send(analyzer.StatusTaskComplete, "Test", "session-123", "")
 	}
 
 	// Next request should fail with circuit open
-	err := sender.Send(analyzer.StatusTaskComplete, "Test", "session-123")
-	if err != ErrCircuitOpen {
+	_, err := sender.Send(analyzer.StatusTaskComplete, "Test", "session-123", "")
+	if !errors.Is(err, ErrCircuitOpen) {
 		t.Errorf("Expected ErrCircuitOpen, got: %v", err)
 	}
 
@@ -163,12 +229,12 @@ func TestSenderSendRateLimit(t *testing.T) {
 
 	// Exhaust the rate limiter bucket (starts with 60 tokens)
 	for i := 0; i < 70; i++ {
-		_ = sender.Send(analyzer.StatusTaskComplete, "Test", "session-123")
+		_, _ = sender.Send(analyzer.StatusTaskComplete, "Test", "session-123", "")
 	}
 
 	// Next request should be rate limited
-	err := sender.Send(analyzer.StatusTaskComplete, "Test", "session-123")
-	if err != ErrRateLimitExceeded {
+	_, err := sender.Send(analyzer.StatusTaskComplete, "Test", "session-123", "")
+	if !errors.Is(err, ErrRateLimitExceeded) {
 		t.Errorf("Expected ErrRateLimitExceeded, got: %v", err)
 	}
 
@@ -192,7 +258,7 @@ func TestSenderSendSlackFormat(t *testing.T) {
 	cfg.Notifications.Webhook.Preset = "slack"
 	sender := New(cfg)
 
-	err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123")
+	_, err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123", "")
 	if err != nil {
 		t.Fatalf("Send failed: %v", err)
 	}
@@ -223,7 +289,7 @@ func TestSenderSendDiscordFormat(t *testing.T) {
 	cfg.Notifications.Webhook.Preset = "discord"
 	sender := New(cfg)
 
-	err := sender.Send(analyzer.StatusQuestion, "What should we do?", "session-456")
+	_, err := sender.Send(analyzer.StatusQuestion, "What should we do?", "session-456", "")
 	if err != nil {
 		t.Fatalf("Send failed: %v", err)
 	}
@@ -256,7 +322,7 @@ func TestSenderSendTelegramFormat(t *testing.T) {
 	cfg.Notifications.Webhook.ChatID = "123456789"
 	sender := New(cfg)
 
-	err := sender.Send(analyzer.StatusTaskComplete, "Done!", "session-789")
+	_, err := sender.Send(analyzer.StatusTaskComplete, "Done!", "session-789", "")
 	if err != nil {
 		t.Fatalf("Send failed: %v", err)
 	}
@@ -291,7 +357,7 @@ func TestSenderSendCustomHeaders(t *testing.T) {
 	}
 	sender := New(cfg)
 
-	err := sender.Send(analyzer.StatusTaskComplete, "Test", "session-123")
+	_, err := sender.Send(analyzer.StatusTaskComplete, "Test", "session-123", "")
 	if err != nil {
 		t.Fatalf("Send failed: %v", err)
 	}
@@ -327,7 +393,7 @@ func TestSenderSendDisabled(t *testing.T) {
 	cfg.Notifications.Webhook.Enabled = false
 	sender := New(cfg)
 
-	err := sender.Send(analyzer.StatusTaskComplete, "Test", "session-123")
+	_, err := sender.Send(analyzer.StatusTaskComplete, "Test", "session-123", "")
 	if err != nil {
 		t.Errorf("Send should succeed (skipped), got error: %v", err)
 	}
@@ -348,7 +414,7 @@ func TestSenderSendAsync(t *testing.T) {
 
 	// Send async - should not block
 	start := time.Now()
-	sender.SendAsync(analyzer.StatusTaskComplete, "Test", "session-123")
+	sender.SendAsync(analyzer.StatusTaskComplete, "Test", "session-123", "")
 	elapsed := time.Since(start)
 
 	// Should return immediately
@@ -378,7 +444,7 @@ func TestSenderShutdown(t *testing.T) {
 	sender := New(cfg)
 
 	// Start async send
-	sender.SendAsync(analyzer.StatusTaskComplete, "Test", "session-123")
+	sender.SendAsync(analyzer.StatusTaskComplete, "Test", "session-123", "")
 
 	// Give it time to start
 	time.Sleep(50 * time.Millisecond)
@@ -415,7 +481,7 @@ func TestSenderShutdownCancelsRequests(t *testing.T) {
 
 	// Start multiple async sends
 	for i := 0; i < 5; i++ {
-		sender.SendAsync(analyzer.StatusTaskComplete, "Test", "session-123")
+		sender.SendAsync(analyzer.StatusTaskComplete, "Test", "session-123", "")
 	}
 
 	// Give requests time to start
@@ -486,7 +552,7 @@ func TestSenderMetricsTracking(t *testing.T) {
 
 	// Send multiple requests
 	for i := 0; i < 10; i++ {
-		_ = sender.Send(analyzer.StatusTaskComplete, "Test", "session-123")
+		_, _ = sender.Send(analyzer.StatusTaskComplete, "Test", "session-123", "")
 	}
 
 	stats := sender.GetMetrics()
@@ -516,7 +582,7 @@ func TestSenderContextCancellation(t *testing.T) {
 	sender.cancel()
 
 	// Send should fail with context canceled
-	err := sender.Send(analyzer.StatusTaskComplete, "Test", "session-123")
+	_, err := sender.Send(analyzer.StatusTaskComplete, "Test", "session-123", "")
 	if err == nil {
 		t.Error("Expected error with canceled context, got nil")
 	}
@@ -562,7 +628,7 @@ func TestSenderSendAsyncWithShutdown(t *testing.T) {
 	// Send multiple async requests
 	numRequests := 3
 	for i := 0; i < numRequests; i++ {
-		sender.SendAsync(analyzer.StatusTaskComplete, "Test message", "session-123")
+		sender.SendAsync(analyzer.StatusTaskComplete, "Test message", "session-123", "")
 	}
 
 	// Immediately call shutdown - it should wait for all requests
@@ -595,7 +661,7 @@ func TestWebhookShutdownWaitsForRequests(t *testing.T) {
 	sender := New(cfg)
 
 	// Start async send
-	sender.SendAsync(analyzer.StatusTaskComplete, "Test", "session-123")
+	sender.SendAsync(analyzer.StatusTaskComplete, "Test", "session-123", "")
 
 	// Give request time to start
 	time.Sleep(50 * time.Millisecond)
@@ -620,3 +686,428 @@ func TestWebhookShutdownWaitsForRequests(t *testing.T) {
 		t.Error("Request should have completed before Shutdown returned")
 	}
 }
+
+func newTestTarget(name, url string) config.WebhookConfig {
+	target := newTestConfig(url).Notifications.Webhook
+	target.Name = name
+	return target
+}
+
+func TestSenderSendMultipleTargets(t *testing.T) {
+	var slackHits, telegramHits atomic.Int32
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slackHits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slack.Close()
+	telegram := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		telegramHits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer telegram.Close()
+
+	cfg := newTestConfig("")
+	cfg.Notifications.Webhook.Enabled = false
+	completions := newTestTarget("completions", slack.URL)
+	completions.Statuses = []string{"task_complete"}
+	questions := newTestTarget("questions", telegram.URL)
+	questions.Statuses = []string{"question"}
+	cfg.Notifications.Webhooks = []config.WebhookConfig{completions, questions}
+
+	sender := New(cfg)
+
+	results, err := sender.Send(analyzer.StatusQuestion, "Which one?", "session-123", "")
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Target != "questions" {
+		t.Fatalf("Expected a single result for target 'questions', got %+v", results)
+	}
+	if slackHits.Load() != 0 || telegramHits.Load() != 1 {
+		t.Errorf("Expected only the questions target to be called, got slack=%d telegram=%d", slackHits.Load(), telegramHits.Load())
+	}
+
+	// A target without a status filter receives everything
+	questions.Statuses = nil
+	cfg.Notifications.Webhooks = []config.WebhookConfig{completions, questions}
+	sender = New(cfg)
+
+	results, err = sender.Send(analyzer.StatusTaskComplete, "Done", "session-123", "")
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].Target != "completions" || results[1].Target != "questions" {
+		t.Errorf("Expected results in target order, got %q and %q", results[0].Target, results[1].Target)
+	}
+}
+
+func TestSenderSendProjectFilter(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Projects = []string{"/work/acme"}
+	sender := New(cfg)
+
+	results, _ := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123", "/home/me/personal")
+	if len(results) != 0 {
+		t.Errorf("Expected no results for a non-matching project, got %d", len(results))
+	}
+
+	results, err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123", "/work/acme/api")
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if len(results) != 1 || hits.Load() != 1 {
+		t.Errorf("Expected 1 delivery for a matching project, got results=%d hits=%d", len(results), hits.Load())
+	}
+}
+
+func TestSenderTargetsHaveIndependentState(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	cfg := newTestConfig("")
+	cfg.Notifications.Webhook.Enabled = false
+	cfg.Notifications.Webhooks = []config.WebhookConfig{
+		newTestTarget("broken", failing.URL),
+		newTestTarget("working", healthy.URL),
+	}
+	sender := New(cfg)
+
+	// Open the broken target's circuit breaker
+	for i := 0; i < 3; i++ {
+		_, _ = sender.Send(analyzer.StatusTaskComplete, "Test", "session-123", "")
+	}
+
+	results, err := sender.Send(analyzer.StatusTaskComplete, "Test", "session-123", "")
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen from the broken target, got: %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "webhook broken") {
+		t.Errorf("Expected error to name the failing target, got: %v", err)
+	}
+	if len(results) != 2 || results[1].StatusCode != http.StatusOK {
+		t.Errorf("Expected the working target to keep delivering, got %+v", results)
+	}
+
+	stats := sender.GetTargetMetrics()
+	if stats["broken"].CircuitBreakerState != StateOpen {
+		t.Errorf("Expected broken target circuit to be open, got %v", stats["broken"].CircuitBreakerState)
+	}
+	if stats["working"].CircuitBreakerState != StateClosed {
+		t.Errorf("Expected working target circuit to be closed, got %v", stats["working"].CircuitBreakerState)
+	}
+	if stats["working"].SuccessfulRequests != 4 {
+		t.Errorf("Expected 4 successful requests for working target, got %d", stats["working"].SuccessfulRequests)
+	}
+
+	combined := sender.GetMetrics()
+	if combined.CircuitBreakerState != StateOpen {
+		t.Errorf("Expected combined state to report the open circuit, got %v", combined.CircuitBreakerState)
+	}
+	if combined.SuccessfulRequests != 4 {
+		t.Errorf("Expected 4 successful requests in combined metrics, got %d", combined.SuccessfulRequests)
+	}
+}
+
+func TestMatchProject(t *testing.T) {
+	tests := []struct {
+		pattern string
+		cwd     string
+		want    bool
+	}{
+		{"/work/acme", "/work/acme", true},
+		{"/work/acme", "/work/acme/api", true},
+		{"/work/acme", "/work/acme-old", false},
+		{"/work/*", "/work/acme", true},
+		{"acme", "/work/acme", true},
+		{"acme-*", "/work/acme-api", true},
+		{"acme", "/work/other", false},
+		{"acme", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchProject(tt.pattern, tt.cwd); got != tt.want {
+			t.Errorf("matchProject(%q, %q) = %v, want %v", tt.pattern, tt.cwd, got, tt.want)
+		}
+	}
+}
+
+func TestSenderNtfyPreset(t *testing.T) {
+	var body string
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		headers = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL + "/claude")
+	cfg.Notifications.Webhook.Preset = "ntfy"
+	cfg.Notifications.Webhook.Ntfy = config.NtfyConfig{Token: "tk_abc"}
+	cfg.Notifications.Webhook.Headers = map[string]string{"X-Priority": "urgent"}
+	sender := New(cfg)
+
+	if _, err := sender.Send(analyzer.StatusQuestion, "Which database?", "session-123", ""); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if body != "Which database?" {
+		t.Errorf("Expected plain text message body, got %q", body)
+	}
+	if ct := headers.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Expected text/plain, got %s", ct)
+	}
+	if headers.Get("X-Title") != "Question" || headers.Get("X-Tags") != "question" {
+		t.Errorf("Expected ntfy title and tags headers, got %v", headers)
+	}
+	if headers.Get("X-Priority") != "urgent" {
+		t.Errorf("Expected custom header to override the preset priority, got %s", headers.Get("X-Priority"))
+	}
+	if headers.Get("Authorization") != "Bearer tk_abc" {
+		t.Errorf("Expected bearer auth, got %q", headers.Get("Authorization"))
+	}
+}
+
+func TestSenderGotifyPresetRetries(t *testing.T) {
+	var attempts int32
+	var body map[string]interface{}
+	var key string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		key = r.Header.Get("X-Gotify-Key")
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL + "/message")
+	cfg.Notifications.Webhook.Preset = "gotify"
+	cfg.Notifications.Webhook.Gotify = config.GotifyConfig{Token: "AbCd"}
+	sender := New(cfg)
+
+	results, err := sender.Send(analyzer.StatusQuestion, "Which database?", "session-123", "")
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if results[0].Attempts != 2 {
+		t.Errorf("Expected a retry after 503, got %d attempts", results[0].Attempts)
+	}
+	if key != "AbCd" {
+		t.Errorf("Expected app token header, got %q", key)
+	}
+	if body["message"] != "Which database?" || body["priority"] != float64(8) {
+		t.Errorf("Unexpected gotify body: %v", body)
+	}
+}
+
+func TestSenderPushoverPreset(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Preset = "pushover"
+	cfg.Notifications.Webhook.Pushover = config.PushoverConfig{Token: "app-token", User: "user-key", Retry: 30, Expire: 600}
+	cfg.Statuses["session_limit_reached"] = config.StatusInfo{Title: "Session Limit"}
+	sender := New(cfg)
+
+	if _, err := sender.Send(analyzer.StatusSessionLimitReached, "Limit reached", "session-123", ""); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if body["priority"] != float64(2) || body["retry"] != float64(30) || body["expire"] != float64(600) {
+		t.Errorf("Expected emergency priority with retry and expire, got %v", body)
+	}
+	if body["token"] != "app-token" || body["user"] != "user-key" || body["title"] != "Session Limit" {
+		t.Errorf("Unexpected pushover body: %v", body)
+	}
+}
+
+func TestSenderTeamsPreset(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Preset = "teams"
+	sender := New(cfg)
+
+	cwd := t.TempDir()
+	if _, err := sender.Send(analyzer.StatusTaskComplete, "["+filepath.Base(cwd)+"] Done", "session-123", cwd); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if !strings.Contains(body, `"AdaptiveCard"`) {
+		t.Errorf("Expected an Adaptive Card, got %s", body)
+	}
+	if !strings.Contains(body, `"text":"Done"`) {
+		t.Errorf("Expected the message without the folder prefix, got %s", body)
+	}
+	if !strings.Contains(body, `"title":"Project"`) || !strings.Contains(body, `"title":"Session"`) {
+		t.Errorf("Expected project and session facts, got %s", body)
+	}
+}
+
+func TestSenderChatPresets(t *testing.T) {
+	tests := []struct {
+		preset string
+		query  string
+		want   string
+	}{
+		{"mattermost", "", `"attachments":[{"color":"#28a745","fallback":"Task Complete: Done"`},
+		{"rocketchat", "", `"alias":"Claude Code"`},
+		{"googlechat", "?key=k&token=t", `"cardsV2"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			var body, contentType, query string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				body = string(data)
+				contentType = r.Header.Get("Content-Type")
+				query = r.URL.RawQuery
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			cfg := newTestConfig(server.URL + tt.query)
+			cfg.Notifications.Webhook.Preset = tt.preset
+			sender := New(cfg)
+
+			if _, err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123", ""); err != nil {
+				t.Fatalf("Send failed: %v", err)
+			}
+			if contentType != "application/json" {
+				t.Errorf("Expected JSON request, got %s", contentType)
+			}
+			if !strings.Contains(body, tt.want) {
+				t.Errorf("Expected %s in body, got %s", tt.want, body)
+			}
+			if tt.query != "" && "?"+query != tt.query {
+				t.Errorf("Expected query %s to be kept, got %s", tt.query, query)
+			}
+		})
+	}
+}
+
+func TestSenderMatrixPresetIdempotentRetries(t *testing.T) {
+	var paths []string
+	var methods []string
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		methods = append(methods, r.Method)
+		auth = r.Header.Get("Authorization")
+		if len(paths) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"event_id":"$abc"}`))
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Preset = "matrix"
+	cfg.Notifications.Webhook.Matrix = config.MatrixConfig{RoomID: "!room:example.com", AccessToken: "syt_token"}
+	sender := New(cfg)
+
+	results, err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123", "")
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	want := "/_matrix/client/v3/rooms/!room:example.com/send/m.room.message/" + results[0].RequestID
+	if len(paths) != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", len(paths))
+	}
+	for i := range paths {
+		if methods[i] != http.MethodPut {
+			t.Errorf("Attempt %d: expected PUT, got %s", i+1, methods[i])
+		}
+		if paths[i] != want {
+			t.Errorf("Attempt %d: expected the request ID as transaction ID in %s, got %s", i+1, want, paths[i])
+		}
+	}
+	if auth != "Bearer syt_token" {
+		t.Errorf("Expected access token, got %q", auth)
+	}
+}
+
+func TestSenderDingTalkPreset(t *testing.T) {
+	var attempts int32
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			_, _ = w.Write([]byte(`{"errcode":130101,"errmsg":"send too fast"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL + "/robot/send?access_token=abc")
+	cfg.Notifications.Webhook.Preset = "dingtalk"
+	cfg.Notifications.Webhook.DingTalk = config.DingTalkConfig{Secret: "SECabc"}
+	sender := New(cfg)
+
+	results, err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123", "")
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if results[0].Attempts != 2 {
+		t.Errorf("Expected a retry after the rate limit error, got %d attempts", results[0].Attempts)
+	}
+	if query.Get("access_token") != "abc" || query.Get("timestamp") == "" || query.Get("sign") == "" {
+		t.Errorf("Expected signed URL, got %v", query)
+	}
+}
+
+func TestSenderWeComPresetAPIError(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		_, _ = w.Write([]byte(`{"errcode":93000,"errmsg":"invalid webhook url"}`))
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL + "/cgi-bin/webhook/send?key=abc")
+	cfg.Notifications.Webhook.Preset = "wecom"
+	sender := newTestSenderWithOutbox(t, server.URL)
+	sender.targets = []*target{newTarget(cfg.Notifications.Webhook, nil)}
+
+	results, err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123", "")
+	if err == nil || !strings.Contains(err.Error(), "invalid webhook url") {
+		t.Fatalf("Expected API error from the response body, got %v", err)
+	}
+	if attempts != 1 || results[0].Queued {
+		t.Errorf("Expected a permanent error without retries or outbox, got %d attempts, queued=%v", attempts, results[0].Queued)
+	}
+}